@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/andyantrim/grpc-example/tasks"
+	"github.com/teamwork/log"
+	"google.golang.org/grpc"
+)
+
+// restoreChunkSize is how much of a backup file each Restore message
+// carries.
+const restoreChunkSize = 1 << 20
+
+// runBackup runs `grpc backup <file>`, saving a backup of a running server.
+// The file only appears once the whole backup has been written.
+func runBackup(args []string) (err error) {
+	fs := flag.NewFlagSet("backup", flag.ExitOnError)
+	fs.Usage = func() { fmt.Fprintln(fs.Output(), "usage: grpc backup [-addr host:port] <file>") }
+	addr := fs.String("addr", ":9000", "the server to back up")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errors.New("backup needs a file to write to")
+	}
+	path := fs.Arg(0)
+
+	conn, err := grpc.Dial(*addr, grpc.WithInsecure())
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	stream, err := tasks.NewAdminClient(conn).Backup(context.Background(), &tasks.BackupRequest{})
+	if err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			f.Close()
+			os.Remove(f.Name())
+		}
+	}()
+	var size int64
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write(chunk.Data); err != nil {
+			return err
+		}
+		size += int64(len(chunk.Data))
+	}
+	if err := f.Sync(); err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(f.Name(), path); err != nil {
+		return err
+	}
+
+	log.Infof("Wrote a %d byte backup to %s", size, path)
+	return nil
+}
+
+// runRestore runs `grpc restore <file>`, loading a backup into a running
+// server which has no tasks yet.
+func runRestore(args []string) error {
+	fs := flag.NewFlagSet("restore", flag.ExitOnError)
+	fs.Usage = func() { fmt.Fprintln(fs.Output(), "usage: grpc restore [-addr host:port] <file>") }
+	addr := fs.String("addr", ":9000", "the server to restore into")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errors.New("restore needs a backup file to read")
+	}
+
+	f, err := os.Open(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	conn, err := grpc.Dial(*addr, grpc.WithInsecure())
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	stream, err := tasks.NewAdminClient(conn).Restore(context.Background())
+	if err != nil {
+		return err
+	}
+
+	buf := make([]byte, restoreChunkSize)
+	for {
+		n, err := io.ReadFull(f, buf)
+		if n > 0 {
+			// A failed send means the server has given up, and
+			// CloseAndRecv says why.
+			if stream.Send(&tasks.BackupChunk{Data: buf[:n]}) != nil {
+				break
+			}
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+	resp, err := stream.CloseAndRecv()
+	if err != nil {
+		return err
+	}
+
+	log.Infof("Restored %d tasks, %d attachments, %d custom fields and %d calendars",
+		resp.Tasks, resp.Attachments, resp.CustomFields, resp.Calendars)
+	return nil
+}
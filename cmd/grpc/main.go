@@ -2,6 +2,8 @@ package main
 
 import (
 	"flag"
+	"fmt"
+	"os"
 
 	"github.com/andyantrim/grpc-example/client"
 	"github.com/andyantrim/grpc-example/server"
@@ -11,10 +13,29 @@ import (
 var serverMode = "server"
 var clientMode = "client"
 
+// subcommands are run as `grpc <name> [args]` instead of a mode.
+var subcommands = map[string]func(args []string) error{
+	"migrate": runMigrate,
+	"backup":  runBackup,
+	"restore": runRestore,
+}
+
 func main() {
+	// Subcommands come before any flags
+	if len(os.Args) > 1 {
+		if run, ok := subcommands[os.Args[1]]; ok {
+			if err := run(os.Args[2:]); err != nil {
+				log.Error(err, fmt.Sprintf("grpc %s failed", os.Args[1]))
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
 	// Parse the flag to get running mode
 	mode := serverMode
 	flag.StringVar(&mode, "mode", serverMode, "[server|client]")
+	flag.Parse()
 
 	switch mode {
 	case clientMode:
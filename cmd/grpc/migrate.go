@@ -0,0 +1,123 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/andyantrim/grpc-example/server"
+	"github.com/andyantrim/grpc-example/tasks/sqlstore"
+	"github.com/teamwork/log"
+)
+
+const migrateUsage = `usage: grpc migrate [-store sql:<driver>:<dsn>] <command>
+
+Commands:
+  up [version]   apply migrations, up to the latest or the given version
+  down [steps]   revert the latest migration, or as many as given
+  status         list migrations and whether each has been applied
+  unlock         clear the migration lock left by a migration which died
+`
+
+// runMigrate runs `grpc migrate`, changing the schema of the sql store
+// named by -store or the server's TASKS_STORE.
+func runMigrate(args []string) error {
+	fs := flag.NewFlagSet("migrate", flag.ExitOnError)
+	fs.Usage = func() { fmt.Fprint(fs.Output(), migrateUsage) }
+	config := fs.String("store", os.Getenv(server.StoreEnv), "the sql store to migrate")
+	fs.Parse(args)
+
+	if !strings.HasPrefix(*config, "sql:") {
+		return fmt.Errorf("only sql stores have migrations, and the store is %q; set -store or %s", *config, server.StoreEnv)
+	}
+	db, err := sqlstore.OpenDB(strings.TrimPrefix(*config, "sql:"))
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	status, err := sqlstore.Status(db)
+	if err != nil {
+		return err
+	}
+	current := 0
+	for _, st := range status {
+		if !st.AppliedAt.IsZero() && st.Version == current+1 {
+			current = st.Version
+		}
+	}
+
+	switch fs.Arg(0) {
+	case "up":
+		target, err := countArg(fs, sqlstore.Latest())
+		if err != nil {
+			return err
+		}
+		if target < current {
+			return fmt.Errorf("database is already at version %d, use down to go back", current)
+		}
+		if err := sqlstore.MigrateTo(db, target); err != nil {
+			return err
+		}
+		log.Infof("Database migrated from version %d to %d", current, target)
+	case "down":
+		steps, err := countArg(fs, 1)
+		if err != nil {
+			return err
+		}
+		target := current - steps
+		if target < 0 {
+			target = 0
+		}
+		if err := sqlstore.MigrateTo(db, target); err != nil {
+			return err
+		}
+		log.Infof("Database migrated from version %d to %d", current, target)
+	case "status":
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED")
+		for _, st := range status {
+			applied := "pending"
+			if !st.AppliedAt.IsZero() {
+				applied = st.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			switch {
+			case st.Unknown:
+				applied += " (not in this build)"
+			case st.Changed:
+				applied += " (changed since)"
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\n", st.Version, st.Name, applied)
+		}
+		w.Flush()
+		if err := sqlstore.Check(db); err != nil {
+			fmt.Println()
+			fmt.Println(err)
+		}
+	case "unlock":
+		if err := sqlstore.Unlock(db); err != nil {
+			return err
+		}
+		log.Info("Migration lock cleared")
+	default:
+		fs.Usage()
+		return errors.New("no migrate command given")
+	}
+	return nil
+}
+
+// countArg parses the argument after the command, if there is one.
+func countArg(fs *flag.FlagSet, def int) (int, error) {
+	if fs.NArg() < 2 {
+		return def, nil
+	}
+	n, err := strconv.Atoi(fs.Arg(1))
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("%q isn't a number of migrations", fs.Arg(1))
+	}
+	return n, nil
+}
@@ -1,10 +1,41 @@
 module github.com/andyantrim/grpc-example
 
-go 1.16
+go 1.19
 
 require (
-	github.com/golang/protobuf v1.5.2 // indirect
 	github.com/teamwork/log v1.0.4
+	github.com/yuin/goldmark v1.7.8
+	go.etcd.io/bbolt v1.3.5
+	go.etcd.io/etcd/raft/v3 v3.5.5
 	google.golang.org/grpc v1.41.0
 	google.golang.org/protobuf v1.27.1
+	modernc.org/sqlite v1.26.0
+)
+
+require (
+	github.com/certifi/gocertifi v0.0.0-20180118203423-deb3ae2ef261 // indirect
+	github.com/dustin/go-humanize v1.0.1 // indirect
+	github.com/evalphobia/logrus_sentry v0.5.0 // indirect
+	github.com/getsentry/raven-go v0.2.0 // indirect
+	github.com/gogo/protobuf v1.3.2 // indirect
+	github.com/golang/protobuf v1.5.2 // indirect
+	github.com/google/uuid v1.3.0 // indirect
+	github.com/konsorten/go-windows-terminal-sequences v1.0.1 // indirect
+	github.com/mattn/go-isatty v0.0.16 // indirect
+	github.com/philhofer/fwd v1.0.0 // indirect
+	github.com/pkg/errors v0.8.0 // indirect
+	github.com/remyoudompheng/bigfft v0.0.0-20230129092748-24d4a6f8daec // indirect
+	github.com/sirupsen/logrus v1.2.0 // indirect
+	github.com/teamwork/utils v0.0.0-20181202231001-702eaeaa40eb // indirect
+	github.com/tinylib/msgp v1.1.0 // indirect
+	golang.org/x/crypto v0.0.0-20200622213623-75b288015ac9 // indirect
+	golang.org/x/net v0.0.0-20200822124328-c89045814202 // indirect
+	golang.org/x/sys v0.0.0-20220811171246-fbc7d0a398ab // indirect
+	golang.org/x/text v0.3.0 // indirect
+	google.golang.org/genproto v0.0.0-20200526211855-cb27e3aa2013 // indirect
+	gopkg.in/DataDog/dd-trace-go.v1 v1.14.0 // indirect
+	gopkg.in/gemnasium/logrus-graylog-hook.v2 v2.0.7 // indirect
+	modernc.org/libc v1.24.1 // indirect
+	modernc.org/mathutil v1.5.0 // indirect
+	modernc.org/memory v1.6.0 // indirect
 )
@@ -1,24 +1,224 @@
 package server
 
 import (
+	"context"
+	"fmt"
 	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	// Calendars need time zones even where the system has none installed.
+	_ "time/tzdata"
 
 	"github.com/andyantrim/grpc-example/tasks"
+	_ "github.com/andyantrim/grpc-example/tasks/boltstore"
+	"github.com/andyantrim/grpc-example/tasks/cryptstore"
+	_ "github.com/andyantrim/grpc-example/tasks/filestore"
+	"github.com/andyantrim/grpc-example/tasks/raftstore"
+	_ "github.com/andyantrim/grpc-example/tasks/sqlstore"
 	"github.com/teamwork/log"
 	"google.golang.org/grpc"
 )
 
+// archiveInterval is how often completed tasks are checked for archiving.
+const archiveInterval = time.Hour
+
+// StoreEnv names the environment variable choosing where tasks are kept,
+// such as "memory", "file:/var/lib/tasks", "bolt:/var/lib/tasks.db" or
+// "sql:sqlite:/var/lib/tasks.db". See tasks.OpenStore.
+const StoreEnv = "TASKS_STORE"
+
+// keyringEnv names the environment variable holding the path of a keyring
+// file. When it's set, task content is encrypted at rest. See
+// cryptstore.Keyring.
+const keyringEnv = "TASKS_KEYRING"
+
+// clusterEnv names the environment variable listing the servers of a
+// cluster by node id, such as "1=10.0.0.1:9000,2=10.0.0.2:9000,3=10.0.0.3:9000".
+// When it's set, the store is replicated across them and nodeEnv says
+// which of them this server is. It listens on its own address from the
+// list. See raftstore.Node.
+const (
+	clusterEnv = "TASKS_CLUSTER"
+	nodeEnv    = "TASKS_NODE"
+)
+
+// followersEnv says what servers other than the leader do with calls:
+// "forward" them to the leader, which is the default, or "redirect" them
+// by returning its address. readsEnv is "linearizable" to have the leader
+// check it still leads before every call.
+const (
+	followersEnv = "TASKS_FOLLOWERS"
+	readsEnv     = "TASKS_READS"
+)
+
+// sinksEnv names the environment variable listing where task change
+// events are sent besides Watch streams, separated by commas, such as
+// "webhook:https://example.com/hook,file:/var/log/tasks.jsonl". See
+// tasks.OpenSink.
+const sinksEnv = "TASKS_EVENT_SINKS"
+
+// relayInterval is how often the outbox is checked for events which
+// weren't delivered straight away, such as after a restart.
+const relayInterval = 10 * time.Second
+
+// keyringInterval is how often the keyring file is reloaded to pick up a
+// new primary key.
+const keyringInterval = time.Minute
+
 func Start() {
-	lis, err := net.Listen("tcp", ":9000")
+	addr := ":9000"
+	storeConfig := os.Getenv(StoreEnv)
+	if storeConfig == "" {
+		storeConfig = "memory"
+	}
+	store, err := tasks.OpenStore(storeConfig)
 	if err != nil {
-		log.Error(err, "Failed to start")
+		log.Error(err, "Failed to open store")
+		return
+	}
+	// store is replaced by the stores wrapping it, which close it in turn.
+	defer func() { store.Close() }()
+	var node *raftstore.Node
+	if peers := os.Getenv(clusterEnv); peers != "" {
+		cfg, err := clusterConfig(peers)
+		if err != nil {
+			log.Error(err, "Failed to read cluster settings")
+			return
+		}
+		transport := raftstore.NewGRPCTransport(cfg.Peers)
+		defer transport.Close()
+		cfg.Store = store
+		cfg.Transport = transport
+		node, err = raftstore.NewNode(cfg)
+		if err != nil {
+			log.Error(err, "Failed to start raft node")
+			return
+		}
+		store = node
+		addr = cfg.Peers[cfg.ID]
+	}
+	var encrypted *cryptstore.Store
+	if path := os.Getenv(keyringEnv); path != "" {
+		keys, err := cryptstore.LoadKeyring(path)
+		if err != nil {
+			log.Error(err, "Failed to load keyring")
+			return
+		}
+		encrypted = cryptstore.Wrap(store, keys)
+		store = encrypted
+		if node != nil {
+			go keys.Watch(context.Background(), keyringInterval)
+		}
+	}
+	taskService, err := tasks.NewTaskService(store)
+	if err != nil {
+		log.Error(err, "Failed to load tasks")
+		return
+	}
+	relay := tasks.NewRelay(taskService)
+	relay.Register(taskService.WatchSink())
+	if sinks := os.Getenv(sinksEnv); sinks != "" {
+		for _, config := range strings.Split(sinks, ",") {
+			sink, err := tasks.OpenSink(strings.TrimSpace(config))
+			if err != nil {
+				log.Error(err, "Failed to read "+sinksEnv)
+				return
+			}
+			relay.Register(sink)
+		}
 	}
-	grpcServer := grpc.NewServer()
-	tasks.RegisterTasksServer(grpcServer, tasks.NewTaskService())
 
+	var opts []grpc.ServerOption
+	if node != nil {
+		node.OnLead(taskService.Reload)
+		opts = node.ServerOptions()
+	}
+	grpcServer := grpc.NewServer(opts...)
+	if node != nil {
+		tasks.RegisterRaftServer(grpcServer, raftstore.NewRaftService(node))
+	}
+	tasks.RegisterTasksServer(grpcServer, taskService)
+	tasks.RegisterTemplatesServer(grpcServer, tasks.NewTemplateService(taskService))
+	tasks.RegisterTimeTrackingServer(grpcServer, tasks.NewTimeService(taskService))
+	tasks.RegisterBoardsServer(grpcServer, tasks.NewBoardService(taskService))
+	tasks.RegisterBulkServer(grpcServer, tasks.NewBulkService(taskService))
+	tasks.RegisterStatsServer(grpcServer, tasks.NewStatsService(taskService))
+	tasks.RegisterCustomFieldsServer(grpcServer, tasks.NewCustomFieldService(taskService))
+	tasks.RegisterNotificationsServer(grpcServer, tasks.NewNotificationService(taskService))
+	tasks.RegisterViewsServer(grpcServer, tasks.NewViewService(taskService))
+	tasks.RegisterCalendarsServer(grpcServer, tasks.NewCalendarService(taskService))
+	tasks.RegisterAdminServer(grpcServer, tasks.NewAdminService(taskService))
+
+	archiveService := tasks.NewArchiveService(taskService)
+	tasks.RegisterArchiveServer(grpcServer, archiveService)
+
+	// The background jobs change the tasks, so in a cluster they only run
+	// on the leader.
+	background := func(job func(ctx context.Context)) {
+		if node != nil {
+			go node.WhileServing(context.Background(), job)
+		} else {
+			go job(context.Background())
+		}
+	}
+	background(taskService.RunWaker)
+	background(func(ctx context.Context) { archiveService.Run(ctx, archiveInterval) })
+	background(func(ctx context.Context) { relay.Run(ctx, relayInterval) })
+	if encrypted != nil {
+		background(func(ctx context.Context) { encrypted.Run(ctx, keyringInterval) })
+	}
+
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		log.Error(err, "Failed to start")
+		return
+	}
 	log.Infof("Starting server on %v", lis.Addr())
 
 	if err := grpcServer.Serve(lis); err != nil {
 		log.Error(err, "Failed to start ")
 	}
 }
+
+// clusterConfig reads the cluster's peers, and this server's settings,
+// from the environment.
+func clusterConfig(peers string) (raftstore.Config, error) {
+	cfg := raftstore.Config{Peers: make(map[uint64]string)}
+	for _, peer := range strings.Split(peers, ",") {
+		i := strings.IndexByte(peer, '=')
+		if i < 0 {
+			return cfg, fmt.Errorf("%s: %q should be id=address", clusterEnv, peer)
+		}
+		id, err := strconv.ParseUint(strings.TrimSpace(peer[:i]), 10, 64)
+		if err != nil || id == 0 {
+			return cfg, fmt.Errorf("%s: node id %q should be a number above 0", clusterEnv, peer[:i])
+		}
+		if _, ok := cfg.Peers[id]; ok {
+			return cfg, fmt.Errorf("%s: node %d is listed twice", clusterEnv, id)
+		}
+		cfg.Peers[id] = strings.TrimSpace(peer[i+1:])
+	}
+	id, err := strconv.ParseUint(os.Getenv(nodeEnv), 10, 64)
+	if err != nil {
+		return cfg, fmt.Errorf("%s should be this server's node id from %s", nodeEnv, clusterEnv)
+	}
+	cfg.ID = id
+
+	switch v := os.Getenv(followersEnv); v {
+	case "", "forward":
+	case "redirect":
+		cfg.Redirect = true
+	default:
+		return cfg, fmt.Errorf("%s is %q, expected forward or redirect", followersEnv, v)
+	}
+	switch v := os.Getenv(readsEnv); v {
+	case "", "leader":
+	case "linearizable":
+		cfg.LinearizableReads = true
+	default:
+		return cfg, fmt.Errorf("%s is %q, expected leader or linearizable", readsEnv, v)
+	}
+	return cfg, nil
+}
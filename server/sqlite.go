@@ -0,0 +1,6 @@
+package server
+
+// The sql store needs a database/sql driver. This links the pure-Go SQLite
+// one, registered as "sqlite", so "sql:sqlite:/var/lib/tasks.db" works
+// without cgo.
+import _ "modernc.org/sqlite"
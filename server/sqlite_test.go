@@ -0,0 +1,38 @@
+package server
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/andyantrim/grpc-example/tasks"
+	"github.com/andyantrim/grpc-example/tasks/sqlstore"
+)
+
+// TestSQLiteStore checks that the server can open the store named in the
+// docs.
+func TestSQLiteStore(t *testing.T) {
+	addr := "sqlite:" + filepath.Join(t.TempDir(), "tasks.db")
+	db, err := sqlstore.OpenDB(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = sqlstore.MigrateTo(db, sqlstore.Latest())
+	db.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := tasks.OpenStore("sql:" + addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	ts, err := tasks.NewTaskService(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ts.Create(context.Background(), &tasks.TaskRequest{Title: "stored"}); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,434 @@
+package tasks
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	"github.com/teamwork/log"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type AdminService struct {
+	UnimplementedAdminServer
+
+	tasks *TaskService
+	// maxRestore is the largest backup Restore accepts.
+	maxRestore int64
+}
+
+func NewAdminService(tasks *TaskService) *AdminService {
+	return &AdminService{tasks: tasks, maxRestore: maxRestore}
+}
+
+// Backup copies everything under the task lock, so the backup is of a
+// single moment, then streams it without holding up other changes.
+// Attachment content is stored under its hash and never removed, so it can
+// safely be read from the store afterwards.
+func (s *AdminService) Backup(r *BackupRequest, stream Admin_BackupServer) error {
+	ts := s.tasks
+	ts.mu.RLock()
+	for id := range ts.corrupt {
+		ts.mu.RUnlock()
+		return corruptError(id)
+	}
+	header := &BackupHeader{
+		Version:          backupVersion,
+		CreatedAt:        timestamppb.New(ts.now()),
+		LastTaskId:       ts.nextID,
+		LastCommentId:    ts.nextCommentID,
+		LastChecklistId:  ts.nextChecklistID,
+		LastAttachmentId: ts.nextAttachID,
+		LastEventId:      ts.nextEventID,
+	}
+	list := make([]*Task, 0, len(ts.tasks))
+	for _, t := range ts.tasks {
+		list = append(list, proto.Clone(t).(*Task))
+	}
+	// The states, outbox and offsets are read from the store, which holds
+	// the same as memory while the lock is held, bar the Relay's changes.
+	var (
+		states  map[string][]byte
+		outbox  []OutboxEntry
+		offsets []*BackupOffset
+	)
+	err := ReadTx(ts.store, func(tx Tx) error {
+		var err error
+		if states, err = readStates(tx); err != nil {
+			return err
+		}
+		if outbox, err = tx.ListOutbox(0, 0); err != nil {
+			return err
+		}
+		for _, name := range ts.sinks {
+			off, err := getOffset(tx, name)
+			if err != nil {
+				return err
+			}
+			if off > 0 {
+				offsets = append(offsets, &BackupOffset{Sink: name, Seq: off})
+			}
+		}
+		return nil
+	})
+	ts.mu.RUnlock()
+	if errors.Is(err, ErrCorrupt) {
+		return status.Errorf(codes.DataLoss, "reading the store: %v", err)
+	}
+	if err != nil {
+		return status.Errorf(codes.Unavailable, "reading the store: %v", err)
+	}
+
+	sort.Slice(list, func(i, j int) bool { return list[i].Id < list[j].Id })
+	keys := make([]string, 0, len(states))
+	for key := range states {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	cw := &chunkWriter{send: stream.Send}
+	w := newBackupWriter(cw)
+	if err := w.write(&BackupRecord{Record: &BackupRecord_Header{Header: header}}); err != nil {
+		return err
+	}
+	blobs := make(map[string]bool)
+	for _, t := range list {
+		if err := w.write(&BackupRecord{Record: &BackupRecord_Task{Task: t}}); err != nil {
+			return err
+		}
+		for _, a := range t.Attachments {
+			if blobs[a.Blob] {
+				continue
+			}
+			blobs[a.Blob] = true
+			var data []byte
+			err := ReadTx(ts.store, func(tx Tx) error {
+				var err error
+				data, err = tx.GetBlob(a.Blob)
+				return err
+			})
+			if errors.Is(err, ErrNotFound) {
+				return status.Errorf(codes.DataLoss, "content of attachment %d on task %d is missing", a.Id, t.Id)
+			}
+			if errors.Is(err, ErrCorrupt) {
+				return status.Errorf(codes.DataLoss, "reading attachment %d on task %d: %v", a.Id, t.Id, err)
+			}
+			if err != nil {
+				return status.Errorf(codes.Unavailable, "reading attachment %d on task %d: %v", a.Id, t.Id, err)
+			}
+			blob := &BackupBlob{Key: a.Blob, Data: data}
+			if err := w.write(&BackupRecord{Record: &BackupRecord_Blob{Blob: blob}}); err != nil {
+				return err
+			}
+		}
+	}
+	for _, key := range keys {
+		st := &BackupState{Key: key, Value: states[key]}
+		if err := w.write(&BackupRecord{Record: &BackupRecord_State{State: st}}); err != nil {
+			return err
+		}
+	}
+	for _, e := range outbox {
+		entry := &BackupOutboxEntry{Seq: e.Seq, Data: e.Data}
+		if err := w.write(&BackupRecord{Record: &BackupRecord_OutboxEntry{OutboxEntry: entry}}); err != nil {
+			return err
+		}
+	}
+	for _, off := range offsets {
+		if err := w.write(&BackupRecord{Record: &BackupRecord_Offset{Offset: off}}); err != nil {
+			return err
+		}
+	}
+	if err := w.close(); err != nil {
+		return err
+	}
+	if err := cw.flush(); err != nil {
+		return err
+	}
+
+	log.Infof("Backed up %d tasks, %d attachments and %d events", len(list), len(blobs), len(outbox))
+	return nil
+}
+
+// Restore reads and checks the whole backup before taking the task lock.
+func (s *AdminService) Restore(stream Admin_RestoreServer) error {
+	chunks := &chunkReader{recv: stream.Recv, left: s.maxRestore}
+	b, err := readBackup(chunks)
+	if err != nil {
+		if cerr := stream.Context().Err(); cerr != nil {
+			return status.FromContextError(cerr).Err()
+		}
+		// The reader's errors are wrapped as text, so the limit is checked
+		// on the chunks instead.
+		if chunks.left < 0 {
+			return status.Errorf(codes.ResourceExhausted, "backups larger than %d bytes can't be restored", s.maxRestore)
+		}
+		if errors.Is(err, errCorruptBackup) {
+			return status.Error(codes.DataLoss, err.Error())
+		}
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	resp, err := s.restore(b)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("Restored %d tasks from a backup taken at %s", resp.Tasks, b.header.CreatedAt.AsTime())
+	return stream.SendAndClose(resp)
+}
+
+// restore writes the backup to the store as it is and loads it, as though
+// the server had restarted with it. Nothing is sent to the watchers, and
+// the events in the outbox are those the backup had.
+func (s *AdminService) restore(b *backup) (_ *RestoreResponse, err error) {
+	ts := s.tasks
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	err = ReadTx(ts.store, func(tx Tx) error {
+		states, err := readStates(tx)
+		if err == nil && len(states) > 0 {
+			err = errNotNew
+		}
+		return err
+	})
+	if err == nil && (len(ts.tasks) > 0 || len(ts.corrupt) > 0 || ts.nextID > 0 || ts.nextEventID > 0) {
+		err = errNotNew
+	}
+	if errors.Is(err, errNotNew) {
+		return nil, status.Error(codes.FailedPrecondition, "backups can only be restored into a new server")
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "reading the store: %v", err)
+	}
+
+	h := b.header
+	seq, err := json.Marshal(sequences{
+		Task:       h.LastTaskId,
+		Comment:    h.LastCommentId,
+		Checklist:  h.LastChecklistId,
+		Attachment: h.LastAttachmentId,
+		Event:      h.LastEventId,
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = WriteTx(ts.store, func(tx Tx) error {
+		for key, data := range b.blobs {
+			if err := tx.PutBlob(key, data); err != nil {
+				return err
+			}
+		}
+		for _, t := range b.tasks {
+			if err := tx.Put(t); err != nil {
+				return err
+			}
+		}
+		for key, value := range b.states {
+			if err := tx.PutMeta(key, value); err != nil {
+				return err
+			}
+		}
+		for _, e := range b.outbox {
+			if err := tx.PutOutbox(e.Seq, e.Data); err != nil {
+				return err
+			}
+		}
+		for _, off := range b.offsets {
+			if err := tx.PutMeta(offsetKey+off.Sink, []byte(strconv.FormatInt(off.Seq, 10))); err != nil {
+				return err
+			}
+		}
+		return tx.PutMeta(sequencesKey, seq)
+	})
+	if err != nil {
+		log.Error(err, "Failed to save a restored backup")
+		return nil, status.Errorf(codes.Unavailable, "saving the backup: %v", err)
+	}
+	if err := ts.loadLocked(); err != nil {
+		log.Error(err, "Failed to load a restored backup")
+		return nil, status.Errorf(codes.Unavailable, "loading the backup: %v", err)
+	}
+	if len(b.outbox) > 0 {
+		ts.outboxChanged()
+	}
+
+	resp := &RestoreResponse{
+		Tasks:       int64(len(b.tasks)),
+		Attachments: int64(len(b.blobs)),
+		Calendars:   int64(len(ts.calendars.byProject)),
+	}
+	for _, defs := range ts.fields.defs {
+		resp.CustomFields += int64(len(defs))
+	}
+	return resp, nil
+}
+
+// errNotNew is returned by restore's check that the server is new.
+var errNotNew = errors.New("server isn't new")
+
+// backup is the content of a backup which has been read and checked.
+// Custom fields and calendars in version 1 backups are turned into states.
+type backup struct {
+	header  *BackupHeader
+	tasks   []*Task
+	blobs   map[string][]byte
+	states  map[string][]byte
+	outbox  []*BackupOutboxEntry
+	offsets []*BackupOffset
+}
+
+// readBackup reads a whole backup, checking its checksum and that it holds
+// everything its tasks refer to.
+func readBackup(r io.Reader) (*backup, error) {
+	br := newBackupReader(r)
+	b := &backup{blobs: make(map[string][]byte), states: make(map[string][]byte)}
+	ids := make(map[int64]bool)
+	fields := &fieldState{defs: make(map[string]map[string]*CustomFieldDefinition)}
+	calendars := &calendarState{byProject: make(map[string]*Calendar)}
+	sinks := make(map[string]bool)
+	var lastSeq int64
+	for {
+		rec, err := br.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if b.header == nil && rec.GetHeader() == nil {
+			return nil, fmt.Errorf("%w: it doesn't start with a header", errBadBackup)
+		}
+
+		switch rec := rec.Record.(type) {
+		case *BackupRecord_Header:
+			if b.header != nil {
+				return nil, fmt.Errorf("%w: it has more than one header", errBadBackup)
+			}
+			if rec.Header.Version < 1 || rec.Header.Version > backupVersion {
+				return nil, fmt.Errorf("%w: it's in format version %d, and this server only reads up to %d", errBadBackup, rec.Header.Version, backupVersion)
+			}
+			b.header = rec.Header
+		case *BackupRecord_Task:
+			t := rec.Task
+			if t.Id <= 0 || t.Id > b.header.LastTaskId || ids[t.Id] {
+				return nil, fmt.Errorf("%w: task id %d is repeated or out of range", errBadBackup, t.Id)
+			}
+			ids[t.Id] = true
+			b.tasks = append(b.tasks, t)
+		case *BackupRecord_Blob:
+			sum := sha256.Sum256(rec.Blob.Data)
+			if hex.EncodeToString(sum[:]) != rec.Blob.Key {
+				return nil, fmt.Errorf("%w: attachment content %s doesn't match its hash", errCorruptBackup, rec.Blob.Key)
+			}
+			b.blobs[rec.Blob.Key] = rec.Blob.Data
+		case *BackupRecord_CustomField:
+			d := rec.CustomField
+			if b.header.Version > 1 {
+				return nil, fmt.Errorf("%w: custom fields are only kept as states after version 1", errBadBackup)
+			}
+			if d.Name == "" || fields.defs[d.Project][d.Name] != nil {
+				return nil, fmt.Errorf("%w: custom field %q in project %q is unnamed or repeated", errBadBackup, d.Name, d.Project)
+			}
+			if fields.defs[d.Project] == nil {
+				fields.defs[d.Project] = make(map[string]*CustomFieldDefinition)
+			}
+			fields.defs[d.Project][d.Name] = d
+		case *BackupRecord_Calendar:
+			if b.header.Version > 1 {
+				return nil, fmt.Errorf("%w: calendars are only kept as states after version 1", errBadBackup)
+			}
+			if calendars.byProject[rec.Calendar.Project] != nil {
+				return nil, fmt.Errorf("%w: project %q has more than one calendar", errBadBackup, rec.Calendar.Project)
+			}
+			cal, err := normalizeCalendar(rec.Calendar)
+			if err != nil {
+				return nil, fmt.Errorf("%w: calendar for project %q: %v", errBadBackup, rec.Calendar.Project, status.Convert(err).Message())
+			}
+			calendars.byProject[cal.Project] = cal
+		case *BackupRecord_State:
+			if _, ok := b.states[rec.State.Key]; ok {
+				return nil, fmt.Errorf("%w: state %q is repeated", errBadBackup, rec.State.Key)
+			}
+			b.states[rec.State.Key] = rec.State.Value
+		case *BackupRecord_OutboxEntry:
+			e := rec.OutboxEntry
+			if e.Seq <= lastSeq || e.Seq > b.header.LastEventId {
+				return nil, fmt.Errorf("%w: event %d is out of order or out of range", errBadBackup, e.Seq)
+			}
+			lastSeq = e.Seq
+			b.outbox = append(b.outbox, e)
+		case *BackupRecord_Offset:
+			off := rec.Offset
+			if off.Sink == "" || sinks[off.Sink] || off.Seq < 0 || off.Seq > b.header.LastEventId {
+				return nil, fmt.Errorf("%w: offset of sink %q is repeated or out of range", errBadBackup, off.Sink)
+			}
+			sinks[off.Sink] = true
+			b.offsets = append(b.offsets, off)
+		default:
+			return nil, fmt.Errorf("%w: record %d is of an unknown kind", errBadBackup, br.records)
+		}
+	}
+	if b.header == nil {
+		return nil, fmt.Errorf("%w: it's empty", errBadBackup)
+	}
+
+	if b.header.Version == 1 {
+		tx := metaTx{meta: b.states}
+		if err := fields.save(tx); err != nil {
+			return nil, err
+		}
+		if err := calendars.save(tx); err != nil {
+			return nil, err
+		}
+	}
+	// Every state must be one this server keeps, and load.
+	read, err := readStates(metaTx{meta: b.states})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errBadBackup, err)
+	}
+	for key := range b.states {
+		if _, ok := read[key]; !ok {
+			return nil, fmt.Errorf("%w: state %q isn't one this server keeps", errBadBackup, key)
+		}
+	}
+
+	h := b.header
+	for _, t := range b.tasks {
+		if t.ParentId != 0 && !ids[t.ParentId] {
+			return nil, fmt.Errorf("%w: task %d is under task %d, which it doesn't have", errBadBackup, t.Id, t.ParentId)
+		}
+		for _, id := range t.DependsOn {
+			if !ids[id] {
+				return nil, fmt.Errorf("%w: task %d depends on task %d, which it doesn't have", errBadBackup, t.Id, id)
+			}
+		}
+		for _, c := range t.Comments {
+			if c.Id <= 0 || c.Id > h.LastCommentId {
+				return nil, fmt.Errorf("%w: comment id %d on task %d is out of range", errBadBackup, c.Id, t.Id)
+			}
+		}
+		for _, item := range t.Checklist {
+			if item.Id <= 0 || item.Id > h.LastChecklistId {
+				return nil, fmt.Errorf("%w: checklist item id %d on task %d is out of range", errBadBackup, item.Id, t.Id)
+			}
+		}
+		for _, a := range t.Attachments {
+			if a.Id <= 0 || a.Id > h.LastAttachmentId {
+				return nil, fmt.Errorf("%w: attachment id %d on task %d is out of range", errBadBackup, a.Id, t.Id)
+			}
+			if _, ok := b.blobs[a.Blob]; !ok {
+				return nil, fmt.Errorf("%w: content of attachment %d on task %d is missing", errBadBackup, a.Id, t.Id)
+			}
+		}
+	}
+	return b, nil
+}
@@ -0,0 +1,247 @@
+package tasks
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// backupStream collects a backup's chunks, and plays them back to Restore.
+type backupStream struct {
+	grpc.ServerStream
+	chunks []*BackupChunk
+	resp   *RestoreResponse
+}
+
+func (s *backupStream) Send(c *BackupChunk) error {
+	s.chunks = append(s.chunks, c)
+	return nil
+}
+
+func (s *backupStream) Recv() (*BackupChunk, error) {
+	if len(s.chunks) == 0 {
+		return nil, io.EOF
+	}
+	c := s.chunks[0]
+	s.chunks = s.chunks[1:]
+	return c, nil
+}
+
+func (s *backupStream) SendAndClose(r *RestoreResponse) error {
+	s.resp = r
+	return nil
+}
+
+func (s *backupStream) Context() context.Context { return context.Background() }
+
+type nopSink struct{}
+
+func (nopSink) Name() string                                { return "nop" }
+func (nopSink) Deliver(context.Context, []*TaskEvent) error { return nil }
+
+// storeContent is everything a TaskService has saved in its store.
+type storeContent struct {
+	tasks  []*Task
+	blobs  map[string][]byte
+	states map[string][]byte
+	outbox []OutboxEntry
+	offset int64
+	seq    sequences
+}
+
+func readContent(t *testing.T, ts *TaskService) *storeContent {
+	t.Helper()
+	c := &storeContent{blobs: make(map[string][]byte)}
+	err := ReadTx(ts.store, func(tx Tx) error {
+		var err error
+		if c.tasks, err = tx.List(0, 0); err != nil {
+			return err
+		}
+		for _, task := range c.tasks {
+			for _, a := range task.Attachments {
+				if c.blobs[a.Blob], err = tx.GetBlob(a.Blob); err != nil {
+					return err
+				}
+			}
+		}
+		if c.states, err = readStates(tx); err != nil {
+			return err
+		}
+		if c.outbox, err = tx.ListOutbox(0, 0); err != nil {
+			return err
+		}
+		if c.offset, err = getOffset(tx, nopSink{}.Name()); err != nil {
+			return err
+		}
+		b, err := tx.GetMeta(sequencesKey)
+		if err != nil {
+			return err
+		}
+		return json.Unmarshal(b, &c.seq)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestBackupRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	ts, err := NewTaskService(NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	NewNotificationService(ts)
+	relay := NewRelay(ts)
+	relay.Register(nopSink{})
+
+	sev := &CustomFieldDefinition{Project: "p", Name: "sev", Type: CustomFieldDefinition_NUMBER}
+	if _, err := NewCustomFieldService(ts).DefineField(ctx, sev); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewCalendarService(ts).SetCalendar(ctx, &Calendar{Project: "p", TimeZone: "Europe/London"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewBoardService(ts).CreateBoard(ctx, &Board{Name: "b", Project: "p", Columns: []*Column{{Name: "todo"}}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewViewService(ts).SaveView(ctx, &View{Name: "v", Owner: "ann", Project: "p"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewTemplateService(ts).SaveTemplate(ctx, &SaveTemplateRequest{Name: "t", Root: &TemplateTask{Title: "{{x}}"}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewArchiveService(ts).SetArchiveSettings(ctx, &ArchiveSettings{Project: "p", After: durationpb.New(time.Hour)}); err != nil {
+		t.Fatal(err)
+	}
+	created, err := ts.Create(ctx, &TaskRequest{Title: "crash", Project: "p", Assignee: "ann"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ts.AddAttachment(ctx, &AddAttachmentRequest{TaskId: created.Id, Name: "log.txt", Data: []byte("boom")}); err != nil {
+		t.Fatal(err)
+	}
+	timers := NewTimeService(ts)
+	if _, err := timers.StartTimer(ctx, &StartTimerRequest{User: "ann", TaskId: created.Id}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := timers.LogWork(ctx, &LogWorkRequest{User: "bob", TaskId: created.Id, Duration: durationpb.New(time.Hour)}); err != nil {
+		t.Fatal(err)
+	}
+	// Some events have been delivered and some are still waiting.
+	if err := relay.RunOnce(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ts.Create(ctx, &TaskRequest{Title: "again", Project: "p", Assignee: "ann"}); err != nil {
+		t.Fatal(err)
+	}
+
+	admin := NewAdminService(ts)
+	stream := &backupStream{}
+	if err := admin.Backup(&BackupRequest{}, stream); err != nil {
+		t.Fatal(err)
+	}
+	chunks := append([]*BackupChunk{}, stream.chunks...)
+
+	restored, err := NewTaskService(NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	NewRelay(restored).Register(nopSink{})
+	if err := NewAdminService(restored).Restore(stream); err != nil {
+		t.Fatal(err)
+	}
+	if stream.resp.Tasks != 2 || stream.resp.CustomFields != 1 || stream.resp.Calendars != 1 {
+		t.Errorf("restore reported %v", stream.resp)
+	}
+
+	want, got := readContent(t, ts), readContent(t, restored)
+	if len(got.tasks) != len(want.tasks) {
+		t.Fatalf("restored %d tasks, want %d", len(got.tasks), len(want.tasks))
+	}
+	for i := range want.tasks {
+		if !proto.Equal(got.tasks[i], want.tasks[i]) {
+			t.Errorf("restored task %v, want %v", got.tasks[i], want.tasks[i])
+		}
+	}
+	got.tasks, want.tasks = nil, nil
+	if len(want.outbox) == 0 || want.offset == 0 {
+		t.Fatalf("the backed up server has %d events waiting and an offset of %d", len(want.outbox), want.offset)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("restored\n%+v\nwant\n%+v", got, want)
+	}
+
+	// The restored server carries on where the backup left off.
+	next, err := restored.Create(ctx, &TaskRequest{Title: "next"})
+	if err != nil || next.Id != 3 {
+		t.Errorf("created %v, %v after restoring", next, err)
+	}
+	// Restoring doesn't notify anyone again.
+	inbox, err := NewNotificationService(restored).ListNotifications(ctx, &ListNotificationsRequest{User: "ann"})
+	if err != nil || len(inbox.Notifications) != 4 {
+		t.Errorf("inbox after restoring is %v, %v", inbox, err)
+	}
+
+	stream = &backupStream{chunks: chunks}
+	err = NewAdminService(restored).Restore(stream)
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("restoring into a server with tasks returned %v", err)
+	}
+}
+
+// Restore stops reading a backup once it's larger than the limit, rather
+// than holding all of it in memory.
+func TestRestoreTooBig(t *testing.T) {
+	ctx := context.Background()
+	ts, err := NewTaskService(NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ts.Create(ctx, &TaskRequest{Title: "big", Description: strings.Repeat("x", 3*backupChunkSize)}); err != nil {
+		t.Fatal(err)
+	}
+	stream := &backupStream{}
+	if err := NewAdminService(ts).Backup(&BackupRequest{}, stream); err != nil {
+		t.Fatal(err)
+	}
+	var size int64
+	for _, c := range stream.chunks {
+		size += int64(len(c.Data))
+	}
+	chunks := stream.chunks
+
+	restored, err := NewTaskService(NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	admin := NewAdminService(restored)
+	for _, limit := range []int64{backupChunkSize, size - 1} {
+		admin.maxRestore = limit
+		stream = &backupStream{chunks: append([]*BackupChunk{}, chunks...)}
+		if err := admin.Restore(stream); status.Code(err) != codes.ResourceExhausted {
+			t.Errorf("restoring %d bytes with a limit of %d returned %v", size, limit, err)
+		}
+		if limit == backupChunkSize && len(stream.chunks) == 0 {
+			t.Errorf("every chunk was read from a backup over the limit")
+		}
+	}
+	if _, err := restored.Get(ctx, &GetRequest{Id: 1}); status.Code(err) != codes.NotFound {
+		t.Errorf("a refused restore left task 1: %v", err)
+	}
+
+	admin.maxRestore = size
+	if err := admin.Restore(&backupStream{chunks: chunks}); err != nil {
+		t.Errorf("restoring a backup at the limit: %v", err)
+	}
+}
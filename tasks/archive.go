@@ -0,0 +1,195 @@
+package tasks
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/teamwork/log"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// DefaultArchiveAfter is how long a task stays DONE or CANCELLED before it
+// is archived, unless configured otherwise.
+const DefaultArchiveAfter = 30 * 24 * time.Hour
+
+// archiveKey is the meta key the archive settings are saved under.
+const archiveKey = "archive"
+
+// archiveState holds each project's archive settings.
+type archiveState struct {
+	settings map[string]*ArchiveSettings
+}
+
+func (as *archiveState) load(tx Tx) error {
+	stored := &StoredArchiveSettings{}
+	if err := getState(tx, archiveKey, stored); err != nil {
+		return err
+	}
+	as.settings = make(map[string]*ArchiveSettings)
+	for _, ps := range stored.Settings {
+		as.settings[ps.Project] = ps
+	}
+	return nil
+}
+
+func (as *archiveState) save(tx Tx) error {
+	stored := &StoredArchiveSettings{}
+	for _, ps := range as.settings {
+		stored.Settings = append(stored.Settings, ps)
+	}
+	sort.Slice(stored.Settings, func(i, j int) bool {
+		return stored.Settings[i].Project < stored.Settings[j].Project
+	})
+	return putState(tx, archiveKey, stored)
+}
+
+type ArchiveService struct {
+	UnimplementedArchiveServer
+
+	tasks *TaskService
+
+	// After is the archive age for projects without their own settings.
+	After time.Duration
+
+	mu  sync.Mutex
+	job *ArchiveJobStatus
+}
+
+func NewArchiveService(tasks *TaskService) *ArchiveService {
+	return &ArchiveService{
+		tasks: tasks,
+		After: DefaultArchiveAfter,
+		job:   &ArchiveJobStatus{},
+	}
+}
+
+func (s *ArchiveService) SetArchiveSettings(c context.Context, r *ArchiveSettings) (_ *ArchiveSettings, err error) {
+	if r.After != nil && r.After.AsDuration() <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "after must be positive")
+	}
+
+	ts := s.tasks
+	ts.mu.Lock()
+	defer ts.unlock(&err)
+
+	ts.archive.settings[r.Project] = proto.Clone(r).(*ArchiveSettings)
+	ts.stateChangedLocked(ts.archive)
+	log.Infof("Archive settings for project %q set to %v", r.Project, r)
+	return s.settingsLocked(r.Project), nil
+}
+
+func (s *ArchiveService) GetArchiveSettings(c context.Context, r *GetArchiveSettingsRequest) (*ArchiveSettings, error) {
+	s.tasks.mu.RLock()
+	defer s.tasks.mu.RUnlock()
+	return s.settingsLocked(r.Project), nil
+}
+
+func (s *ArchiveService) GetArchiveJobStatus(c context.Context, r *GetArchiveJobStatusRequest) (*ArchiveJobStatus, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return proto.Clone(s.job).(*ArchiveJobStatus), nil
+}
+
+// Run archives old tasks every interval until ctx is cancelled.
+func (s *ArchiveService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		s.mu.Lock()
+		s.job.NextRun = timestamppb.New(time.Now().Add(interval))
+		s.mu.Unlock()
+
+		s.RunOnce()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce archives every completed task which is older than its project's
+// archive age, returning how many were archived.
+func (s *ArchiveService) RunOnce() int64 {
+	s.mu.Lock()
+	s.job.Running = true
+	s.job.LastStarted = timestamppb.Now()
+	s.mu.Unlock()
+
+	log.Info("Archive job started")
+
+	ts := s.tasks
+	ts.mu.Lock()
+	settings := make(map[string]*ArchiveSettings, len(ts.archive.settings))
+	for p := range ts.archive.settings {
+		settings[p] = s.settingsLocked(p)
+	}
+	now := ts.now()
+	var archived int64
+	for _, t := range ts.tasks {
+		if t.ArchivedAt != nil || t.CompletedAt == nil || isOpen(t.Status) {
+			continue
+		}
+		after := s.After
+		if ps, ok := settings[t.Project]; ok {
+			if ps.Disabled {
+				continue
+			}
+			after = ps.After.AsDuration()
+		}
+		if now.Sub(t.CompletedAt.AsTime()) < after {
+			continue
+		}
+
+		old := proto.Clone(t).(*Task)
+		t.ArchivedAt = timestamppb.New(now)
+		ts.changedLocked(old, t)
+		archived++
+	}
+	err := ts.commitLocked()
+	ts.mu.Unlock()
+	if err != nil {
+		archived = 0
+	}
+
+	s.mu.Lock()
+	s.job.Running = false
+	s.job.Runs++
+	s.job.LastFinished = timestamppb.Now()
+	s.job.LastArchived = archived
+	s.job.TotalArchived += archived
+	if err != nil {
+		s.job.LastError = err.Error()
+		s.job.LastErrorAt = s.job.LastFinished
+	}
+	s.mu.Unlock()
+
+	if err != nil {
+		log.Error(err, "Archive job failed to save archived tasks")
+	} else {
+		log.Infof("Archive job finished, archived %d tasks", archived)
+	}
+	return archived
+}
+
+// settingsLocked returns a copy of the project's settings with the default
+// age filled in. The task lock must be held.
+func (s *ArchiveService) settingsLocked(project string) *ArchiveSettings {
+	ps, ok := s.tasks.archive.settings[project]
+	if !ok {
+		ps = &ArchiveSettings{Project: project}
+	}
+	ps = proto.Clone(ps).(*ArchiveSettings)
+	if ps.After == nil {
+		ps.After = durationpb.New(s.After)
+	}
+	return ps
+}
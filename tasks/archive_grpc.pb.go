@@ -0,0 +1,177 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             (unknown)
+// source: tasks/archive.proto
+
+package tasks
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// ArchiveClient is the client API for Archive service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type ArchiveClient interface {
+	SetArchiveSettings(ctx context.Context, in *ArchiveSettings, opts ...grpc.CallOption) (*ArchiveSettings, error)
+	GetArchiveSettings(ctx context.Context, in *GetArchiveSettingsRequest, opts ...grpc.CallOption) (*ArchiveSettings, error)
+	GetArchiveJobStatus(ctx context.Context, in *GetArchiveJobStatusRequest, opts ...grpc.CallOption) (*ArchiveJobStatus, error)
+}
+
+type archiveClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewArchiveClient(cc grpc.ClientConnInterface) ArchiveClient {
+	return &archiveClient{cc}
+}
+
+func (c *archiveClient) SetArchiveSettings(ctx context.Context, in *ArchiveSettings, opts ...grpc.CallOption) (*ArchiveSettings, error) {
+	out := new(ArchiveSettings)
+	err := c.cc.Invoke(ctx, "/task.Archive/SetArchiveSettings", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *archiveClient) GetArchiveSettings(ctx context.Context, in *GetArchiveSettingsRequest, opts ...grpc.CallOption) (*ArchiveSettings, error) {
+	out := new(ArchiveSettings)
+	err := c.cc.Invoke(ctx, "/task.Archive/GetArchiveSettings", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *archiveClient) GetArchiveJobStatus(ctx context.Context, in *GetArchiveJobStatusRequest, opts ...grpc.CallOption) (*ArchiveJobStatus, error) {
+	out := new(ArchiveJobStatus)
+	err := c.cc.Invoke(ctx, "/task.Archive/GetArchiveJobStatus", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ArchiveServer is the server API for Archive service.
+// All implementations must embed UnimplementedArchiveServer
+// for forward compatibility
+type ArchiveServer interface {
+	SetArchiveSettings(context.Context, *ArchiveSettings) (*ArchiveSettings, error)
+	GetArchiveSettings(context.Context, *GetArchiveSettingsRequest) (*ArchiveSettings, error)
+	GetArchiveJobStatus(context.Context, *GetArchiveJobStatusRequest) (*ArchiveJobStatus, error)
+	mustEmbedUnimplementedArchiveServer()
+}
+
+// UnimplementedArchiveServer must be embedded to have forward compatible implementations.
+type UnimplementedArchiveServer struct {
+}
+
+func (UnimplementedArchiveServer) SetArchiveSettings(context.Context, *ArchiveSettings) (*ArchiveSettings, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetArchiveSettings not implemented")
+}
+func (UnimplementedArchiveServer) GetArchiveSettings(context.Context, *GetArchiveSettingsRequest) (*ArchiveSettings, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetArchiveSettings not implemented")
+}
+func (UnimplementedArchiveServer) GetArchiveJobStatus(context.Context, *GetArchiveJobStatusRequest) (*ArchiveJobStatus, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetArchiveJobStatus not implemented")
+}
+func (UnimplementedArchiveServer) mustEmbedUnimplementedArchiveServer() {}
+
+// UnsafeArchiveServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ArchiveServer will
+// result in compilation errors.
+type UnsafeArchiveServer interface {
+	mustEmbedUnimplementedArchiveServer()
+}
+
+func RegisterArchiveServer(s grpc.ServiceRegistrar, srv ArchiveServer) {
+	s.RegisterService(&Archive_ServiceDesc, srv)
+}
+
+func _Archive_SetArchiveSettings_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ArchiveSettings)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ArchiveServer).SetArchiveSettings(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/task.Archive/SetArchiveSettings",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ArchiveServer).SetArchiveSettings(ctx, req.(*ArchiveSettings))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Archive_GetArchiveSettings_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetArchiveSettingsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ArchiveServer).GetArchiveSettings(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/task.Archive/GetArchiveSettings",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ArchiveServer).GetArchiveSettings(ctx, req.(*GetArchiveSettingsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Archive_GetArchiveJobStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetArchiveJobStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ArchiveServer).GetArchiveJobStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/task.Archive/GetArchiveJobStatus",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ArchiveServer).GetArchiveJobStatus(ctx, req.(*GetArchiveJobStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Archive_ServiceDesc is the grpc.ServiceDesc for Archive service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Archive_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "task.Archive",
+	HandlerType: (*ArchiveServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "SetArchiveSettings",
+			Handler:    _Archive_SetArchiveSettings_Handler,
+		},
+		{
+			MethodName: "GetArchiveSettings",
+			Handler:    _Archive_GetArchiveSettings_Handler,
+		},
+		{
+			MethodName: "GetArchiveJobStatus",
+			Handler:    _Archive_GetArchiveJobStatus_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "tasks/archive.proto",
+}
@@ -0,0 +1,114 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// brokenStore fails every write while broken is set.
+type brokenStore struct {
+	Store
+	broken bool
+}
+
+func (s *brokenStore) Begin(writable bool) (Tx, error) {
+	if writable && s.broken {
+		return nil, errors.New("disk full")
+	}
+	return s.Store.Begin(writable)
+}
+
+func TestArchiveJobError(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	store := &brokenStore{Store: NewMemoryStore()}
+	ts, err := NewTaskService(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts.now = func() time.Time { return now }
+	created, err := ts.Create(ctx, &TaskRequest{Title: "old"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := &UpdateRequest{
+		Id:         created.Id,
+		Task:       &Task{Status: Status_DONE},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"status"}},
+	}
+	if _, err := ts.Update(ctx, done); err != nil {
+		t.Fatal(err)
+	}
+	now = now.Add(DefaultArchiveAfter + time.Hour)
+	archive := NewArchiveService(ts)
+
+	store.broken = true
+	if n := archive.RunOnce(); n != 0 {
+		t.Errorf("failed run archived %d tasks", n)
+	}
+	job, err := archive.GetArchiveJobStatus(ctx, &GetArchiveJobStatusRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.LastError == "" || job.LastErrorAt == nil || job.Runs != 1 {
+		t.Errorf("job after a failed run is %v", job)
+	}
+
+	// A later successful run keeps the last error.
+	store.broken = false
+	if n := archive.RunOnce(); n != 1 {
+		t.Errorf("archived %d tasks, want 1", n)
+	}
+	after, err := archive.GetArchiveJobStatus(ctx, &GetArchiveJobStatusRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if after.LastError != job.LastError || !after.LastErrorAt.AsTime().Equal(job.LastErrorAt.AsTime()) || after.TotalArchived != 1 {
+		t.Errorf("job after a successful run is %v", after)
+	}
+}
+
+// Reopening an archived task brings it back into List.
+func TestReopenArchived(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	ts, err := NewTaskService(NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts.now = func() time.Time { return now }
+	if _, err := ts.Create(ctx, &TaskRequest{Title: "again"}); err != nil {
+		t.Fatal(err)
+	}
+	setStatus := func(st Status) {
+		_, err := ts.Update(ctx, &UpdateRequest{
+			Id:         1,
+			Task:       &Task{Status: st},
+			UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"status"}},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	setStatus(Status_DONE)
+	now = now.Add(DefaultArchiveAfter + time.Hour)
+	if n := NewArchiveService(ts).RunOnce(); n != 1 {
+		t.Fatalf("archived %d tasks, want 1", n)
+	}
+	if list, _ := ts.List(ctx, &ListRequest{}); len(list.Tasks) != 0 {
+		t.Fatalf("archived task is still listed")
+	}
+
+	setStatus(Status_TODO)
+	list, err := ts.List(ctx, &ListRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Tasks) != 1 || list.Tasks[0].ArchivedAt != nil {
+		t.Errorf("tasks after reopening are %v, want the task unarchived", list.Tasks)
+	}
+}
@@ -0,0 +1,105 @@
+package tasks
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+
+	"github.com/teamwork/log"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// maxAttachment is the largest attachment AddAttachment accepts, which
+// leaves room for the rest of the message under gRPC's default 4MB limit.
+const maxAttachment = 4<<20 - 64<<10
+
+func (s *TaskService) AddAttachment(c context.Context, r *AddAttachmentRequest) (_ *Attachment, err error) {
+	if strings.TrimSpace(r.Name) == "" {
+		return nil, status.Error(codes.InvalidArgument, "attachments need a name")
+	}
+	if len(r.Data) > maxAttachment {
+		return nil, status.Errorf(codes.InvalidArgument, "attachments can't be larger than %d bytes", maxAttachment)
+	}
+
+	sum := sha256.Sum256(r.Data)
+	blob := hex.EncodeToString(sum[:])
+
+	s.mu.Lock()
+	defer s.unlock(&err)
+
+	t, ok := s.tasks[r.TaskId]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "task %d not found", r.TaskId)
+	}
+	// Content is stored under its hash, so storing it again is harmless.
+	s.pendingBlobs[blob] = r.Data
+
+	s.nextAttachID++
+	a := &Attachment{
+		Id:          s.nextAttachID,
+		Name:        r.Name,
+		ContentType: r.ContentType,
+		Size:        int64(len(r.Data)),
+		Blob:        blob,
+		CreatedAt:   timestamppb.New(s.now()),
+	}
+	old := proto.Clone(t).(*Task)
+	t.Attachments = append(t.Attachments, a)
+	s.changedLocked(old, t)
+
+	log.Infof("Attached %s to task %d", r.Name, t.Id)
+	return proto.Clone(a).(*Attachment), nil
+}
+
+func (s *TaskService) GetAttachment(c context.Context, r *GetAttachmentRequest) (*AttachmentData, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	t, ok := s.tasks[r.TaskId]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "task %d not found", r.TaskId)
+	}
+	for _, a := range t.Attachments {
+		if a.Id != r.AttachmentId {
+			continue
+		}
+		var data []byte
+		err := ReadTx(s.store, func(tx Tx) error {
+			var err error
+			data, err = tx.GetBlob(a.Blob)
+			return err
+		})
+		if errors.Is(err, ErrNotFound) {
+			return nil, status.Errorf(codes.DataLoss, "content of attachment %d is missing", a.Id)
+		}
+		if errors.Is(err, ErrCorrupt) {
+			return nil, status.Errorf(codes.DataLoss, "reading attachment %d: %v", a.Id, err)
+		}
+		if err != nil {
+			return nil, status.Errorf(codes.Unavailable, "reading attachment %d: %v", a.Id, err)
+		}
+		return &AttachmentData{
+			Attachment: proto.Clone(a).(*Attachment),
+			Data:       data,
+		}, nil
+	}
+	return nil, status.Errorf(codes.NotFound, "attachment %d not found", r.AttachmentId)
+}
+
+// copyAttachmentsLocked gives copies of t's attachments new ids. The copies
+// share their content with the originals. s.mu must be held.
+func (s *TaskService) copyAttachmentsLocked(t *Task) []*Attachment {
+	var copies []*Attachment
+	for _, a := range t.Attachments {
+		s.nextAttachID++
+		a = proto.Clone(a).(*Attachment)
+		a.Id = s.nextAttachID
+		copies = append(copies, a)
+	}
+	return copies
+}
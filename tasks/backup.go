@@ -0,0 +1,190 @@
+package tasks
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// backupVersion is the backup format written by Backup. See BackupRecord.
+const backupVersion = 2
+
+// maxBackupRecord is the largest record a backup may hold, well above the
+// largest attachment.
+const maxBackupRecord = 16 << 20
+
+// maxRestore is the largest backup Restore reads. The whole backup is held
+// in memory until it has been checked.
+const maxRestore = 1 << 30
+
+// errBadBackup is wrapped by every error reading a backup which isn't
+// well formed, and errCorruptBackup by those where its content doesn't
+// match its checksum.
+var (
+	errBadBackup     = errors.New("not a valid backup")
+	errCorruptBackup = errors.New("backup is corrupt")
+	errBackupTooBig  = errors.New("backup is too large")
+)
+
+// backupWriter writes backup records, keeping the checksum and count the
+// trailer needs.
+type backupWriter struct {
+	w       io.Writer
+	sum     hash.Hash
+	records int64
+}
+
+func newBackupWriter(w io.Writer) *backupWriter {
+	return &backupWriter{w: w, sum: sha256.New()}
+}
+
+func (w *backupWriter) write(rec *BackupRecord) error {
+	b, err := proto.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	var size [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(size[:], uint64(len(b)))
+	if _, err := w.w.Write(size[:n]); err != nil {
+		return err
+	}
+	if _, err := w.w.Write(b); err != nil {
+		return err
+	}
+	w.sum.Write(size[:n])
+	w.sum.Write(b)
+	w.records++
+	return nil
+}
+
+// close writes the trailer. Nothing can be written after it.
+func (w *backupWriter) close() error {
+	trailer := &BackupTrailer{Records: w.records, Sha256: w.sum.Sum(nil)}
+	return w.write(&BackupRecord{Record: &BackupRecord_Trailer{Trailer: trailer}})
+}
+
+// backupReader reads backup records, checking the trailer when it reaches
+// it.
+type backupReader struct {
+	r       *bufio.Reader
+	sum     hash.Hash
+	records int64
+	done    bool
+}
+
+func newBackupReader(r io.Reader) *backupReader {
+	return &backupReader{r: bufio.NewReader(r), sum: sha256.New()}
+}
+
+// next returns the next record before the trailer, or io.EOF once the
+// trailer has been read and checked.
+func (r *backupReader) next() (*BackupRecord, error) {
+	if r.done {
+		return nil, io.EOF
+	}
+	size, err := binary.ReadUvarint(r.r)
+	if err == io.EOF {
+		return nil, fmt.Errorf("%w: it ends before its trailer", errBadBackup)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: reading record %d: %v", errBadBackup, r.records+1, err)
+	}
+	if size > maxBackupRecord {
+		return nil, fmt.Errorf("%w: record %d is %d bytes, more than the %d allowed", errBadBackup, r.records+1, size, maxBackupRecord)
+	}
+	b := make([]byte, size)
+	if _, err := io.ReadFull(r.r, b); err != nil {
+		return nil, fmt.Errorf("%w: reading record %d: %v", errBadBackup, r.records+1, err)
+	}
+	rec := &BackupRecord{}
+	if err := proto.Unmarshal(b, rec); err != nil {
+		return nil, fmt.Errorf("%w: decoding record %d: %v", errCorruptBackup, r.records+1, err)
+	}
+
+	if trailer := rec.GetTrailer(); trailer != nil {
+		r.done = true
+		if trailer.Records != r.records {
+			return nil, fmt.Errorf("%w: it has %d records but its trailer says %d", errCorruptBackup, r.records, trailer.Records)
+		}
+		if string(trailer.Sha256) != string(r.sum.Sum(nil)) {
+			return nil, fmt.Errorf("%w: its checksum doesn't match", errCorruptBackup)
+		}
+		if _, err := r.r.ReadByte(); err != io.EOF {
+			return nil, fmt.Errorf("%w: there is more after its trailer", errBadBackup)
+		}
+		return nil, io.EOF
+	}
+
+	var prefix [binary.MaxVarintLen64]byte
+	r.sum.Write(prefix[:binary.PutUvarint(prefix[:], size)])
+	r.sum.Write(b)
+	r.records++
+	return rec, nil
+}
+
+// chunkWriter sends what's written to it as chunks of at most
+// backupChunkSize bytes.
+type chunkWriter struct {
+	send func(*BackupChunk) error
+	buf  []byte
+}
+
+// backupChunkSize keeps chunks well under gRPC's default 4MB limit.
+const backupChunkSize = 1 << 20
+
+func (w *chunkWriter) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		take := backupChunkSize - len(w.buf)
+		if take > len(p) {
+			take = len(p)
+		}
+		w.buf = append(w.buf, p[:take]...)
+		p = p[take:]
+		if len(w.buf) == backupChunkSize {
+			if err := w.flush(); err != nil {
+				return 0, err
+			}
+		}
+	}
+	return n, nil
+}
+
+func (w *chunkWriter) flush() error {
+	if len(w.buf) == 0 {
+		return nil
+	}
+	err := w.send(&BackupChunk{Data: w.buf})
+	w.buf = nil
+	return err
+}
+
+// chunkReader reads the data from a stream of chunks.
+type chunkReader struct {
+	recv func() (*BackupChunk, error)
+	buf  []byte
+	// left is how many more bytes may be received.
+	left int64
+}
+
+func (r *chunkReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		chunk, err := r.recv()
+		if err != nil {
+			return 0, err
+		}
+		if r.left -= int64(len(chunk.Data)); r.left < 0 {
+			return 0, errBackupTooBig
+		}
+		r.buf = chunk.Data
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
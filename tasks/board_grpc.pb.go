@@ -0,0 +1,177 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             (unknown)
+// source: tasks/board.proto
+
+package tasks
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// BoardsClient is the client API for Boards service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type BoardsClient interface {
+	CreateBoard(ctx context.Context, in *Board, opts ...grpc.CallOption) (*Board, error)
+	GetBoard(ctx context.Context, in *GetBoardRequest, opts ...grpc.CallOption) (*BoardView, error)
+	Move(ctx context.Context, in *MoveRequest, opts ...grpc.CallOption) (*Task, error)
+}
+
+type boardsClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewBoardsClient(cc grpc.ClientConnInterface) BoardsClient {
+	return &boardsClient{cc}
+}
+
+func (c *boardsClient) CreateBoard(ctx context.Context, in *Board, opts ...grpc.CallOption) (*Board, error) {
+	out := new(Board)
+	err := c.cc.Invoke(ctx, "/task.Boards/CreateBoard", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *boardsClient) GetBoard(ctx context.Context, in *GetBoardRequest, opts ...grpc.CallOption) (*BoardView, error) {
+	out := new(BoardView)
+	err := c.cc.Invoke(ctx, "/task.Boards/GetBoard", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *boardsClient) Move(ctx context.Context, in *MoveRequest, opts ...grpc.CallOption) (*Task, error) {
+	out := new(Task)
+	err := c.cc.Invoke(ctx, "/task.Boards/Move", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// BoardsServer is the server API for Boards service.
+// All implementations must embed UnimplementedBoardsServer
+// for forward compatibility
+type BoardsServer interface {
+	CreateBoard(context.Context, *Board) (*Board, error)
+	GetBoard(context.Context, *GetBoardRequest) (*BoardView, error)
+	Move(context.Context, *MoveRequest) (*Task, error)
+	mustEmbedUnimplementedBoardsServer()
+}
+
+// UnimplementedBoardsServer must be embedded to have forward compatible implementations.
+type UnimplementedBoardsServer struct {
+}
+
+func (UnimplementedBoardsServer) CreateBoard(context.Context, *Board) (*Board, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateBoard not implemented")
+}
+func (UnimplementedBoardsServer) GetBoard(context.Context, *GetBoardRequest) (*BoardView, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBoard not implemented")
+}
+func (UnimplementedBoardsServer) Move(context.Context, *MoveRequest) (*Task, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Move not implemented")
+}
+func (UnimplementedBoardsServer) mustEmbedUnimplementedBoardsServer() {}
+
+// UnsafeBoardsServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to BoardsServer will
+// result in compilation errors.
+type UnsafeBoardsServer interface {
+	mustEmbedUnimplementedBoardsServer()
+}
+
+func RegisterBoardsServer(s grpc.ServiceRegistrar, srv BoardsServer) {
+	s.RegisterService(&Boards_ServiceDesc, srv)
+}
+
+func _Boards_CreateBoard_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Board)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BoardsServer).CreateBoard(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/task.Boards/CreateBoard",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BoardsServer).CreateBoard(ctx, req.(*Board))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Boards_GetBoard_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBoardRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BoardsServer).GetBoard(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/task.Boards/GetBoard",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BoardsServer).GetBoard(ctx, req.(*GetBoardRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Boards_Move_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MoveRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BoardsServer).Move(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/task.Boards/Move",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BoardsServer).Move(ctx, req.(*MoveRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Boards_ServiceDesc is the grpc.ServiceDesc for Boards service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Boards_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "task.Boards",
+	HandlerType: (*BoardsServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateBoard",
+			Handler:    _Boards_CreateBoard_Handler,
+		},
+		{
+			MethodName: "GetBoard",
+			Handler:    _Boards_GetBoard_Handler,
+		},
+		{
+			MethodName: "Move",
+			Handler:    _Boards_Move_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "tasks/board.proto",
+}
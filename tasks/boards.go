@@ -0,0 +1,271 @@
+package tasks
+
+import (
+	"context"
+	"sort"
+
+	"github.com/teamwork/log"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// boardsKey is the meta key the boards are saved under.
+const boardsKey = "boards"
+
+type boardState struct {
+	boards map[int64]*Board
+	nextID int64
+}
+
+func (bs *boardState) load(tx Tx) error {
+	stored := &StoredBoards{}
+	if err := getState(tx, boardsKey, stored); err != nil {
+		return err
+	}
+	bs.boards = make(map[int64]*Board)
+	bs.nextID = stored.LastId
+	for _, b := range stored.Boards {
+		bs.boards[b.Id] = b
+		bs.nextID = maxInt64(bs.nextID, b.Id)
+	}
+	return nil
+}
+
+func (bs *boardState) save(tx Tx) error {
+	stored := &StoredBoards{LastId: bs.nextID}
+	for _, b := range bs.boards {
+		stored.Boards = append(stored.Boards, b)
+	}
+	sort.Slice(stored.Boards, func(i, j int) bool {
+		return stored.Boards[i].Id < stored.Boards[j].Id
+	})
+	return putState(tx, boardsKey, stored)
+}
+
+type BoardService struct {
+	UnimplementedBoardsServer
+
+	tasks *TaskService
+}
+
+func NewBoardService(tasks *TaskService) *BoardService {
+	return &BoardService{tasks: tasks}
+}
+
+func (s *BoardService) CreateBoard(c context.Context, b *Board) (_ *Board, err error) {
+	if b.Name == "" {
+		return nil, status.Error(codes.InvalidArgument, "board name is required")
+	}
+	if len(b.Columns) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "board needs at least one column")
+	}
+	names := make(map[string]bool)
+	statuses := make(map[Status]bool)
+	for _, col := range b.Columns {
+		if col.Name == "" || names[col.Name] {
+			return nil, status.Errorf(codes.InvalidArgument, "column names must be unique and non-empty")
+		}
+		if statuses[col.Status] {
+			return nil, status.Errorf(codes.InvalidArgument, "status %s is used by more than one column", col.Status)
+		}
+		if col.WipLimit < 0 {
+			return nil, status.Errorf(codes.InvalidArgument, "column %s has a negative WIP limit", col.Name)
+		}
+		names[col.Name] = true
+		statuses[col.Status] = true
+	}
+
+	board := proto.Clone(b).(*Board)
+
+	ts := s.tasks
+	ts.mu.Lock()
+	defer ts.unlock(&err)
+
+	ts.boards.nextID++
+	board.Id = ts.boards.nextID
+	ts.boards.boards[board.Id] = board
+	ts.stateChangedLocked(ts.boards)
+
+	log.Infof("Created board %d (%s)", board.Id, board.Name)
+	return proto.Clone(board).(*Board), nil
+}
+
+func (s *BoardService) GetBoard(c context.Context, r *GetBoardRequest) (*BoardView, error) {
+	s.tasks.mu.RLock()
+	defer s.tasks.mu.RUnlock()
+
+	board, err := s.tasks.boardLocked(r.Id)
+	if err != nil {
+		return nil, err
+	}
+	view := &BoardView{Board: proto.Clone(board).(*Board)}
+	for _, col := range board.Columns {
+		cards := &ColumnCards{Column: col}
+		for _, t := range s.tasks.columnLocked(board.Project, col.Status) {
+			cards.Tasks = append(cards.Tasks, proto.Clone(t).(*Task))
+		}
+		view.Columns = append(view.Columns, cards)
+	}
+	return view, nil
+}
+
+// Move moves a task to a column and position on the board. Usually only
+// the moved task is rewritten, with its new rank picked between its new
+// neighbours, but once ranks grow too long the column is ranked afresh.
+func (s *BoardService) Move(c context.Context, r *MoveRequest) (_ *Task, err error) {
+	ts := s.tasks
+	ts.mu.Lock()
+	defer ts.unlock(&err)
+
+	board, err := ts.boardLocked(r.BoardId)
+	if err != nil {
+		return nil, err
+	}
+	var col *Column
+	for _, bc := range board.Columns {
+		if bc.Name == r.Column {
+			col = bc
+		}
+	}
+	if col == nil {
+		return nil, status.Errorf(codes.NotFound, "board %d has no column %s", board.Id, r.Column)
+	}
+
+	t, ok := ts.tasks[r.TaskId]
+	if !ok || (board.Project != "" && t.Project != board.Project) {
+		return nil, status.Errorf(codes.NotFound, "task %d not found on board %d", r.TaskId, board.Id)
+	}
+	if t.ArchivedAt != nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "task %d is archived", t.Id)
+	}
+
+	// Put the task in its place in the column, taking it out first if it's
+	// already there, to find the neighbours it will sit between. The WIP
+	// limit is checked when the move is committed.
+	var order []*Task
+	found := r.AfterId == 0
+	if found {
+		order = append(order, t)
+	}
+	for _, card := range ts.columnLocked(board.Project, col.Status) {
+		if card.Id == t.Id {
+			continue
+		}
+		order = append(order, card)
+		if card.Id == r.AfterId {
+			order = append(order, t)
+			found = true
+		}
+	}
+	if !found {
+		return nil, status.Errorf(codes.NotFound, "task %d is not in column %s", r.AfterId, col.Name)
+	}
+	var before, after string
+	for i, card := range order {
+		if card == t {
+			if i > 0 {
+				before = order[i-1].Rank
+			}
+			if i+1 < len(order) {
+				after = order[i+1].Rank
+			}
+			break
+		}
+	}
+
+	old := proto.Clone(t).(*Task)
+	t.Status = col.Status
+	t.Rank = rankBetween(before, after)
+	ts.changedLocked(old, t)
+	if len(t.Rank) > maxRankLength {
+		ts.rerankLocked(order)
+	}
+
+	log.Infof("Moved task %d to %s on board %d", t.Id, col.Name, board.Id)
+	return proto.Clone(t).(*Task), nil
+}
+
+// boardLocked returns the board with the given id. s.mu must be held.
+func (s *TaskService) boardLocked(id int64) (*Board, error) {
+	b, ok := s.boards.boards[id]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "board %d not found", id)
+	}
+	return b, nil
+}
+
+// rerankLocked spreads the ranks of cards, which are a column in order,
+// evenly, rewriting every card whose rank changes. s.mu must be held.
+func (s *TaskService) rerankLocked(cards []*Task) {
+	for i, rank := range spreadRanks(len(cards)) {
+		t := cards[i]
+		if t.Rank == rank {
+			continue
+		}
+		old := proto.Clone(t).(*Task)
+		t.Rank = rank
+		s.changedLocked(old, t)
+	}
+	log.Infof("Ranked %d cards afresh", len(cards))
+}
+
+// checkWIPLocked fails if a change waiting to be committed put a task in a
+// board column which is then over its WIP limit. It's checked on commit,
+// so every way a task can reach a column is covered, bar lowering the
+// limit. s.mu must be held.
+func (s *TaskService) checkWIPLocked() error {
+	type column struct {
+		project string
+		status  Status
+	}
+	entered := make(map[column]bool)
+	for _, c := range s.changes {
+		t := c.new
+		if t == nil || t.ArchivedAt != nil {
+			continue
+		}
+		if c.old != nil && c.old.ArchivedAt == nil && c.old.Status == t.Status && c.old.Project == t.Project {
+			continue
+		}
+		entered[column{t.Project, t.Status}] = true
+		entered[column{"", t.Status}] = true
+	}
+	if len(entered) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, 0, len(s.boards.boards))
+	for id := range s.boards.boards {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	for _, id := range ids {
+		b := s.boards.boards[id]
+		for _, col := range b.Columns {
+			if col.WipLimit <= 0 || !entered[column{b.Project, col.Status}] {
+				continue
+			}
+			if n := len(s.columnLocked(b.Project, col.Status)); n > int(col.WipLimit) {
+				return status.Errorf(codes.FailedPrecondition, "column %s on board %d would be over its WIP limit of %d", col.Name, b.Id, col.WipLimit)
+			}
+		}
+	}
+	return nil
+}
+
+// columnLocked returns the unarchived tasks in project with the given
+// status, in rank order. An empty project matches every task. s.mu must be
+// held.
+func (s *TaskService) columnLocked(project string, st Status) []*Task {
+	var cards []*Task
+	for _, t := range s.tasks {
+		if t.Status == st && t.ArchivedAt == nil && (project == "" || t.Project == project) {
+			cards = append(cards, t)
+		}
+	}
+	sort.Slice(cards, func(i, j int) bool {
+		return cards[i].Rank < cards[j].Rank
+	})
+	return cards
+}
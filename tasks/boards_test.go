@@ -0,0 +1,102 @@
+package tasks
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// The WIP limit holds however a task reaches a column.
+func TestWIPLimit(t *testing.T) {
+	ctx := context.Background()
+	ts, err := NewTaskService(NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	board := &Board{Name: "b", Project: "p", Columns: []*Column{
+		{Name: "todo", Status: Status_TODO, WipLimit: 2},
+		{Name: "doing", Status: Status_IN_PROGRESS, WipLimit: 1},
+	}}
+	if _, err := NewBoardService(ts).CreateBoard(ctx, board); err != nil {
+		t.Fatal(err)
+	}
+	for _, title := range []string{"a", "b"} {
+		if _, err := ts.Create(ctx, &TaskRequest{Title: title, Project: "p"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := ts.Create(ctx, &TaskRequest{Title: "c", Project: "p"}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("creating a task in a full column returned %v", err)
+	}
+	if _, err := ts.Create(ctx, &TaskRequest{Title: "c", Project: "q"}); err != nil {
+		t.Errorf("creating a task in another project: %v", err)
+	}
+
+	start := func(id int64) error {
+		_, err := ts.Update(ctx, &UpdateRequest{
+			Id:         id,
+			Task:       &Task{Status: Status_IN_PROGRESS},
+			UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"status"}},
+		})
+		return err
+	}
+	if err := start(1); err != nil {
+		t.Fatal(err)
+	}
+	if err := start(2); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("updating a task into a full column returned %v", err)
+	}
+	task, err := ts.Get(ctx, &GetRequest{Id: 2})
+	if err != nil || task.Status != Status_TODO {
+		t.Errorf("task after the update failed is %v, %v", task, err)
+	}
+	if _, err := NewBoardService(ts).Move(ctx, &MoveRequest{BoardId: 1, TaskId: 2, Column: "doing"}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("moving a task into a full column returned %v", err)
+	}
+}
+
+// Moving cards into the same spot over and over doesn't grow their ranks
+// without end.
+func TestMoveRanks(t *testing.T) {
+	ctx := context.Background()
+	ts, err := NewTaskService(NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	boards := NewBoardService(ts)
+	board := &Board{Name: "b", Project: "p", Columns: []*Column{{Name: "todo", Status: Status_TODO}}}
+	if _, err := boards.CreateBoard(ctx, board); err != nil {
+		t.Fatal(err)
+	}
+	for _, title := range []string{"a", "b", "c", "d"} {
+		if _, err := ts.Create(ctx, &TaskRequest{Title: title, Project: "p"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Each move squeezes a card in straight after the first.
+	for i := 0; i < 200; i++ {
+		id := int64(2 + i%2)
+		if _, err := boards.Move(ctx, &MoveRequest{BoardId: 1, TaskId: id, Column: "todo", AfterId: 1}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	view, err := boards.GetBoard(ctx, &GetBoardRequest{Id: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var order []int64
+	for _, card := range view.Columns[0].Tasks {
+		order = append(order, card.Id)
+		if len(card.Rank) > maxRankLength {
+			t.Errorf("task %d has rank %q", card.Id, card.Rank)
+		}
+	}
+	if len(order) != 4 || order[0] != 1 || order[1] != 3 || order[2] != 2 || order[3] != 4 {
+		t.Errorf("cards are in order %v, want [1 3 2 4]", order)
+	}
+}
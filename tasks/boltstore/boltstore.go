@@ -0,0 +1,288 @@
+// Package boltstore keeps tasks in a single bbolt database file. Tasks are
+// stored as protobuf, keyed by id. There are no index buckets, as the
+// TaskService holds every task in memory and only reads them all at once.
+//
+// Importing the package registers it with tasks.OpenStore as "bolt",
+// taking the database's path, as in "bolt:/var/lib/tasks.db".
+package boltstore
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/andyantrim/grpc-example/tasks"
+	bolt "go.etcd.io/bbolt"
+	"google.golang.org/protobuf/proto"
+)
+
+var (
+	tasksBucket  = []byte("tasks")
+	blobsBucket  = []byte("blobs")
+	metaBucket   = []byte("meta")
+	outboxBucket = []byte("outbox")
+
+	buckets = [][]byte{tasksBucket, blobsBucket, metaBucket, outboxBucket}
+
+	// oldBuckets held indexes which nothing read. They're removed from
+	// databases which still have them.
+	oldBuckets = [][]byte{[]byte("by_status"), []byte("by_assignee"), []byte("by_due")}
+)
+
+// lockTimeout is how long Open waits for another process to let go of the
+// database.
+const lockTimeout = 5 * time.Second
+
+func init() {
+	tasks.RegisterStore("bolt", func(path string) (tasks.Store, error) {
+		if path == "" {
+			return nil, errors.New("bolt store needs a path, as in bolt:/var/lib/tasks.db")
+		}
+		return Open(path)
+	})
+}
+
+type Store struct {
+	db *bolt.DB
+}
+
+// Open opens the database at path, creating it if needed.
+func Open(path string) (*Store, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: lockTimeout})
+	if err != nil {
+		return nil, fmt.Errorf("opening %s: %v", path, err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range buckets {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		for _, name := range oldBuckets {
+			if err := tx.DeleteBucket(name); err != nil && !errors.Is(err, bolt.ErrBucketNotFound) {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &Store{db: db}, nil
+}
+
+func (s *Store) Begin(writable bool) (tasks.Tx, error) {
+	tx, err := s.db.Begin(writable)
+	if err != nil {
+		return nil, err
+	}
+	return &Tx{tx: tx}, nil
+}
+
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Backup writes a consistent copy of the database to w while the store
+// carries on serving.
+func (s *Store) Backup(w io.Writer) (int64, error) {
+	var n int64
+	err := s.db.View(func(tx *bolt.Tx) error {
+		var err error
+		n, err = tx.WriteTo(w)
+		return err
+	})
+	return n, err
+}
+
+// Tx is a bbolt transaction.
+type Tx struct {
+	tx   *bolt.Tx
+	done bool
+}
+
+func (t *Tx) Get(id int64) (*tasks.Task, error) {
+	if t.done {
+		return nil, tasks.ErrTxDone
+	}
+	return t.get(id)
+}
+
+func (t *Tx) get(id int64) (*tasks.Task, error) {
+	b := t.tx.Bucket(tasksBucket).Get(idKey(id))
+	if b == nil {
+		return nil, tasks.ErrNotFound
+	}
+	task := &tasks.Task{}
+	if err := proto.Unmarshal(b, task); err != nil {
+		return nil, fmt.Errorf("decoding task %d: %v", id, err)
+	}
+	return task, nil
+}
+
+// List walks the tasks bucket with a cursor, starting after the given id.
+func (t *Tx) List(after int64, limit int) ([]*tasks.Task, error) {
+	if t.done {
+		return nil, tasks.ErrTxDone
+	}
+	var list []*tasks.Task
+	c := t.tx.Bucket(tasksBucket).Cursor()
+	for k, v := c.Seek(idKey(after + 1)); k != nil; k, v = c.Next() {
+		if limit > 0 && len(list) == limit {
+			break
+		}
+		task := &tasks.Task{}
+		if err := proto.Unmarshal(v, task); err != nil {
+			return nil, fmt.Errorf("decoding task %d: %v", keyID(k), err)
+		}
+		list = append(list, task)
+	}
+	return list, nil
+}
+
+func (t *Tx) Put(task *tasks.Task) error {
+	if err := t.check(); err != nil {
+		return err
+	}
+	b, err := proto.Marshal(task)
+	if err != nil {
+		return err
+	}
+	return t.tx.Bucket(tasksBucket).Put(idKey(task.Id), b)
+}
+
+func (t *Tx) Delete(id int64) error {
+	if err := t.check(); err != nil {
+		return err
+	}
+	return t.tx.Bucket(tasksBucket).Delete(idKey(id))
+}
+
+func (t *Tx) GetBlob(key string) ([]byte, error) {
+	return t.getBytes(blobsBucket, key)
+}
+
+func (t *Tx) PutBlob(key string, data []byte) error {
+	return t.putBytes(blobsBucket, key, data)
+}
+
+func (t *Tx) GetMeta(key string) ([]byte, error) {
+	return t.getBytes(metaBucket, key)
+}
+
+func (t *Tx) PutMeta(key string, value []byte) error {
+	return t.putBytes(metaBucket, key, value)
+}
+
+func (t *Tx) ListMeta() ([]string, error) {
+	if t.done {
+		return nil, tasks.ErrTxDone
+	}
+	var keys []string
+	err := t.tx.Bucket(metaBucket).ForEach(func(k, _ []byte) error {
+		keys = append(keys, string(k))
+		return nil
+	})
+	return keys, err
+}
+
+func (t *Tx) PutOutbox(seq int64, data []byte) error {
+	if err := t.check(); err != nil {
+		return err
+	}
+	return t.tx.Bucket(outboxBucket).Put(idKey(seq), append([]byte{}, data...))
+}
+
+// ListOutbox walks the outbox bucket with a cursor, starting after the
+// given seq.
+func (t *Tx) ListOutbox(after int64, limit int) ([]tasks.OutboxEntry, error) {
+	if t.done {
+		return nil, tasks.ErrTxDone
+	}
+	var list []tasks.OutboxEntry
+	c := t.tx.Bucket(outboxBucket).Cursor()
+	for k, v := c.Seek(idKey(after + 1)); k != nil; k, v = c.Next() {
+		if limit > 0 && len(list) == limit {
+			break
+		}
+		list = append(list, tasks.OutboxEntry{Seq: keyID(k), Data: append([]byte{}, v...)})
+	}
+	return list, nil
+}
+
+func (t *Tx) TrimOutbox(upTo int64) error {
+	if err := t.check(); err != nil {
+		return err
+	}
+	c := t.tx.Bucket(outboxBucket).Cursor()
+	for k, _ := c.First(); k != nil && keyID(k) <= upTo; k, _ = c.Next() {
+		if err := c.Delete(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *Tx) getBytes(bucket []byte, key string) ([]byte, error) {
+	if t.done {
+		return nil, tasks.ErrTxDone
+	}
+	b := t.tx.Bucket(bucket).Get([]byte(key))
+	if b == nil {
+		return nil, tasks.ErrNotFound
+	}
+	// bbolt's slices are only valid during the transaction.
+	return append([]byte{}, b...), nil
+}
+
+func (t *Tx) putBytes(bucket []byte, key string, value []byte) error {
+	if err := t.check(); err != nil {
+		return err
+	}
+	// bbolt treats a nil value as missing, so empty values are stored
+	// as an empty slice.
+	return t.tx.Bucket(bucket).Put([]byte(key), append([]byte{}, value...))
+}
+
+func (t *Tx) Commit() error {
+	if t.done {
+		return tasks.ErrTxDone
+	}
+	t.done = true
+	if !t.tx.Writable() {
+		return t.tx.Rollback()
+	}
+	return t.tx.Commit()
+}
+
+func (t *Tx) Rollback() error {
+	if t.done {
+		return nil
+	}
+	t.done = true
+	return t.tx.Rollback()
+}
+
+func (t *Tx) check() error {
+	if t.done {
+		return tasks.ErrTxDone
+	}
+	if !t.tx.Writable() {
+		return tasks.ErrReadOnly
+	}
+	return nil
+}
+
+// idKey encodes an id so keys sort in id order.
+func idKey(id int64) []byte {
+	k := make([]byte, 8)
+	binary.BigEndian.PutUint64(k, uint64(id))
+	return k
+}
+
+func keyID(k []byte) int64 {
+	return int64(binary.BigEndian.Uint64(k))
+}
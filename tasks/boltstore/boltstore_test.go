@@ -0,0 +1,79 @@
+package boltstore
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/andyantrim/grpc-example/tasks"
+	"github.com/andyantrim/grpc-example/tasks/storetest"
+	bolt "go.etcd.io/bbolt"
+)
+
+func TestStore(t *testing.T) {
+	storetest.Run(t, storetest.Backend{
+		Open: func(t *testing.T) tasks.Store {
+			s, err := Open(filepath.Join(t.TempDir(), "tasks.db"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			return s
+		},
+		Reopen: func(t *testing.T, s tasks.Store) tasks.Store {
+			path := s.(*Store).db.Path()
+			if err := s.Close(); err != nil {
+				t.Fatal(err)
+			}
+			s, err := Open(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return s
+		},
+	})
+}
+
+// The index buckets earlier versions kept are dropped when the database is
+// opened.
+func TestOldBuckets(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.db")
+	db, err := bolt.Open(path, 0600, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range oldBuckets {
+			b, err := tx.CreateBucket(name)
+			if err != nil {
+				return err
+			}
+			if err := b.Put([]byte("key"), nil); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+
+	s, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	err = s.db.View(func(tx *bolt.Tx) error {
+		return tx.ForEach(func(name []byte, _ *bolt.Bucket) error {
+			for _, old := range oldBuckets {
+				if bytes.Equal(name, old) {
+					t.Errorf("bucket %s is still there", name)
+				}
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
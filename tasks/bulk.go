@@ -0,0 +1,210 @@
+package tasks
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/teamwork/log"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// bulkBatchSize is how many tasks a bulk operation changes each time it
+// takes the task lock, so other requests aren't blocked for long.
+const bulkBatchSize = 100
+
+// bulkExpiry is how long a finished operation can still be fetched.
+const bulkExpiry = time.Hour
+
+type BulkService struct {
+	UnimplementedBulkServer
+
+	tasks *TaskService
+
+	mu     sync.Mutex
+	ops    map[string]*bulkOp
+	nextID int64
+}
+
+// bulkOp is an operation and its bookkeeping, guarded by BulkService.mu.
+type bulkOp struct {
+	op *Operation
+	// cancel is set by CancelOperation, and checked between batches.
+	cancel   bool
+	finished time.Time
+}
+
+func NewBulkService(tasks *TaskService) *BulkService {
+	return &BulkService{
+		tasks: tasks,
+		ops:   make(map[string]*bulkOp),
+	}
+}
+
+func (s *BulkService) BulkUpdate(c context.Context, r *BulkUpdateRequest) (*Operation, error) {
+	if r.Filter == nil {
+		return nil, status.Error(codes.InvalidArgument, "filter is required")
+	}
+	paths := r.UpdateMask.GetPaths()
+	if err := validateMask(paths); err != nil {
+		return nil, err
+	}
+
+	patch := proto.Clone(r.Task).(*Task)
+	return s.start(r.Filter, r.DryRun, func(t *Task) error {
+		return s.tasks.updateLocked(t, patch, paths)
+	})
+}
+
+func (s *BulkService) BulkDelete(c context.Context, r *BulkDeleteRequest) (*Operation, error) {
+	if r.Filter == nil {
+		return nil, status.Error(codes.InvalidArgument, "filter is required")
+	}
+
+	return s.start(r.Filter, r.DryRun, func(t *Task) error {
+		s.tasks.deleteLocked(t.Id)
+		return nil
+	})
+}
+
+func (s *BulkService) GetOperation(c context.Context, r *GetOperationRequest) (*Operation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, err := s.getLocked(r.Id)
+	if err != nil {
+		return nil, err
+	}
+	return proto.Clone(b.op).(*Operation), nil
+}
+
+// CancelOperation stops an operation after its current batch. Tasks already
+// changed stay changed, and cancelling a finished operation does nothing.
+func (s *BulkService) CancelOperation(c context.Context, r *CancelOperationRequest) (*Operation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, err := s.getLocked(r.Id)
+	if err != nil {
+		return nil, err
+	}
+	if !b.op.Done {
+		b.cancel = true
+	}
+	return proto.Clone(b.op).(*Operation), nil
+}
+
+// getLocked returns an operation, dropping any that finished more than
+// bulkExpiry ago first.
+func (s *BulkService) getLocked(id string) (*bulkOp, error) {
+	s.expireLocked()
+	b, ok := s.ops[id]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "operation %s not found", id)
+	}
+	return b, nil
+}
+
+func (s *BulkService) expireLocked() {
+	now := s.tasks.now()
+	for id, b := range s.ops {
+		if b.op.Done && now.Sub(b.finished) > bulkExpiry {
+			delete(s.ops, id)
+		}
+	}
+}
+
+// start snapshots the tasks matching f and applies fn to them in the
+// background. Each task is checked against the filter again before it is
+// changed, in case it was edited after the operation started.
+func (s *BulkService) start(f *TaskFilter, dryRun bool, fn func(*Task) error) (*Operation, error) {
+	s.tasks.mu.RLock()
+	matched := s.tasks.matchLocked(f)
+	s.tasks.mu.RUnlock()
+
+	ids := make([]int64, len(matched))
+	for i, t := range matched {
+		ids[i] = t.Id
+	}
+	filter := proto.Clone(f).(*TaskFilter)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.expireLocked()
+	s.nextID++
+	op := &Operation{
+		Id:      fmt.Sprintf("bulk-%d", s.nextID),
+		Matched: int64(len(ids)),
+		Done:    dryRun,
+	}
+	b := &bulkOp{op: op}
+	s.ops[op.Id] = b
+	if dryRun {
+		b.finished = s.tasks.now()
+		return proto.Clone(op).(*Operation), nil
+	}
+
+	go s.run(b, ids, filter, fn)
+
+	log.Infof("Started %s on %d tasks", op.Id, len(ids))
+	return proto.Clone(op).(*Operation), nil
+}
+
+func (s *BulkService) run(b *bulkOp, ids []int64, f *TaskFilter, fn func(*Task) error) {
+	op := b.op
+	for {
+		n := bulkBatchSize
+		if n > len(ids) {
+			n = len(ids)
+		}
+		batch := ids[:n]
+		ids = ids[n:]
+
+		var err error
+		done, skipped := 0, 0
+		s.tasks.mu.Lock()
+		for _, id := range batch {
+			t, ok := s.tasks.tasks[id]
+			if !ok || !f.matches(t) {
+				skipped++
+				continue
+			}
+			if err = fn(t); err != nil {
+				break
+			}
+			done++
+		}
+		// Each batch is saved as a whole, so a failure undoes the rest of
+		// its batch.
+		s.tasks.unlock(&err)
+		if err != nil {
+			done, skipped = 0, 0
+		}
+
+		// Cancellation is checked under the same lock that finishes the
+		// operation, so a cancel either stops the next batch or arrives
+		// after the operation is done and changes nothing.
+		s.mu.Lock()
+		op.Processed += int64(done)
+		op.Skipped += int64(skipped)
+		if err == nil && len(ids) > 0 && !b.cancel {
+			s.mu.Unlock()
+			continue
+		}
+		op.Done = true
+		op.Cancelled = err == nil && len(ids) > 0
+		b.finished = s.tasks.now()
+		if err != nil {
+			op.Error = err.Error()
+			log.Error(err, fmt.Sprintf("%s failed after %d tasks", op.Id, op.Processed))
+		} else {
+			log.Infof("Finished %s, %d of %d tasks processed and %d skipped", op.Id, op.Processed, op.Matched, op.Skipped)
+		}
+		s.mu.Unlock()
+		return
+	}
+}
@@ -0,0 +1,125 @@
+package tasks
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// waitOperation polls an operation until it is done.
+func waitOperation(t *testing.T, s *BulkService, id string) *Operation {
+	t.Helper()
+	for i := 0; i < 500; i++ {
+		op, err := s.GetOperation(context.Background(), &GetOperationRequest{Id: id})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if op.Done {
+			return op
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("%s didn't finish", id)
+	return nil
+}
+
+func TestBulkCancel(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	ts, err := NewTaskService(NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts.now = func() time.Time { return now }
+	for i := 0; i < bulkBatchSize+50; i++ {
+		if _, err := ts.Create(ctx, &TaskRequest{Title: "t", Project: "p"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	bulk := NewBulkService(ts)
+
+	// The first batch waits until the operation has been cancelled.
+	started, release := make(chan struct{}), make(chan struct{})
+	first := true
+	op, err := bulk.start(&TaskFilter{Project: "p"}, false, func(*Task) error {
+		if first {
+			first = false
+			close(started)
+			<-release
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-started
+	if _, err := bulk.CancelOperation(ctx, &CancelOperationRequest{Id: op.Id}); err != nil {
+		t.Fatal(err)
+	}
+	close(release)
+	op = waitOperation(t, bulk, op.Id)
+	if !op.Cancelled || op.Processed != bulkBatchSize {
+		t.Errorf("cancelled operation finished as %v", op)
+	}
+
+	// Cancelling an operation that has processed everything changes nothing.
+	op, err = bulk.start(&TaskFilter{Project: "p"}, false, func(*Task) error { return nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	op = waitOperation(t, bulk, op.Id)
+	op, err = bulk.CancelOperation(ctx, &CancelOperationRequest{Id: op.Id})
+	if err != nil || op.Cancelled || op.Processed != op.Matched {
+		t.Errorf("cancelling a finished operation returned %v, %v", op, err)
+	}
+
+	now = now.Add(bulkExpiry + time.Minute)
+	_, err = bulk.GetOperation(ctx, &GetOperationRequest{Id: op.Id})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("getting an expired operation returned %v", err)
+	}
+	if len(bulk.ops) != 0 {
+		t.Errorf("%d operations kept after expiring", len(bulk.ops))
+	}
+}
+
+// Tasks which are deleted or stop matching once an operation has started
+// are counted as skipped rather than processed.
+func TestBulkSkipped(t *testing.T) {
+	ctx := context.Background()
+	ts, err := NewTaskService(NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 4; i++ {
+		if _, err := ts.Create(ctx, &TaskRequest{Title: "t", Project: "p"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	bulk := NewBulkService(ts)
+
+	var changed []int64
+	op, err := bulk.start(&TaskFilter{Project: "p"}, false, func(task *Task) error {
+		if task.Id == 1 {
+			ts.deleteLocked(2)
+			if err := ts.updateLocked(ts.tasks[3], &Task{Project: "q"}, []string{"project"}); err != nil {
+				return err
+			}
+		}
+		changed = append(changed, task.Id)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	op = waitOperation(t, bulk, op.Id)
+	if op.Matched != 4 || op.Processed != 2 || op.Skipped != 2 || op.Error != "" {
+		t.Errorf("operation finished as %v, want 4 matched, 2 processed and 2 skipped", op)
+	}
+	if len(changed) != 2 || changed[0] != 1 || changed[1] != 4 {
+		t.Errorf("changed tasks %v, want [1 4]", changed)
+	}
+}
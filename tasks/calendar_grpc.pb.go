@@ -0,0 +1,249 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             (unknown)
+// source: tasks/calendar.proto
+
+package tasks
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// CalendarsClient is the client API for Calendars service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type CalendarsClient interface {
+	SetCalendar(ctx context.Context, in *Calendar, opts ...grpc.CallOption) (*Calendar, error)
+	GetCalendar(ctx context.Context, in *GetCalendarRequest, opts ...grpc.CallOption) (*Calendar, error)
+	ImportHolidays(ctx context.Context, in *ImportHolidaysRequest, opts ...grpc.CallOption) (*Calendar, error)
+	AddBusinessTime(ctx context.Context, in *AddBusinessTimeRequest, opts ...grpc.CallOption) (*AddBusinessTimeResponse, error)
+	BusinessTimeBetween(ctx context.Context, in *BusinessTimeBetweenRequest, opts ...grpc.CallOption) (*BusinessTimeBetweenResponse, error)
+}
+
+type calendarsClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewCalendarsClient(cc grpc.ClientConnInterface) CalendarsClient {
+	return &calendarsClient{cc}
+}
+
+func (c *calendarsClient) SetCalendar(ctx context.Context, in *Calendar, opts ...grpc.CallOption) (*Calendar, error) {
+	out := new(Calendar)
+	err := c.cc.Invoke(ctx, "/task.Calendars/SetCalendar", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *calendarsClient) GetCalendar(ctx context.Context, in *GetCalendarRequest, opts ...grpc.CallOption) (*Calendar, error) {
+	out := new(Calendar)
+	err := c.cc.Invoke(ctx, "/task.Calendars/GetCalendar", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *calendarsClient) ImportHolidays(ctx context.Context, in *ImportHolidaysRequest, opts ...grpc.CallOption) (*Calendar, error) {
+	out := new(Calendar)
+	err := c.cc.Invoke(ctx, "/task.Calendars/ImportHolidays", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *calendarsClient) AddBusinessTime(ctx context.Context, in *AddBusinessTimeRequest, opts ...grpc.CallOption) (*AddBusinessTimeResponse, error) {
+	out := new(AddBusinessTimeResponse)
+	err := c.cc.Invoke(ctx, "/task.Calendars/AddBusinessTime", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *calendarsClient) BusinessTimeBetween(ctx context.Context, in *BusinessTimeBetweenRequest, opts ...grpc.CallOption) (*BusinessTimeBetweenResponse, error) {
+	out := new(BusinessTimeBetweenResponse)
+	err := c.cc.Invoke(ctx, "/task.Calendars/BusinessTimeBetween", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CalendarsServer is the server API for Calendars service.
+// All implementations must embed UnimplementedCalendarsServer
+// for forward compatibility
+type CalendarsServer interface {
+	SetCalendar(context.Context, *Calendar) (*Calendar, error)
+	GetCalendar(context.Context, *GetCalendarRequest) (*Calendar, error)
+	ImportHolidays(context.Context, *ImportHolidaysRequest) (*Calendar, error)
+	AddBusinessTime(context.Context, *AddBusinessTimeRequest) (*AddBusinessTimeResponse, error)
+	BusinessTimeBetween(context.Context, *BusinessTimeBetweenRequest) (*BusinessTimeBetweenResponse, error)
+	mustEmbedUnimplementedCalendarsServer()
+}
+
+// UnimplementedCalendarsServer must be embedded to have forward compatible implementations.
+type UnimplementedCalendarsServer struct {
+}
+
+func (UnimplementedCalendarsServer) SetCalendar(context.Context, *Calendar) (*Calendar, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetCalendar not implemented")
+}
+func (UnimplementedCalendarsServer) GetCalendar(context.Context, *GetCalendarRequest) (*Calendar, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCalendar not implemented")
+}
+func (UnimplementedCalendarsServer) ImportHolidays(context.Context, *ImportHolidaysRequest) (*Calendar, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ImportHolidays not implemented")
+}
+func (UnimplementedCalendarsServer) AddBusinessTime(context.Context, *AddBusinessTimeRequest) (*AddBusinessTimeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddBusinessTime not implemented")
+}
+func (UnimplementedCalendarsServer) BusinessTimeBetween(context.Context, *BusinessTimeBetweenRequest) (*BusinessTimeBetweenResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BusinessTimeBetween not implemented")
+}
+func (UnimplementedCalendarsServer) mustEmbedUnimplementedCalendarsServer() {}
+
+// UnsafeCalendarsServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to CalendarsServer will
+// result in compilation errors.
+type UnsafeCalendarsServer interface {
+	mustEmbedUnimplementedCalendarsServer()
+}
+
+func RegisterCalendarsServer(s grpc.ServiceRegistrar, srv CalendarsServer) {
+	s.RegisterService(&Calendars_ServiceDesc, srv)
+}
+
+func _Calendars_SetCalendar_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Calendar)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CalendarsServer).SetCalendar(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/task.Calendars/SetCalendar",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CalendarsServer).SetCalendar(ctx, req.(*Calendar))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Calendars_GetCalendar_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetCalendarRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CalendarsServer).GetCalendar(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/task.Calendars/GetCalendar",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CalendarsServer).GetCalendar(ctx, req.(*GetCalendarRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Calendars_ImportHolidays_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ImportHolidaysRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CalendarsServer).ImportHolidays(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/task.Calendars/ImportHolidays",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CalendarsServer).ImportHolidays(ctx, req.(*ImportHolidaysRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Calendars_AddBusinessTime_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddBusinessTimeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CalendarsServer).AddBusinessTime(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/task.Calendars/AddBusinessTime",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CalendarsServer).AddBusinessTime(ctx, req.(*AddBusinessTimeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Calendars_BusinessTimeBetween_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BusinessTimeBetweenRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CalendarsServer).BusinessTimeBetween(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/task.Calendars/BusinessTimeBetween",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CalendarsServer).BusinessTimeBetween(ctx, req.(*BusinessTimeBetweenRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Calendars_ServiceDesc is the grpc.ServiceDesc for Calendars service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Calendars_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "task.Calendars",
+	HandlerType: (*CalendarsServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "SetCalendar",
+			Handler:    _Calendars_SetCalendar_Handler,
+		},
+		{
+			MethodName: "GetCalendar",
+			Handler:    _Calendars_GetCalendar_Handler,
+		},
+		{
+			MethodName: "ImportHolidays",
+			Handler:    _Calendars_ImportHolidays_Handler,
+		},
+		{
+			MethodName: "AddBusinessTime",
+			Handler:    _Calendars_AddBusinessTime_Handler,
+		},
+		{
+			MethodName: "BusinessTimeBetween",
+			Handler:    _Calendars_BusinessTimeBetween_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "tasks/calendar.proto",
+}
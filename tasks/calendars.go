@@ -0,0 +1,405 @@
+package tasks
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/teamwork/log"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const dateLayout = "2006-01-02"
+
+// defaultCalendar is used when neither the project nor the workspace has a
+// calendar.
+var defaultCalendar = &Calendar{
+	TimeZone:    "UTC",
+	WorkingDays: []Weekday{Weekday_MONDAY, Weekday_TUESDAY, Weekday_WEDNESDAY, Weekday_THURSDAY, Weekday_FRIDAY},
+	DayStart:    "09:00",
+	DayEnd:      "17:00",
+}
+
+// maxBusinessDays caps the days the calendar arithmetic is asked to step
+// through, at about ten years, so one request can't keep the server busy.
+const maxBusinessDays = 10 * 366
+
+// maxBusinessTime caps durations and spans of time the same way.
+const maxBusinessTime = maxBusinessDays * 24 * time.Hour
+
+// calendarsKey is the meta key the calendars are saved under.
+const calendarsKey = "calendars"
+
+// calendarState holds the working calendars by project, with the workspace
+// calendar under "".
+type calendarState struct {
+	byProject map[string]*Calendar
+}
+
+func (cs *calendarState) load(tx Tx) error {
+	stored := &StoredCalendars{}
+	if err := getState(tx, calendarsKey, stored); err != nil {
+		return err
+	}
+	cs.byProject = make(map[string]*Calendar)
+	for _, cal := range stored.Calendars {
+		cs.byProject[cal.Project] = cal
+	}
+	return nil
+}
+
+func (cs *calendarState) save(tx Tx) error {
+	stored := &StoredCalendars{}
+	for _, cal := range cs.byProject {
+		stored.Calendars = append(stored.Calendars, cal)
+	}
+	sort.Slice(stored.Calendars, func(i, j int) bool {
+		return stored.Calendars[i].Project < stored.Calendars[j].Project
+	})
+	return putState(tx, calendarsKey, stored)
+}
+
+type CalendarService struct {
+	UnimplementedCalendarsServer
+
+	tasks *TaskService
+}
+
+func NewCalendarService(tasks *TaskService) *CalendarService {
+	return &CalendarService{tasks: tasks}
+}
+
+// SetCalendar replaces a project's calendar, or the workspace one. Due
+// dates which have already been worked out aren't changed.
+func (s *CalendarService) SetCalendar(c context.Context, r *Calendar) (_ *Calendar, err error) {
+	cal, err := normalizeCalendar(r)
+	if err != nil {
+		return nil, err
+	}
+
+	ts := s.tasks
+	ts.mu.Lock()
+	defer ts.unlock(&err)
+
+	ts.calendars.byProject[cal.Project] = cal
+	ts.stateChangedLocked(ts.calendars)
+	log.Infof("Set calendar for project %q", cal.Project)
+	return proto.Clone(cal).(*Calendar), nil
+}
+
+// GetCalendar returns the calendar a project uses, which may be the
+// workspace or default calendar.
+func (s *CalendarService) GetCalendar(c context.Context, r *GetCalendarRequest) (*Calendar, error) {
+	ts := s.tasks
+	ts.mu.RLock()
+	defer ts.mu.RUnlock()
+
+	return proto.Clone(ts.calendarLocked(r.Project)).(*Calendar), nil
+}
+
+func (s *CalendarService) ImportHolidays(c context.Context, r *ImportHolidaysRequest) (_ *Calendar, err error) {
+	holidays, err := parseICS(r.Ics)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "can't read holidays: %v", err)
+	}
+
+	ts := s.tasks
+	ts.mu.Lock()
+	defer ts.unlock(&err)
+
+	// A project importing holidays gets its own copy of the calendar it was
+	// using.
+	next := proto.Clone(ts.calendarLocked(r.Project)).(*Calendar)
+	next.Project = r.Project
+	if r.Replace {
+		next.Holidays = nil
+	}
+	next.Holidays = append(next.Holidays, holidays...)
+	cal, err := normalizeCalendar(next)
+	if err != nil {
+		return nil, err
+	}
+	ts.calendars.byProject[cal.Project] = cal
+	ts.stateChangedLocked(ts.calendars)
+
+	log.Infof("Imported %d holidays for project %q", len(holidays), r.Project)
+	return proto.Clone(cal).(*Calendar), nil
+}
+
+func (s *CalendarService) AddBusinessTime(c context.Context, r *AddBusinessTimeRequest) (*AddBusinessTimeResponse, error) {
+	if r.From == nil {
+		return nil, status.Error(codes.InvalidArgument, "from is required")
+	}
+	if err := checkBusinessDays("days", r.Days); err != nil {
+		return nil, err
+	}
+	if err := checkBusinessTime("duration", r.Duration.AsDuration()); err != nil {
+		return nil, err
+	}
+
+	ts := s.tasks
+	ts.mu.RLock()
+	wc := compileCalendar(ts.calendarLocked(r.Project))
+	ts.mu.RUnlock()
+
+	at := r.From.AsTime()
+	if r.Days > 0 {
+		at = wc.addDays(at, int(r.Days))
+	}
+	at = wc.addTime(at, r.Duration.AsDuration())
+	return &AddBusinessTimeResponse{At: timestamppb.New(at)}, nil
+}
+
+func (s *CalendarService) BusinessTimeBetween(c context.Context, r *BusinessTimeBetweenRequest) (*BusinessTimeBetweenResponse, error) {
+	if r.From == nil || r.To == nil {
+		return nil, status.Error(codes.InvalidArgument, "from and to are required")
+	}
+	from, to := r.From.AsTime(), r.To.AsTime()
+	if to.Before(from) {
+		return nil, status.Error(codes.InvalidArgument, "to can't be before from")
+	}
+	if err := checkBusinessTime("the time between from and to", to.Sub(from)); err != nil {
+		return nil, err
+	}
+
+	ts := s.tasks
+	ts.mu.RLock()
+	wc := compileCalendar(ts.calendarLocked(r.Project))
+	ts.mu.RUnlock()
+
+	working, days := wc.between(from, to)
+	return &BusinessTimeBetweenResponse{
+		Working: durationpb.New(working),
+		Days:    int32(days),
+	}, nil
+}
+
+// repeatLocked creates the next occurrence of t, a repeating task which has
+// just been done. s.mu must be held.
+func (s *TaskService) repeatLocked(t *Task) {
+	from := s.now()
+	if due := t.DueAt.AsTime(); t.DueAt != nil && due.After(from) {
+		from = due
+	}
+	wc := compileCalendar(s.calendarLocked(t.Project))
+	next := &Task{
+		Title:       t.Title,
+		Description: t.Description,
+		ParentId:    t.ParentId,
+		Labels:      append([]string(nil), t.Labels...),
+		Project:     t.Project,
+		Priority:    t.Priority,
+		Assignee:    t.Assignee,
+		DueAt:       timestamppb.New(wc.addDays(from, int(t.RepeatEveryBusinessDays))),
+		Sla:         t.Sla,
+
+		DescriptionFormat:       t.DescriptionFormat,
+		RepeatEveryBusinessDays: t.RepeatEveryBusinessDays,
+	}
+	for name, v := range t.CustomFields {
+		if next.CustomFields == nil {
+			next.CustomFields = make(map[string]*CustomValue)
+		}
+		next.CustomFields[name] = proto.Clone(v).(*CustomValue)
+	}
+	for _, item := range t.Checklist {
+		s.nextChecklistID++
+		next.Checklist = append(next.Checklist, &ChecklistItem{Id: s.nextChecklistID, Text: item.Text})
+	}
+	id := s.insertLocked(next)
+	log.Infof("Task %d repeats as task %d", t.Id, id)
+}
+
+// checkBusinessDays checks a count of working days called name is in
+// range.
+func checkBusinessDays(name string, n int32) error {
+	if n < 0 || n > maxBusinessDays {
+		return status.Errorf(codes.InvalidArgument, "%s must be from 0 to %d", name, maxBusinessDays)
+	}
+	return nil
+}
+
+// checkBusinessTime checks a working time called name is in range.
+func checkBusinessTime(name string, d time.Duration) error {
+	if d < 0 || d > maxBusinessTime {
+		return status.Errorf(codes.InvalidArgument, "%s must be from 0 to %v", name, maxBusinessTime)
+	}
+	return nil
+}
+
+// calendarLocked returns the calendar used by project. s.mu must be held.
+func (s *TaskService) calendarLocked(project string) *Calendar {
+	if cal, ok := s.calendars.byProject[project]; ok {
+		return cal
+	}
+	if cal, ok := s.calendars.byProject[""]; ok {
+		return cal
+	}
+	return defaultCalendar
+}
+
+// normalizeCalendar checks c and returns a copy with the defaults filled in
+// and the holidays sorted by date.
+func normalizeCalendar(c *Calendar) (*Calendar, error) {
+	cal := proto.Clone(c).(*Calendar)
+	if cal.TimeZone == "" {
+		cal.TimeZone = defaultCalendar.TimeZone
+	}
+	if _, err := time.LoadLocation(cal.TimeZone); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "unknown time zone %q", cal.TimeZone)
+	}
+	if len(cal.WorkingDays) == 0 {
+		cal.WorkingDays = defaultCalendar.WorkingDays
+	}
+	for _, d := range cal.WorkingDays {
+		if d < Weekday_SUNDAY || d > Weekday_SATURDAY {
+			return nil, status.Errorf(codes.InvalidArgument, "unknown weekday %d", d)
+		}
+	}
+	if cal.DayStart == "" {
+		cal.DayStart = defaultCalendar.DayStart
+	}
+	if cal.DayEnd == "" {
+		cal.DayEnd = defaultCalendar.DayEnd
+	}
+	start, err := time.Parse("15:04", cal.DayStart)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "day_start %q isn't HH:MM", cal.DayStart)
+	}
+	end, err := time.Parse("15:04", cal.DayEnd)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "day_end %q isn't HH:MM", cal.DayEnd)
+	}
+	if !end.After(start) {
+		return nil, status.Error(codes.InvalidArgument, "day_end must be after day_start")
+	}
+
+	byDate := make(map[string]*Holiday)
+	for _, h := range cal.Holidays {
+		if _, err := time.Parse(dateLayout, h.Date); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "holiday date %q isn't YYYY-MM-DD", h.Date)
+		}
+		byDate[h.Date] = h
+	}
+	cal.Holidays = cal.Holidays[:0]
+	for _, h := range byDate {
+		cal.Holidays = append(cal.Holidays, h)
+	}
+	sort.Slice(cal.Holidays, func(i, j int) bool {
+		return cal.Holidays[i].Date < cal.Holidays[j].Date
+	})
+	return cal, nil
+}
+
+// workCalendar is a Calendar ready for date arithmetic.
+type workCalendar struct {
+	loc        *time.Location
+	days       [7]bool
+	start, end int // minutes after midnight
+	holidays   map[string]bool
+}
+
+// compileCalendar prepares c, which must have come from normalizeCalendar.
+func compileCalendar(c *Calendar) *workCalendar {
+	wc := &workCalendar{holidays: make(map[string]bool)}
+	wc.loc, _ = time.LoadLocation(c.TimeZone)
+	for _, d := range c.WorkingDays {
+		wc.days[d] = true
+	}
+	start, _ := time.Parse("15:04", c.DayStart)
+	end, _ := time.Parse("15:04", c.DayEnd)
+	wc.start = start.Hour()*60 + start.Minute()
+	wc.end = end.Hour()*60 + end.Minute()
+	for _, h := range c.Holidays {
+		wc.holidays[h.Date] = true
+	}
+	return wc
+}
+
+// day returns midnight at the start of the day t falls on.
+func (wc *workCalendar) day(t time.Time) time.Time {
+	y, m, d := t.In(wc.loc).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, wc.loc)
+}
+
+// nextDay returns midnight at the start of the day after day. Days are
+// stepped by date rather than 24 hours so clock changes don't matter.
+func (wc *workCalendar) nextDay(day time.Time) time.Time {
+	y, m, d := day.Date()
+	return time.Date(y, m, d+1, 0, 0, 0, 0, wc.loc)
+}
+
+func (wc *workCalendar) working(day time.Time) bool {
+	return wc.days[day.Weekday()] && !wc.holidays[day.Format(dateLayout)]
+}
+
+// hours returns when work starts and ends on day.
+func (wc *workCalendar) hours(day time.Time) (time.Time, time.Time) {
+	y, m, d := day.Date()
+	return time.Date(y, m, d, 0, wc.start, 0, 0, wc.loc), time.Date(y, m, d, 0, wc.end, 0, 0, wc.loc)
+}
+
+// addDays returns the end of the working day n working days after t's day.
+func (wc *workCalendar) addDays(t time.Time, n int) time.Time {
+	day := wc.day(t)
+	for n > 0 {
+		day = wc.nextDay(day)
+		if wc.working(day) {
+			n--
+		}
+	}
+	_, end := wc.hours(day)
+	return end
+}
+
+// addTime returns the moment d of working time after t.
+func (wc *workCalendar) addTime(t time.Time, d time.Duration) time.Time {
+	if d <= 0 {
+		return t
+	}
+	for day := wc.day(t); ; day = wc.nextDay(day) {
+		if !wc.working(day) {
+			continue
+		}
+		start, end := wc.hours(day)
+		if t.Before(start) {
+			t = start
+		}
+		if left := end.Sub(t); left > 0 {
+			if d <= left {
+				return t.Add(d)
+			}
+			d -= left
+		}
+	}
+}
+
+// between returns the working time from from to to, and how many whole
+// working days that covers.
+func (wc *workCalendar) between(from, to time.Time) (time.Duration, int) {
+	var total time.Duration
+	days := 0
+	for day := wc.day(from); day.Before(to); day = wc.nextDay(day) {
+		if !wc.working(day) {
+			continue
+		}
+		start, end := wc.hours(day)
+		if !from.After(start) && !to.Before(end) {
+			days++
+		}
+		if from.After(start) {
+			start = from
+		}
+		if to.Before(end) {
+			end = to
+		}
+		if end.After(start) {
+			total += end.Sub(start)
+		}
+	}
+	return total, days
+}
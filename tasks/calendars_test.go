@@ -0,0 +1,236 @@
+package tasks
+
+import (
+	"context"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Working days skip weekends and holidays, and are counted in the
+// calendar's time zone.
+func TestBusinessTime(t *testing.T) {
+	ctx := context.Background()
+	ts, err := NewTaskService(NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	cs := NewCalendarService(ts)
+	_, err = cs.SetCalendar(ctx, &Calendar{
+		Project:  "uk",
+		TimeZone: "Europe/London",
+		Holidays: []*Holiday{{Date: "2026-12-25", Name: "Christmas"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	london, err := time.LoadLocation("Europe/London")
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := func(s string) time.Time {
+		t, err := time.ParseInLocation("2006-01-02 15:04", s, london)
+		if err != nil {
+			panic(err)
+		}
+		return t
+	}
+
+	for _, tt := range []struct {
+		from string
+		days int32
+		d    time.Duration
+		want string
+	}{
+		{"2026-12-24 10:00", 0, 0, "2026-12-24 10:00"},
+		{"2026-12-24 10:00", 1, 0, "2026-12-28 17:00"},
+		{"2026-12-24 16:00", 0, 2 * time.Hour, "2026-12-28 10:00"},
+		{"2026-12-24 07:00", 0, 8 * time.Hour, "2026-12-24 17:00"},
+		{"2026-12-26 12:00", 1, time.Hour, "2026-12-29 10:00"},
+		// The clocks go forward on the Sunday.
+		{"2026-03-27 12:00", 1, 0, "2026-03-30 17:00"},
+	} {
+		resp, err := cs.AddBusinessTime(ctx, &AddBusinessTimeRequest{
+			Project:  "uk",
+			From:     timestamppb.New(at(tt.from)),
+			Days:     tt.days,
+			Duration: durationpb.New(tt.d),
+		})
+		if err != nil {
+			t.Errorf("adding %d days and %v to %s: %v", tt.days, tt.d, tt.from, err)
+			continue
+		}
+		if got := resp.At.AsTime(); !got.Equal(at(tt.want)) {
+			t.Errorf("adding %d days and %v to %s gave %v, want %s", tt.days, tt.d, tt.from, got.In(london), tt.want)
+		}
+	}
+
+	between, err := cs.BusinessTimeBetween(ctx, &BusinessTimeBetweenRequest{
+		Project: "uk",
+		From:    timestamppb.New(at("2026-12-24 09:00")),
+		To:      timestamppb.New(at("2026-12-29 13:00")),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if between.Working.AsDuration() != 20*time.Hour || between.Days != 2 {
+		t.Errorf("between is %v over %d days, want 20h over 2", between.Working.AsDuration(), between.Days)
+	}
+
+	// Huge counts are refused rather than stepped through.
+	for _, r := range []*AddBusinessTimeRequest{
+		{From: timestamppb.New(at("2026-12-24 09:00")), Days: math.MaxInt32},
+		{From: timestamppb.New(at("2026-12-24 09:00")), Days: -1},
+		{From: timestamppb.New(at("2026-12-24 09:00")), Duration: durationpb.New(math.MaxInt64)},
+	} {
+		if _, err := cs.AddBusinessTime(ctx, r); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("adding %d days and %v returned %v", r.Days, r.Duration.AsDuration(), err)
+		}
+	}
+	_, err = cs.BusinessTimeBetween(ctx, &BusinessTimeBetweenRequest{
+		From: timestamppb.New(time.Unix(0, 0)),
+		To:   timestamppb.New(at("2026-12-24 09:00")),
+	})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("working time over 50 years returned %v", err)
+	}
+	if _, err := ts.Create(ctx, &TaskRequest{Title: "later", DueInBusinessDays: math.MaxInt32}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("creating a task due in %d business days returned %v", math.MaxInt32, err)
+	}
+}
+
+// A repeating task which is done comes back due on the next working day
+// the calendar allows.
+func TestRepeat(t *testing.T) {
+	ctx := context.Background()
+	ts, err := NewTaskService(NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2026, 12, 23, 12, 0, 0, 0, time.UTC)
+	ts.now = func() time.Time { return now }
+	_, err = NewCalendarService(ts).SetCalendar(ctx, &Calendar{
+		Holidays: []*Holiday{{Date: "2026-12-25", Name: "Christmas"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = ts.Create(ctx, &TaskRequest{
+		Title:                   "stand-up notes",
+		Project:                 "p",
+		DueAt:                   timestamppb.New(time.Date(2026, 12, 24, 17, 0, 0, 0, time.UTC)),
+		RepeatEveryBusinessDays: 1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ts.AddChecklistItem(ctx, &AddChecklistItemRequest{TaskId: 1, Text: "write"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ts.ToggleChecklistItem(ctx, &ToggleChecklistItemRequest{TaskId: 1, ItemId: 1, Done: true}); err != nil {
+		t.Fatal(err)
+	}
+	setStatus := func(id int64, st Status) {
+		_, err := ts.Update(ctx, &UpdateRequest{
+			Id:         id,
+			Task:       &Task{Status: st},
+			UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"status"}},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	setStatus(1, Status_DONE)
+
+	next, err := ts.Get(ctx, &GetRequest{Id: 2})
+	if err != nil {
+		t.Fatalf("getting the next occurrence: %v", err)
+	}
+	if want := time.Date(2026, 12, 28, 17, 0, 0, 0, time.UTC); !next.DueAt.AsTime().Equal(want) {
+		t.Errorf("next occurrence is due %v, want %v", next.DueAt.AsTime(), want)
+	}
+	if next.Title != "stand-up notes" || next.Project != "p" || next.RepeatEveryBusinessDays != 1 || next.Status != Status_TODO {
+		t.Errorf("next occurrence is %v", next)
+	}
+	if len(next.Checklist) != 1 || next.Checklist[0].Done || next.Checklist[0].Id == 1 {
+		t.Errorf("next occurrence's checklist is %v, want one new unticked item", next.Checklist)
+	}
+
+	// Cancelling ends the series.
+	setStatus(2, Status_CANCELLED)
+	if _, err := ts.Get(ctx, &GetRequest{Id: 3}); status.Code(err) != codes.NotFound {
+		t.Errorf("cancelling a repeating task created another: %v", err)
+	}
+	_, err = ts.Create(ctx, &TaskRequest{Title: "often", RepeatEveryBusinessDays: -1})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("repeating every -1 days returned %v", err)
+	}
+}
+
+func TestParseICS(t *testing.T) {
+	ics := "BEGIN:VCALENDAR\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"DTSTART;VALUE=DATE:20261225\r\n" +
+		"DTEND;VALUE=DATE:20261227\r\n" +
+		"SUMMARY:Christmas\\, and\r\n" +
+		"  Boxing Day\r\n" +
+		"END:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"DTSTART:20260504T090000Z\r\n" +
+		"DTEND:20260504T170000Z\r\n" +
+		"SUMMARY:Bank holiday\r\n" +
+		"END:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"DTSTART;VALUE=DATE:20260101\r\n" +
+		"END:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"
+	got, err := parseICS([]byte(ics))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*Holiday{
+		{Date: "2026-12-25", Name: "Christmas, and Boxing Day"},
+		{Date: "2026-12-26", Name: "Christmas, and Boxing Day"},
+		{Date: "2026-05-04", Name: "Bank holiday"},
+		{Date: "2026-01-01"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d holidays, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i].Date != want[i].Date || got[i].Name != want[i].Name {
+			t.Errorf("holiday %d is %v, want %v", i, got[i], want[i])
+		}
+	}
+
+	long, err := parseICS([]byte("BEGIN:VEVENT\nDTSTART:20260101\nDTEND:20360101\nEND:VEVENT\n"))
+	if err != nil || len(long) != maxEventDays {
+		t.Errorf("a ten year event gave %d holidays, %v; want %d", len(long), err, maxEventDays)
+	}
+
+	for _, bad := range []string{
+		"BEGIN:VEVENT\nSUMMARY:no start\nEND:VEVENT\n",
+		"BEGIN:VEVENT\nDTSTART:2026-01-01\nEND:VEVENT\n",
+		"BEGIN:VEVENT\nDTSTART:20260101\n",
+		"END:VEVENT\n",
+	} {
+		if _, err := parseICS([]byte(bad)); err == nil {
+			t.Errorf("parsing %q succeeded", bad)
+		}
+	}
+}
+
+func TestUnfoldICS(t *testing.T) {
+	got := unfoldICS([]byte("A:one\r\n two\r\n\tthree\r\nB:four\n"))
+	want := []string{"A:onetwothree", "B:four"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("unfolded to %q, want %q", got, want)
+	}
+}
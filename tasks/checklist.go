@@ -0,0 +1,129 @@
+package tasks
+
+import (
+	"context"
+	"strings"
+
+	"github.com/teamwork/log"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// Checklist items are addressed by id rather than position, so concurrent
+// changes to the same checklist can't land on the wrong item.
+
+func (s *TaskService) AddChecklistItem(c context.Context, r *AddChecklistItemRequest) (*Task, error) {
+	if strings.TrimSpace(r.Text) == "" {
+		return nil, status.Error(codes.InvalidArgument, "checklist items need text")
+	}
+
+	return s.editChecklist(r.TaskId, func(t *Task) error {
+		pos := len(t.Checklist)
+		if r.AfterId != 0 {
+			i := checklistIndex(t, r.AfterId)
+			if i < 0 {
+				return status.Errorf(codes.NotFound, "checklist item %d not found", r.AfterId)
+			}
+			pos = i + 1
+		}
+
+		s.nextChecklistID++
+		item := &ChecklistItem{Id: s.nextChecklistID, Text: r.Text}
+		t.Checklist = append(t.Checklist, nil)
+		copy(t.Checklist[pos+1:], t.Checklist[pos:])
+		t.Checklist[pos] = item
+		return nil
+	})
+}
+
+func (s *TaskService) ToggleChecklistItem(c context.Context, r *ToggleChecklistItemRequest) (*Task, error) {
+	return s.editChecklist(r.TaskId, func(t *Task) error {
+		i := checklistIndex(t, r.ItemId)
+		if i < 0 {
+			return status.Errorf(codes.NotFound, "checklist item %d not found", r.ItemId)
+		}
+		t.Checklist[i].Done = r.Done
+		return nil
+	})
+}
+
+func (s *TaskService) ReorderChecklist(c context.Context, r *ReorderChecklistRequest) (*Task, error) {
+	return s.editChecklist(r.TaskId, func(t *Task) error {
+		if len(r.ItemIds) != len(t.Checklist) {
+			return status.Error(codes.Aborted, "checklist has changed, reload it and try again")
+		}
+		items := make(map[int64]*ChecklistItem, len(t.Checklist))
+		for _, item := range t.Checklist {
+			items[item.Id] = item
+		}
+		ordered := make([]*ChecklistItem, 0, len(r.ItemIds))
+		for _, id := range r.ItemIds {
+			item, ok := items[id]
+			if !ok {
+				return status.Error(codes.Aborted, "checklist has changed, reload it and try again")
+			}
+			delete(items, id)
+			ordered = append(ordered, item)
+		}
+		t.Checklist = ordered
+		return nil
+	})
+}
+
+func (s *TaskService) RemoveChecklistItem(c context.Context, r *RemoveChecklistItemRequest) (*Task, error) {
+	return s.editChecklist(r.TaskId, func(t *Task) error {
+		i := checklistIndex(t, r.ItemId)
+		if i < 0 {
+			return status.Errorf(codes.NotFound, "checklist item %d not found", r.ItemId)
+		}
+		t.Checklist = append(t.Checklist[:i], t.Checklist[i+1:]...)
+		return nil
+	})
+}
+
+// editChecklist runs fn on the task with the task lock held. fn works on a
+// copy, so nothing changes if it returns an error.
+func (s *TaskService) editChecklist(id int64, fn func(t *Task) error) (_ *Task, err error) {
+	s.mu.Lock()
+	defer s.unlock(&err)
+
+	t, ok := s.tasks[id]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "task %d not found", id)
+	}
+	next := proto.Clone(t).(*Task)
+	if err := fn(next); err != nil {
+		return nil, err
+	}
+
+	old := proto.Clone(t).(*Task)
+	t.Checklist = next.Checklist
+	s.changedLocked(old, t)
+
+	log.Infof("Updated checklist on task %d", id)
+	return proto.Clone(t).(*Task), nil
+}
+
+func checklistIndex(t *Task, id int64) int {
+	for i, item := range t.Checklist {
+		if item.Id == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// checklistProgress is the percentage of t's checklist which is done.
+func checklistProgress(t *Task) int32 {
+	if len(t.Checklist) == 0 {
+		return 0
+	}
+	done := 0
+	for _, item := range t.Checklist {
+		if item.Done {
+			done++
+		}
+	}
+	return int32(done * 100 / len(t.Checklist))
+}
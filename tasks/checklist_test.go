@@ -0,0 +1,103 @@
+package tasks
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Checklist edits address items by id, so concurrent edits all land, and
+// the task's progress follows the ticked items.
+func TestChecklistProgress(t *testing.T) {
+	ctx := context.Background()
+	ts, err := NewTaskService(NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ts.Create(ctx, &TaskRequest{Title: "launch"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := ts.AddChecklistItem(ctx, &AddChecklistItemRequest{TaskId: 1, Text: "step"}); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	task, err := ts.Get(ctx, &GetRequest{Id: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []int64
+	for _, item := range task.Checklist {
+		ids = append(ids, item.Id)
+	}
+	if len(ids) != 8 {
+		t.Fatalf("concurrent adds left %d items", len(ids))
+	}
+
+	// Tick half the items at once.
+	for _, id := range ids[:4] {
+		wg.Add(1)
+		go func(id int64) {
+			defer wg.Done()
+			if _, err := ts.ToggleChecklistItem(ctx, &ToggleChecklistItemRequest{TaskId: 1, ItemId: id, Done: true}); err != nil {
+				t.Error(err)
+			}
+		}(id)
+	}
+	wg.Wait()
+	if task, _ = ts.Get(ctx, &GetRequest{Id: 1}); task.Progress != 50 {
+		t.Errorf("progress with 4 of 8 items done is %d", task.Progress)
+	}
+
+	task, err = ts.AddChecklistItem(ctx, &AddChecklistItemRequest{TaskId: 1, Text: "second", AfterId: ids[0]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task.Checklist[1].Text != "second" || task.Progress != 44 {
+		t.Errorf("after inserting an item the checklist is %v at %d%%", task.Checklist, task.Progress)
+	}
+	inserted := task.Checklist[1].Id
+
+	// A reorder made from a stale checklist is refused.
+	_, err = ts.ReorderChecklist(ctx, &ReorderChecklistRequest{TaskId: 1, ItemIds: ids})
+	if status.Code(err) != codes.Aborted {
+		t.Errorf("reordering a stale checklist returned %v", err)
+	}
+	reversed := []int64{inserted}
+	for i := len(ids) - 1; i >= 0; i-- {
+		reversed = append(reversed, ids[i])
+	}
+	if task, err = ts.ReorderChecklist(ctx, &ReorderChecklistRequest{TaskId: 1, ItemIds: reversed}); err != nil {
+		t.Fatal(err)
+	}
+	var got []int64
+	for _, item := range task.Checklist {
+		got = append(got, item.Id)
+	}
+	if !reflect.DeepEqual(got, reversed) {
+		t.Errorf("reordered checklist is %v, want %v", got, reversed)
+	}
+
+	for _, id := range ids[4:] {
+		if _, err := ts.RemoveChecklistItem(ctx, &RemoveChecklistItemRequest{TaskId: 1, ItemId: id}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := ts.RemoveChecklistItem(ctx, &RemoveChecklistItemRequest{TaskId: 1, ItemId: ids[4]}); status.Code(err) != codes.NotFound {
+		t.Errorf("removing a removed item returned %v", err)
+	}
+	if task, _ = ts.Get(ctx, &GetRequest{Id: 1}); len(task.Checklist) != 5 || task.Progress != 80 {
+		t.Errorf("after removing the unticked items the checklist is %v at %d%%", task.Checklist, task.Progress)
+	}
+}
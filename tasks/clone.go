@@ -0,0 +1,112 @@
+package tasks
+
+import (
+	"context"
+
+	"github.com/teamwork/log"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// Clone copies a task, and with IncludeSubtasks everything under it. Every
+// copy is checked before any are stored, so either the whole subtree is
+// cloned or nothing is.
+func (s *TaskService) Clone(c context.Context, r *CloneRequest) (_ *CloneResponse, err error) {
+	s.mu.Lock()
+	defer s.unlock(&err)
+
+	root, ok := s.tasks[r.TaskId]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "task %d not found", r.TaskId)
+	}
+	project := r.Project
+	if project == "" {
+		project = root.Project
+	}
+	parent := r.ParentId
+	if parent == 0 && project == root.Project {
+		parent = root.ParentId
+	}
+	if parent != 0 && s.tasks[parent] == nil {
+		return nil, status.Errorf(codes.NotFound, "parent task %d not found", parent)
+	}
+
+	originals := []*Task{root}
+	if r.IncludeSubtasks {
+		originals = s.subtreeLocked(root.Id)
+	}
+
+	// The copies are inserted in order, so their ids are known up front.
+	idMap := make(map[int64]int64, len(originals))
+	for i, t := range originals {
+		idMap[t.Id] = s.nextID + int64(i) + 1
+	}
+
+	copies := make([]*Task, 0, len(originals))
+	for _, t := range originals {
+		cp := &Task{
+			Title:             t.Title,
+			Description:       t.Description,
+			DescriptionFormat: t.DescriptionFormat,
+			ParentId:          idMap[t.ParentId],
+			Project:           project,
+			Priority:          t.Priority,
+			Assignee:          t.Assignee,
+		}
+		if t.Id == root.Id {
+			cp.ParentId = parent
+		}
+		if r.IncludeLabels {
+			cp.Labels = append([]string(nil), t.Labels...)
+		}
+		if project == t.Project {
+			for name, v := range t.CustomFields {
+				if cp.CustomFields == nil {
+					cp.CustomFields = make(map[string]*CustomValue)
+				}
+				cp.CustomFields[name] = proto.Clone(v).(*CustomValue)
+			}
+		}
+		for _, id := range t.DependsOn {
+			if copied, ok := idMap[id]; ok {
+				id = copied
+			}
+			cp.DependsOn = append(cp.DependsOn, id)
+		}
+		if err := s.validateCustomFieldsLocked(cp); err != nil {
+			return nil, err
+		}
+		copies = append(copies, cp)
+	}
+
+	resp := &CloneResponse{IdMap: idMap}
+	for i, cp := range copies {
+		if r.IncludeChecklist {
+			for _, item := range originals[i].Checklist {
+				s.nextChecklistID++
+				cp.Checklist = append(cp.Checklist, &ChecklistItem{
+					Id:   s.nextChecklistID,
+					Text: item.Text,
+				})
+			}
+		}
+		if r.IncludeAttachments {
+			cp.Attachments = s.copyAttachmentsLocked(originals[i])
+		}
+		resp.Ids = append(resp.Ids, s.insertLocked(cp))
+	}
+
+	log.Infof("Cloned task %d into %d new tasks", root.Id, len(resp.Ids))
+	return resp, nil
+}
+
+// subtreeLocked returns id and all of its subtasks, parents before their
+// children. s.mu must be held.
+func (s *TaskService) subtreeLocked(id int64) []*Task {
+	tree := []*Task{s.tasks[id]}
+	for _, child := range s.childrenLocked(id) {
+		tree = append(tree, s.subtreeLocked(child.Id)...)
+	}
+	return tree
+}
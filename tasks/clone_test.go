@@ -0,0 +1,92 @@
+package tasks
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Cloning a subtree copies its shape, and dependencies inside it point at
+// the copies while those outside it are kept.
+func TestCloneSubtree(t *testing.T) {
+	ctx := context.Background()
+	ts, err := NewTaskService(NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range []*TaskRequest{
+		{Title: "root", Project: "p", Labels: []string{"x"}},
+		{Title: "child", Project: "p", ParentId: 1},
+		{Title: "grandchild", Project: "p", ParentId: 2},
+		{Title: "outside", Project: "p"},
+	} {
+		if _, err := ts.Create(ctx, r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, l := range []*LinkRequest{{TaskId: 3, DependsOn: 2}, {TaskId: 3, DependsOn: 4}} {
+		if _, err := ts.Link(ctx, l); err != nil {
+			t.Fatal(err)
+		}
+	}
+	item, err := ts.AddChecklistItem(ctx, &AddChecklistItemRequest{TaskId: 2, Text: "check"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ts.ToggleChecklistItem(ctx, &ToggleChecklistItemRequest{TaskId: 2, ItemId: item.Checklist[0].Id, Done: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = ts.Clone(ctx, &CloneRequest{TaskId: 1, IncludeSubtasks: true, ParentId: 99})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("cloning under a missing parent returned %v", err)
+	}
+	resp, err := ts.Clone(ctx, &CloneRequest{
+		TaskId:           1,
+		IncludeSubtasks:  true,
+		IncludeChecklist: true,
+		Project:          "q",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int64{5, 6, 7}; !reflect.DeepEqual(resp.Ids, want) {
+		t.Fatalf("cloned into %v, want %v", resp.Ids, want)
+	}
+	if want := map[int64]int64{1: 5, 2: 6, 3: 7}; !reflect.DeepEqual(resp.IdMap, want) {
+		t.Errorf("id map is %v, want %v", resp.IdMap, want)
+	}
+
+	for _, want := range []*Task{
+		{Id: 5, Title: "root"},
+		{Id: 6, Title: "child", ParentId: 5},
+		{Id: 7, Title: "grandchild", ParentId: 6, DependsOn: []int64{6, 4}},
+	} {
+		got, err := ts.Get(ctx, &GetRequest{Id: want.Id})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Title != want.Title || got.ParentId != want.ParentId || got.Project != "q" || !reflect.DeepEqual(got.DependsOn, want.DependsOn) {
+			t.Errorf("task %d is %q in %s under %d depending on %v, want %q in q under %d depending on %v", want.Id,
+				got.Title, got.Project, got.ParentId, got.DependsOn, want.Title, want.ParentId, want.DependsOn)
+		}
+		if len(got.Labels) != 0 {
+			t.Errorf("task %d was cloned with labels %v", got.Id, got.Labels)
+		}
+		if got.Id == 6 && (len(got.Checklist) != 1 || got.Checklist[0].Done || got.Checklist[0].Id == item.Checklist[0].Id) {
+			t.Errorf("cloned checklist is %v", got.Checklist)
+		}
+	}
+
+	// Without subtasks only the task itself is copied, under the same parent.
+	resp, err = ts.Clone(ctx, &CloneRequest{TaskId: 2, IncludeLabels: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := ts.Get(ctx, &GetRequest{Id: 8}); len(resp.Ids) != 1 || got.ParentId != 1 || got.Project != "p" || len(got.Checklist) != 0 {
+		t.Errorf("cloning task 2 alone created %v: %v", resp.Ids, got)
+	}
+}
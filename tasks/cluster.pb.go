@@ -0,0 +1,203 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.27.1
+// 	protoc        (unknown)
+// source: tasks/cluster.proto
+
+package tasks
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// RaftMessages holds raft messages from one server to another, each
+// encoded as a raftpb.Message.
+type RaftMessages struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Messages [][]byte `protobuf:"bytes,1,rep,name=messages,proto3" json:"messages,omitempty"`
+}
+
+func (x *RaftMessages) Reset() {
+	*x = RaftMessages{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_tasks_cluster_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RaftMessages) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RaftMessages) ProtoMessage() {}
+
+func (x *RaftMessages) ProtoReflect() protoreflect.Message {
+	mi := &file_tasks_cluster_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RaftMessages.ProtoReflect.Descriptor instead.
+func (*RaftMessages) Descriptor() ([]byte, []int) {
+	return file_tasks_cluster_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *RaftMessages) GetMessages() [][]byte {
+	if x != nil {
+		return x.Messages
+	}
+	return nil
+}
+
+type RaftAck struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *RaftAck) Reset() {
+	*x = RaftAck{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_tasks_cluster_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RaftAck) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RaftAck) ProtoMessage() {}
+
+func (x *RaftAck) ProtoReflect() protoreflect.Message {
+	mi := &file_tasks_cluster_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RaftAck.ProtoReflect.Descriptor instead.
+func (*RaftAck) Descriptor() ([]byte, []int) {
+	return file_tasks_cluster_proto_rawDescGZIP(), []int{1}
+}
+
+var File_tasks_cluster_proto protoreflect.FileDescriptor
+
+var file_tasks_cluster_proto_rawDesc = []byte{
+	0x0a, 0x13, 0x74, 0x61, 0x73, 0x6b, 0x73, 0x2f, 0x63, 0x6c, 0x75, 0x73, 0x74, 0x65, 0x72, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x04, 0x74, 0x61, 0x73, 0x6b, 0x22, 0x2a, 0x0a, 0x0c, 0x52,
+	0x61, 0x66, 0x74, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x6d,
+	0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0c, 0x52, 0x08, 0x6d,
+	0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x22, 0x09, 0x0a, 0x07, 0x52, 0x61, 0x66, 0x74, 0x41,
+	0x63, 0x6b, 0x32, 0x33, 0x0a, 0x04, 0x52, 0x61, 0x66, 0x74, 0x12, 0x2b, 0x0a, 0x04, 0x53, 0x65,
+	0x6e, 0x64, 0x12, 0x12, 0x2e, 0x74, 0x61, 0x73, 0x6b, 0x2e, 0x52, 0x61, 0x66, 0x74, 0x4d, 0x65,
+	0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x1a, 0x0d, 0x2e, 0x74, 0x61, 0x73, 0x6b, 0x2e, 0x52, 0x61,
+	0x66, 0x74, 0x41, 0x63, 0x6b, 0x22, 0x00, 0x42, 0x2a, 0x5a, 0x28, 0x67, 0x69, 0x74, 0x68, 0x75,
+	0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x61, 0x6e, 0x64, 0x79, 0x61, 0x6e, 0x74, 0x72, 0x69, 0x6d,
+	0x2f, 0x67, 0x72, 0x70, 0x63, 0x5f, 0x65, 0x78, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x2f, 0x74, 0x61,
+	0x73, 0x6b, 0x73, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_tasks_cluster_proto_rawDescOnce sync.Once
+	file_tasks_cluster_proto_rawDescData = file_tasks_cluster_proto_rawDesc
+)
+
+func file_tasks_cluster_proto_rawDescGZIP() []byte {
+	file_tasks_cluster_proto_rawDescOnce.Do(func() {
+		file_tasks_cluster_proto_rawDescData = protoimpl.X.CompressGZIP(file_tasks_cluster_proto_rawDescData)
+	})
+	return file_tasks_cluster_proto_rawDescData
+}
+
+var file_tasks_cluster_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_tasks_cluster_proto_goTypes = []interface{}{
+	(*RaftMessages)(nil), // 0: task.RaftMessages
+	(*RaftAck)(nil),      // 1: task.RaftAck
+}
+var file_tasks_cluster_proto_depIdxs = []int32{
+	0, // 0: task.Raft.Send:input_type -> task.RaftMessages
+	1, // 1: task.Raft.Send:output_type -> task.RaftAck
+	1, // [1:2] is the sub-list for method output_type
+	0, // [0:1] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_tasks_cluster_proto_init() }
+func file_tasks_cluster_proto_init() {
+	if File_tasks_cluster_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_tasks_cluster_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RaftMessages); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_tasks_cluster_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RaftAck); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_tasks_cluster_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_tasks_cluster_proto_goTypes,
+		DependencyIndexes: file_tasks_cluster_proto_depIdxs,
+		MessageInfos:      file_tasks_cluster_proto_msgTypes,
+	}.Build()
+	File_tasks_cluster_proto = out.File
+	file_tasks_cluster_proto_rawDesc = nil
+	file_tasks_cluster_proto_goTypes = nil
+	file_tasks_cluster_proto_depIdxs = nil
+}
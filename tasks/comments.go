@@ -0,0 +1,40 @@
+package tasks
+
+import (
+	"context"
+	"strings"
+
+	"github.com/teamwork/log"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (s *TaskService) AddComment(c context.Context, r *AddCommentRequest) (_ *Comment, err error) {
+	if r.Author == "" || strings.TrimSpace(r.Body) == "" {
+		return nil, status.Error(codes.InvalidArgument, "comments need an author and a body")
+	}
+
+	s.mu.Lock()
+	defer s.unlock(&err)
+
+	t, ok := s.tasks[r.TaskId]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "task %d not found", r.TaskId)
+	}
+
+	s.nextCommentID++
+	comment := &Comment{
+		Id:        s.nextCommentID,
+		Author:    r.Author,
+		Body:      r.Body,
+		CreatedAt: timestamppb.New(s.now()),
+	}
+	old := proto.Clone(t).(*Task)
+	t.Comments = append(t.Comments, comment)
+	s.changedLocked(old, t)
+
+	log.Infof("%s commented on task %d", r.Author, t.Id)
+	return proto.Clone(comment).(*Comment), nil
+}
@@ -0,0 +1,701 @@
+// Package cryptstore encrypts task content at rest. It wraps another
+// tasks.Store, sealing each task's title, description and rendered HTML,
+// all attachment content, the events waiting in the outbox and the meta
+// values kept alongside the tasks, such as notifications and templates,
+// with AES-GCM before it reaches the store.
+//
+// Encryption is by envelope: every task, attachment and meta value gets a
+// random key of its own, which is stored encrypted under a version of the server's key
+// from a Keyring. Moving to a new version of the server's key only means
+// re-encrypting those small keys, which Run does in the background.
+//
+// Content which fails to decrypt has been tampered with, and reading it
+// returns an error wrapping tasks.ErrCorrupt, bar tasks read with List,
+// which are left sealed so the TaskService can still start.
+package cryptstore
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/andyantrim/grpc-example/tasks"
+	"github.com/teamwork/log"
+	"google.golang.org/protobuf/proto"
+)
+
+// blobMagic starts every encrypted blob and outbox entry, followed by a
+// tasks.Sealed.
+var blobMagic = []byte("TASKENC1")
+
+// rotateBatch is how many tasks Rotate moves to the primary key in each
+// transaction, so the server's own writes get in between.
+const rotateBatch = 100
+
+// Store encrypts what it passes on to another Store.
+type Store struct {
+	inner tasks.Store
+	keys  *Keyring
+}
+
+// Wrap returns a store encrypting what it saves in inner with keys. Tasks
+// and attachments already in inner unencrypted can still be read, and are
+// encrypted by Rotate.
+func Wrap(inner tasks.Store, keys *Keyring) *Store {
+	return &Store{inner: inner, keys: keys}
+}
+
+func (s *Store) Begin(writable bool) (tasks.Tx, error) {
+	tx, err := s.inner.Begin(writable)
+	if err != nil {
+		return nil, err
+	}
+	return &Tx{inner: tx, keys: s.keys}, nil
+}
+
+func (s *Store) Close() error {
+	return s.inner.Close()
+}
+
+// Tx decrypts what it reads and encrypts what it writes.
+type Tx struct {
+	inner tasks.Tx
+	keys  *Keyring
+}
+
+func (t *Tx) Get(id int64) (*tasks.Task, error) {
+	task, err := t.inner.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	return task, t.keys.open(task)
+}
+
+func (t *Tx) List(after int64, limit int) ([]*tasks.Task, error) {
+	list, err := t.inner.List(after, limit)
+	if err != nil {
+		return nil, err
+	}
+	for _, task := range list {
+		// A task which fails to open is left sealed, as the Tx interface
+		// asks.
+		if err := t.keys.open(task); err != nil && !errors.Is(err, tasks.ErrCorrupt) {
+			return nil, err
+		}
+	}
+	return list, nil
+}
+
+func (t *Tx) Put(task *tasks.Task) error {
+	sealed, err := t.keys.seal(task)
+	if err != nil {
+		return err
+	}
+	return t.inner.Put(sealed)
+}
+
+func (t *Tx) Delete(id int64) error {
+	return t.inner.Delete(id)
+}
+
+func (t *Tx) GetBlob(key string) ([]byte, error) {
+	b, err := t.inner.GetBlob(key)
+	if err != nil {
+		return nil, err
+	}
+	return t.keys.openBlob(key, b)
+}
+
+func (t *Tx) PutBlob(key string, data []byte) error {
+	b, err := t.keys.sealBlob(key, data)
+	if err != nil {
+		return err
+	}
+	return t.inner.PutBlob(key, b)
+}
+
+func (t *Tx) GetMeta(key string) ([]byte, error) {
+	b, err := t.inner.GetMeta(key)
+	if err != nil {
+		return nil, err
+	}
+	return t.keys.openMeta(key, b)
+}
+
+func (t *Tx) PutMeta(key string, value []byte) error {
+	b, err := t.keys.sealMeta(key, value)
+	if err != nil {
+		return err
+	}
+	return t.inner.PutMeta(key, b)
+}
+
+func (t *Tx) ListMeta() ([]string, error) {
+	return t.inner.ListMeta()
+}
+
+func (t *Tx) PutOutbox(seq int64, data []byte) error {
+	b, err := t.keys.sealOutbox(seq, data)
+	if err != nil {
+		return err
+	}
+	return t.inner.PutOutbox(seq, b)
+}
+
+func (t *Tx) ListOutbox(after int64, limit int) ([]tasks.OutboxEntry, error) {
+	list, err := t.inner.ListOutbox(after, limit)
+	if err != nil {
+		return nil, err
+	}
+	for i, e := range list {
+		if list[i].Data, err = t.keys.openOutbox(e.Seq, e.Data); err != nil {
+			return nil, err
+		}
+	}
+	return list, nil
+}
+
+func (t *Tx) TrimOutbox(upTo int64) error {
+	return t.inner.TrimOutbox(upTo)
+}
+
+func (t *Tx) Commit() error {
+	return t.inner.Commit()
+}
+
+func (t *Tx) Rollback() error {
+	return t.inner.Rollback()
+}
+
+// seal returns a copy of task with its content encrypted.
+func (k *Keyring) seal(task *tasks.Task) (*tasks.Task, error) {
+	content, err := proto.Marshal(&tasks.Task{
+		Title:        task.Title,
+		Description:  task.Description,
+		RenderedHtml: task.RenderedHtml,
+	})
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := k.encrypt(content, taskData(task.Id))
+	if err != nil {
+		return nil, err
+	}
+	task = proto.Clone(task).(*tasks.Task)
+	task.Title, task.Description, task.RenderedHtml = "", "", ""
+	task.Sealed = sealed
+	return task, nil
+}
+
+// open decrypts task's content in place. Tasks saved before encryption was
+// turned on are left alone.
+func (k *Keyring) open(task *tasks.Task) error {
+	if task.Sealed == nil {
+		return nil
+	}
+	content, err := k.decrypt(task.Sealed, taskData(task.Id))
+	if err != nil {
+		return fmt.Errorf("task %d: %w", task.Id, err)
+	}
+	var c tasks.Task
+	if err := proto.Unmarshal(content, &c); err != nil {
+		return fmt.Errorf("task %d: %w: %v", task.Id, tasks.ErrCorrupt, err)
+	}
+	task.Title, task.Description, task.RenderedHtml = c.Title, c.Description, c.RenderedHtml
+	task.Sealed = nil
+	return nil
+}
+
+func (k *Keyring) sealBlob(key string, data []byte) ([]byte, error) {
+	sealed, err := k.encrypt(data, blobData(key))
+	if err != nil {
+		return nil, err
+	}
+	return marshalSealed(sealed)
+}
+
+// openBlob decrypts b, the stored content under key. Content is keyed by
+// its SHA-256, which tells apart unencrypted content that happens to start
+// like encrypted content.
+func (k *Keyring) openBlob(key string, b []byte) ([]byte, error) {
+	sealed, ok := unmarshalBlob(b)
+	if !ok {
+		if !hashes(b, key) {
+			return nil, fmt.Errorf("attachment %s: %w: content doesn't match its hash", key, tasks.ErrCorrupt)
+		}
+		return b, nil
+	}
+	data, err := k.decrypt(sealed, blobData(key))
+	if err != nil {
+		if hashes(b, key) {
+			return b, nil
+		}
+		return nil, fmt.Errorf("attachment %s: %w", key, err)
+	}
+	return data, nil
+}
+
+func (k *Keyring) sealOutbox(seq int64, data []byte) ([]byte, error) {
+	sealed, err := k.encrypt(data, outboxData(seq))
+	if err != nil {
+		return nil, err
+	}
+	return marshalSealed(sealed)
+}
+
+// openOutbox decrypts b, the stored entry under seq. Entries are
+// protobuf, which can't start with blobMagic, so those saved before
+// encryption was turned on are told apart by the prefix alone.
+func (k *Keyring) openOutbox(seq int64, b []byte) ([]byte, error) {
+	if !bytes.HasPrefix(b, blobMagic) {
+		return b, nil
+	}
+	sealed, ok := unmarshalBlob(b)
+	if !ok {
+		return nil, fmt.Errorf("outbox entry %d: %w: can't decode it", seq, tasks.ErrCorrupt)
+	}
+	data, err := k.decrypt(sealed, outboxData(seq))
+	if err != nil {
+		return nil, fmt.Errorf("outbox entry %d: %w", seq, err)
+	}
+	return data, nil
+}
+
+func (k *Keyring) sealMeta(key string, value []byte) ([]byte, error) {
+	sealed, err := k.encrypt(value, metaData(key))
+	if err != nil {
+		return nil, err
+	}
+	return marshalSealed(sealed)
+}
+
+// openMeta decrypts b, the stored value under key. Meta values are
+// protobuf, JSON or numbers, none of which start with blobMagic, so as with
+// the outbox those saved before encryption was turned on are told apart by
+// the prefix.
+func (k *Keyring) openMeta(key string, b []byte) ([]byte, error) {
+	if !bytes.HasPrefix(b, blobMagic) {
+		return b, nil
+	}
+	sealed, ok := unmarshalBlob(b)
+	if !ok {
+		return nil, fmt.Errorf("meta %s: %w: can't decode it", key, tasks.ErrCorrupt)
+	}
+	value, err := k.decrypt(sealed, metaData(key))
+	if err != nil {
+		return nil, fmt.Errorf("meta %s: %w", key, err)
+	}
+	return value, nil
+}
+
+func marshalSealed(sealed *tasks.Sealed) ([]byte, error) {
+	b, err := proto.Marshal(sealed)
+	if err != nil {
+		return nil, err
+	}
+	return append(append([]byte{}, blobMagic...), b...), nil
+}
+
+func unmarshalBlob(b []byte) (*tasks.Sealed, bool) {
+	if !bytes.HasPrefix(b, blobMagic) {
+		return nil, false
+	}
+	sealed := &tasks.Sealed{}
+	if err := proto.Unmarshal(b[len(blobMagic):], sealed); err != nil {
+		return nil, false
+	}
+	return sealed, true
+}
+
+func hashes(b []byte, key string) bool {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]) == key
+}
+
+// taskData, blobData, outboxData and metaData tie content to where it's
+// stored, so sealed content moved from one task, attachment, outbox entry
+// or meta value to another doesn't decrypt.
+func taskData(id int64) []byte {
+	return []byte("task:" + strconv.FormatInt(id, 10))
+}
+
+func blobData(key string) []byte {
+	return []byte("blob:" + key)
+}
+
+func outboxData(seq int64) []byte {
+	return []byte("outbox:" + strconv.FormatInt(seq, 10))
+}
+
+func metaData(key string) []byte {
+	return []byte("meta:" + key)
+}
+
+// encrypt seals plaintext under a new content key, wrapped with the primary
+// key.
+func (k *Keyring) encrypt(plaintext, data []byte) (*tasks.Sealed, error) {
+	version, kek := k.Primary()
+	dek := make([]byte, keySize)
+	if _, err := io.ReadFull(rand.Reader, dek); err != nil {
+		return nil, err
+	}
+	ciphertext, err := seal(dek, plaintext, data)
+	if err != nil {
+		return nil, err
+	}
+	wrapped, err := seal(kek, dek, wrapData(version))
+	if err != nil {
+		return nil, err
+	}
+	return &tasks.Sealed{KeyVersion: version, WrappedKey: wrapped, Ciphertext: ciphertext}, nil
+}
+
+func (k *Keyring) decrypt(s *tasks.Sealed, data []byte) ([]byte, error) {
+	dek, err := k.unwrap(s)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := open(dek, s.Ciphertext, data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", tasks.ErrCorrupt, err)
+	}
+	return plaintext, nil
+}
+
+func (k *Keyring) unwrap(s *tasks.Sealed) ([]byte, error) {
+	kek, err := k.key(s.KeyVersion)
+	if err != nil {
+		return nil, err
+	}
+	dek, err := open(kek, s.WrappedKey, wrapData(s.KeyVersion))
+	if err != nil {
+		return nil, fmt.Errorf("%w: unwrapping its key: %v", tasks.ErrCorrupt, err)
+	}
+	return dek, nil
+}
+
+// rewrap moves s to the primary key, reporting whether it had to.
+func (k *Keyring) rewrap(s *tasks.Sealed) (bool, error) {
+	version, kek := k.Primary()
+	if s.KeyVersion == version {
+		return false, nil
+	}
+	dek, err := k.unwrap(s)
+	if err != nil {
+		return false, err
+	}
+	wrapped, err := seal(kek, dek, wrapData(version))
+	if err != nil {
+		return false, err
+	}
+	s.KeyVersion, s.WrappedKey = version, wrapped
+	return true, nil
+}
+
+func wrapData(version uint32) []byte {
+	return []byte("key:" + strconv.FormatUint(uint64(version), 10))
+}
+
+// seal encrypts plaintext with AES-GCM, putting the nonce first.
+func seal(key, plaintext, data []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize(), gcm.NonceSize()+len(plaintext)+gcm.Overhead())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plaintext, data), nil
+}
+
+func open(key, ciphertext, data []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(ciphertext) < gcm.NonceSize() {
+		return nil, errors.New("ciphertext is too short")
+	}
+	nonce, ciphertext := ciphertext[:gcm.NonceSize()], ciphertext[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, ciphertext, data)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// Run reloads the keyring every interval until ctx is cancelled, calling
+// Rotate when it starts and whenever the primary key changes. In a cluster
+// only the leader should run it, as Rotate writes, and the other servers
+// run the keyring's Watch instead.
+func (s *Store) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var rotated uint32
+	for {
+		if err := s.keys.Reload(); err != nil {
+			log.Error(err, "Failed to reload the keyring")
+		}
+		if primary, _ := s.keys.Primary(); primary != rotated {
+			err := s.Rotate(ctx)
+			var failed *RotateError
+			switch {
+			case errors.As(err, &failed):
+				// Trying again won't mend them, so they're only reported.
+				log.Error(err, "Left some items under older keys")
+				rotated = primary
+			case err != nil:
+				log.Error(err, "Failed to re-encrypt tasks")
+			default:
+				rotated = primary
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Rotate moves every task, the attachments they refer to and every meta
+// value to the primary key, encrypting any saved before encryption was
+// turned on. It works through the tasks in batches, each in its own
+// transaction, so the server carries on while it runs. Attachment content
+// no task refers to any more is left as it is.
+//
+// Events in the outbox are left as they are too, as an entry can't be
+// written again under the same seq. They're removed once every sink has
+// had them.
+//
+// A task or meta value which is corrupt, or whose key is missing, is
+// logged and left as it is while Rotate carries on with the rest. They're
+// then listed in the *RotateError it returns.
+func (s *Store) Rotate(ctx context.Context) error {
+	primary, _ := s.keys.Primary()
+	log.Infof("Re-encrypting tasks under key version %d", primary)
+
+	var rewrapped, encrypted int64
+	var failed RotateError
+	seen := make(map[string]bool)
+	for after := int64(0); ; {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		var n int
+		err := tasks.WriteTx(s.inner, func(tx tasks.Tx) error {
+			list, err := tx.List(after, rotateBatch)
+			if err != nil {
+				return err
+			}
+			n = len(list)
+			for _, task := range list {
+				after = task.Id
+				r, e, err := s.rotateTask(tx, task, seen)
+				if skip(err) {
+					log.Error(err, fmt.Sprintf("Failed to re-encrypt task %d", task.Id))
+					failed.Tasks = append(failed.Tasks, task.Id)
+					continue
+				}
+				if err != nil {
+					return fmt.Errorf("task %d: %w", task.Id, err)
+				}
+				rewrapped += r
+				encrypted += e
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if n < rotateBatch {
+			break
+		}
+	}
+
+	// There are only a few meta values, so they're moved together.
+	err := tasks.WriteTx(s.inner, func(tx tasks.Tx) error {
+		keys, err := tx.ListMeta()
+		if err != nil {
+			return err
+		}
+		for _, key := range keys {
+			r, e, err := s.rotateMeta(tx, key)
+			if skip(err) {
+				log.Error(err, fmt.Sprintf("Failed to re-encrypt meta %s", key))
+				failed.Meta = append(failed.Meta, key)
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			rewrapped += r
+			encrypted += e
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	var waiting int
+	err = tasks.ReadTx(s.inner, func(tx tasks.Tx) error {
+		list, err := tx.ListOutbox(0, 0)
+		if err != nil {
+			return err
+		}
+		for _, e := range list {
+			if sealed, ok := unmarshalBlob(e.Data); !ok || sealed.KeyVersion != primary {
+				waiting++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if waiting > 0 {
+		log.Infof("%d events in the outbox are under older keys or unencrypted until they're delivered", waiting)
+	}
+
+	log.Infof("Re-encryption finished: moved %d keys to version %d and encrypted %d items", rewrapped, primary, encrypted)
+	if len(failed.Tasks) > 0 || len(failed.Meta) > 0 {
+		return &failed
+	}
+	return nil
+}
+
+// RotateError lists what Rotate had to leave under an older key, as it was
+// corrupt or its key is missing from the keyring. Everything else was
+// moved.
+type RotateError struct {
+	Tasks []int64
+	Meta  []string
+}
+
+func (e *RotateError) Error() string {
+	return fmt.Sprintf("couldn't re-encrypt tasks %v and meta values %q", e.Tasks, e.Meta)
+}
+
+// skip reports whether Rotate should leave an item failing with err and
+// carry on, rather than stop.
+func skip(err error) bool {
+	return errors.Is(err, tasks.ErrCorrupt) || errors.Is(err, errNoKey)
+}
+
+// rotateTask moves task and its attachments to the primary key within tx,
+// which reads and writes the stored, encrypted forms. It returns how many
+// keys it rewrapped and how many items it encrypted for the first time.
+func (s *Store) rotateTask(tx tasks.Tx, task *tasks.Task, seen map[string]bool) (rewrapped, encrypted int64, err error) {
+	if task.Sealed == nil {
+		sealed, err := s.keys.seal(task)
+		if err != nil {
+			return 0, 0, err
+		}
+		if err := tx.Put(sealed); err != nil {
+			return 0, 0, err
+		}
+		encrypted++
+	} else {
+		moved, err := s.keys.rewrap(task.Sealed)
+		if err != nil {
+			return 0, 0, err
+		}
+		if moved {
+			if err := tx.Put(task); err != nil {
+				return 0, 0, err
+			}
+			rewrapped++
+		}
+	}
+
+	for _, a := range task.Attachments {
+		if seen[a.Blob] {
+			continue
+		}
+		seen[a.Blob] = true
+		b, err := tx.GetBlob(a.Blob)
+		if errors.Is(err, tasks.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return 0, 0, err
+		}
+		data, err := s.keys.openBlob(a.Blob, b)
+		if err != nil {
+			return 0, 0, err
+		}
+		sealed, ok := unmarshalBlob(b)
+		if !ok || bytes.Equal(data, b) {
+			// Stored unencrypted.
+			if b, err = s.keys.sealBlob(a.Blob, data); err != nil {
+				return 0, 0, err
+			}
+			encrypted++
+		} else {
+			moved, err := s.keys.rewrap(sealed)
+			if err != nil {
+				return 0, 0, err
+			}
+			if !moved {
+				continue
+			}
+			if b, err = marshalSealed(sealed); err != nil {
+				return 0, 0, err
+			}
+			rewrapped++
+		}
+		if err := tx.PutBlob(a.Blob, b); err != nil {
+			return 0, 0, err
+		}
+	}
+	return rewrapped, encrypted, nil
+}
+
+// rotateMeta moves the meta value under key to the primary key within tx,
+// reporting as rotateTask does.
+func (s *Store) rotateMeta(tx tasks.Tx, key string) (rewrapped, encrypted int64, err error) {
+	b, err := tx.GetMeta(key)
+	if err != nil {
+		return 0, 0, err
+	}
+	if !bytes.HasPrefix(b, blobMagic) {
+		if b, err = s.keys.sealMeta(key, b); err != nil {
+			return 0, 0, err
+		}
+		encrypted++
+	} else {
+		sealed, ok := unmarshalBlob(b)
+		if !ok {
+			return 0, 0, fmt.Errorf("meta %s: %w: can't decode it", key, tasks.ErrCorrupt)
+		}
+		moved, err := s.keys.rewrap(sealed)
+		if err != nil {
+			return 0, 0, fmt.Errorf("meta %s: %w", key, err)
+		}
+		if !moved {
+			return 0, 0, nil
+		}
+		if b, err = marshalSealed(sealed); err != nil {
+			return 0, 0, err
+		}
+		rewrapped++
+	}
+	return rewrapped, encrypted, tx.PutMeta(key, b)
+}
@@ -0,0 +1,307 @@
+package cryptstore
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/andyantrim/grpc-example/tasks"
+	"github.com/andyantrim/grpc-example/tasks/storetest"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+func testKeyring() *Keyring {
+	return &Keyring{
+		keys:    map[uint32][]byte{1: bytes.Repeat([]byte{1}, keySize)},
+		primary: 1,
+	}
+}
+
+func TestStore(t *testing.T) {
+	storetest.Run(t, storetest.Backend{
+		Open: func(t *testing.T) tasks.Store {
+			return Wrap(tasks.NewMemoryStore(), testKeyring())
+		},
+	})
+}
+
+// Rotate moves tasks to the new primary key, but leaves the outbox, whose
+// entries can't be written again.
+func TestRotate(t *testing.T) {
+	inner := tasks.NewMemoryStore()
+	keys := testKeyring()
+	s := Wrap(inner, keys)
+	err := tasks.WriteTx(s, func(tx tasks.Tx) error {
+		if err := tx.Put(&tasks.Task{Id: 1, Title: "secret"}); err != nil {
+			return err
+		}
+		if err := tx.PutMeta("sealed", []byte("state")); err != nil {
+			return err
+		}
+		return tx.PutOutbox(1, []byte("event"))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Saved before encryption was turned on.
+	err = tasks.WriteTx(inner, func(tx tasks.Tx) error {
+		return tx.PutMeta("plain", []byte("{}"))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var before []tasks.OutboxEntry
+	err = tasks.ReadTx(inner, func(tx tasks.Tx) (err error) {
+		before, err = tx.ListOutbox(0, 0)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keys.keys[2] = bytes.Repeat([]byte{2}, keySize)
+	keys.primary = 2
+	if err := s.Rotate(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	err = tasks.ReadTx(inner, func(tx tasks.Tx) error {
+		task, err := tx.Get(1)
+		if err != nil {
+			return err
+		}
+		if task.Sealed.GetKeyVersion() != 2 {
+			t.Errorf("task is under key %d after rotating, want 2", task.Sealed.GetKeyVersion())
+		}
+		for _, key := range []string{"sealed", "plain"} {
+			b, err := tx.GetMeta(key)
+			if err != nil {
+				return err
+			}
+			if sealed, ok := unmarshalBlob(b); !ok || sealed.KeyVersion != 2 {
+				t.Errorf("meta %s is %q after rotating, want it under key 2", key, b)
+			}
+		}
+		after, err := tx.ListOutbox(0, 0)
+		if err != nil {
+			return err
+		}
+		if len(after) != 1 || !bytes.Equal(after[0].Data, before[0].Data) {
+			t.Errorf("outbox changed from %v to %v", before, after)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tasks.ReadTx(s, func(tx tasks.Tx) error {
+		task, err := tx.Get(1)
+		if err != nil {
+			return err
+		}
+		if task.Title != "secret" {
+			t.Errorf("task title is %q after rotating", task.Title)
+		}
+		for key, want := range map[string]string{"sealed": "state", "plain": "{}"} {
+			if b, err := tx.GetMeta(key); err != nil || string(b) != want {
+				t.Errorf("meta %s is %q, %v after rotating, want %q", key, b, err, want)
+			}
+		}
+		list, err := tx.ListOutbox(0, 0)
+		if err != nil {
+			return err
+		}
+		if len(list) != 1 || string(list[0].Data) != "event" {
+			t.Errorf("outbox after rotating is %v", list)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// A task whose key can't be unwrapped doesn't stop the ones after it from
+// moving to the new key.
+func TestRotateCorrupt(t *testing.T) {
+	inner := tasks.NewMemoryStore()
+	keys := testKeyring()
+	s := Wrap(inner, keys)
+	err := tasks.WriteTx(s, func(tx tasks.Tx) error {
+		for id := int64(1); id <= 3; id++ {
+			if err := tx.Put(&tasks.Task{Id: id, Title: "secret"}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tasks.WriteTx(inner, func(tx tasks.Tx) error {
+		task, err := tx.Get(2)
+		if err != nil {
+			return err
+		}
+		task.Sealed.WrappedKey[0] ^= 1
+		return tx.Put(task)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keys.keys[2] = bytes.Repeat([]byte{2}, keySize)
+	keys.primary = 2
+	err = s.Rotate(context.Background())
+	failed, ok := err.(*RotateError)
+	if !ok || len(failed.Tasks) != 1 || failed.Tasks[0] != 2 || len(failed.Meta) != 0 {
+		t.Fatalf("rotating returned %v, want task 2 reported", err)
+	}
+
+	err = tasks.ReadTx(inner, func(tx tasks.Tx) error {
+		for id, want := range map[int64]uint32{1: 2, 2: 1, 3: 2} {
+			task, err := tx.Get(id)
+			if err != nil {
+				return err
+			}
+			if v := task.Sealed.GetKeyVersion(); v != want {
+				t.Errorf("task %d is under key %d after rotating, want %d", id, v, want)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tasks.ReadTx(s, func(tx tasks.Tx) error {
+		_, err := tx.Get(3)
+		return err
+	})
+	if err != nil {
+		t.Errorf("reading a rotated task: %v", err)
+	}
+}
+
+// A task which has been tampered with is reported when it's asked for,
+// and doesn't stop the server starting.
+func TestCorruptTask(t *testing.T) {
+	ctx := context.Background()
+	inner := tasks.NewMemoryStore()
+	ts, err := tasks.NewTaskService(Wrap(inner, testKeyring()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, title := range []string{"fine", "tampered"} {
+		if _, err := ts.Create(ctx, &tasks.TaskRequest{Title: title, Project: title}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = tasks.WriteTx(inner, func(tx tasks.Tx) error {
+		task, err := tx.Get(2)
+		if err != nil {
+			return err
+		}
+		task.Sealed.Ciphertext[0] ^= 1
+		return tx.Put(task)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ts, err = tasks.NewTaskService(Wrap(inner, testKeyring()))
+	if err != nil {
+		t.Fatalf("starting with a corrupt task: %v", err)
+	}
+	if _, err := ts.Get(ctx, &tasks.GetRequest{Id: 1}); err != nil {
+		t.Errorf("getting a sound task: %v", err)
+	}
+	if _, err := ts.Get(ctx, &tasks.GetRequest{Id: 2}); status.Code(err) != codes.DataLoss {
+		t.Errorf("getting a corrupt task returned %v", err)
+	}
+	if _, err := ts.List(ctx, &tasks.ListRequest{}); status.Code(err) != codes.DataLoss {
+		t.Errorf("listing every task returned %v", err)
+	}
+	list, err := ts.List(ctx, &tasks.ListRequest{Filter: &tasks.TaskFilter{Project: "fine"}})
+	if err != nil || len(list.Tasks) != 1 {
+		t.Errorf("listing the sound task's project returned %v, %v", list, err)
+	}
+	created, err := ts.Create(ctx, &tasks.TaskRequest{Title: "new"})
+	if err != nil || created.Id != 3 {
+		t.Errorf("created %v, %v; the corrupt task's id mustn't be reused", created, err)
+	}
+}
+
+// Nothing the service saves about a task shows its content in the store
+// underneath.
+func TestNoPlaintext(t *testing.T) {
+	ctx := context.Background()
+	const secret = "launch codes"
+	inner := tasks.NewMemoryStore()
+	ts, err := tasks.NewTaskService(Wrap(inner, testKeyring()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	created, err := ts.Create(ctx, &tasks.TaskRequest{Title: secret, Description: secret, Assignee: "ann"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ts.AddAttachment(ctx, &tasks.AddAttachmentRequest{TaskId: created.Id, Name: "a.txt", Data: []byte(secret)}); err != nil {
+		t.Fatal(err)
+	}
+	templates := tasks.NewTemplateService(ts)
+	if _, err := templates.SaveTemplate(ctx, &tasks.SaveTemplateRequest{Name: "t", TaskId: created.Id}); err != nil {
+		t.Fatal(err)
+	}
+	inbox, err := tasks.NewNotificationService(ts).ListNotifications(ctx, &tasks.ListNotificationsRequest{User: "ann"})
+	if err != nil || len(inbox.Notifications) == 0 {
+		t.Fatalf("ann's inbox is %v, %v", inbox, err)
+	}
+
+	var raw [][]byte
+	err = tasks.ReadTx(inner, func(tx tasks.Tx) error {
+		list, err := tx.List(0, 0)
+		if err != nil {
+			return err
+		}
+		for _, task := range list {
+			b, err := proto.Marshal(task)
+			if err != nil {
+				return err
+			}
+			raw = append(raw, b)
+			for _, a := range task.Attachments {
+				b, err := tx.GetBlob(a.Blob)
+				if err != nil {
+					return err
+				}
+				raw = append(raw, b)
+			}
+		}
+		keys, err := tx.ListMeta()
+		if err != nil {
+			return err
+		}
+		for _, key := range keys {
+			b, err := tx.GetMeta(key)
+			if err != nil {
+				return err
+			}
+			raw = append(raw, b)
+		}
+		outbox, err := tx.ListOutbox(0, 0)
+		for _, e := range outbox {
+			raw = append(raw, e.Data)
+		}
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, b := range raw {
+		if bytes.Contains(b, []byte(secret)) {
+			t.Errorf("store holds %q", b)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package cryptstore
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/teamwork/log"
+)
+
+// keySize is the length of every key, for AES-256.
+const keySize = 32
+
+// Keyring holds the versions of the server's key, read from a JSON file
+// such as
+//
+//	{
+//		"primary": 2,
+//		"keys": [
+//			{"version": 1, "key": "<32 bytes in base64>"},
+//			{"version": 2, "key": "<32 bytes in base64>"}
+//		]
+//	}
+//
+// New content is encrypted under the primary version. Older versions are
+// kept to read what hasn't been moved to the primary yet, and can be
+// removed from the file once the store's Run has caught up and the events
+// saved before then have been delivered.
+type Keyring struct {
+	path string
+
+	mu      sync.RWMutex
+	keys    map[uint32][]byte
+	primary uint32
+}
+
+type keyringFile struct {
+	Primary uint32 `json:"primary"`
+	Keys    []struct {
+		Version uint32 `json:"version"`
+		Key     string `json:"key"`
+	} `json:"keys"`
+}
+
+// LoadKeyring reads the keyring file at path.
+func LoadKeyring(path string) (*Keyring, error) {
+	k := &Keyring{path: path}
+	if err := k.Reload(); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// Reload reads the keyring file again, keeping the keys already loaded if
+// it isn't valid.
+func (k *Keyring) Reload() error {
+	b, err := ioutil.ReadFile(k.path)
+	if err != nil {
+		return fmt.Errorf("reading keyring: %v", err)
+	}
+	if fi, err := os.Stat(k.path); err == nil && fi.Mode().Perm()&0077 != 0 {
+		log.Infof("Keyring %s can be read by other users, and should have mode 0600", k.path)
+	}
+
+	var f keyringFile
+	if err := json.Unmarshal(b, &f); err != nil {
+		return fmt.Errorf("reading keyring %s: %v", k.path, err)
+	}
+	keys := make(map[uint32][]byte, len(f.Keys))
+	for _, fk := range f.Keys {
+		if fk.Version == 0 {
+			return fmt.Errorf("keyring %s: key versions start at 1", k.path)
+		}
+		if _, ok := keys[fk.Version]; ok {
+			return fmt.Errorf("keyring %s: key version %d is listed twice", k.path, fk.Version)
+		}
+		key, err := base64.StdEncoding.DecodeString(fk.Key)
+		if err != nil || len(key) != keySize {
+			return fmt.Errorf("keyring %s: key version %d must be %d bytes in base64", k.path, fk.Version, keySize)
+		}
+		keys[fk.Version] = key
+	}
+	if _, ok := keys[f.Primary]; !ok {
+		return fmt.Errorf("keyring %s: primary key version %d isn't in the keyring", k.path, f.Primary)
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.primary != 0 && k.primary != f.Primary {
+		log.Infof("Keyring primary key changed from version %d to %d", k.primary, f.Primary)
+	}
+	k.keys = keys
+	k.primary = f.Primary
+	return nil
+}
+
+// Watch reloads the keyring every interval until ctx is cancelled.
+func (k *Keyring) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if err := k.Reload(); err != nil {
+			log.Error(err, "Failed to reload the keyring")
+		}
+	}
+}
+
+// Primary returns the version new content is encrypted under, and its key.
+func (k *Keyring) Primary() (uint32, []byte) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.primary, k.keys[k.primary]
+}
+
+// errNoKey is returned when content is under a key version which has been
+// removed from the keyring.
+var errNoKey = errors.New("cryptstore: key version isn't in the keyring")
+
+func (k *Keyring) key(version uint32) ([]byte, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	key, ok := k.keys[version]
+	if !ok {
+		return nil, fmt.Errorf("%w: version %d", errNoKey, version)
+	}
+	return key, nil
+}
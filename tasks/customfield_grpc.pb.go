@@ -0,0 +1,177 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             (unknown)
+// source: tasks/customfield.proto
+
+package tasks
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// CustomFieldsClient is the client API for CustomFields service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type CustomFieldsClient interface {
+	DefineField(ctx context.Context, in *CustomFieldDefinition, opts ...grpc.CallOption) (*CustomFieldDefinition, error)
+	ListFields(ctx context.Context, in *ListFieldsRequest, opts ...grpc.CallOption) (*ListFieldsResponse, error)
+	DeleteField(ctx context.Context, in *DeleteFieldRequest, opts ...grpc.CallOption) (*DeleteFieldResponse, error)
+}
+
+type customFieldsClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewCustomFieldsClient(cc grpc.ClientConnInterface) CustomFieldsClient {
+	return &customFieldsClient{cc}
+}
+
+func (c *customFieldsClient) DefineField(ctx context.Context, in *CustomFieldDefinition, opts ...grpc.CallOption) (*CustomFieldDefinition, error) {
+	out := new(CustomFieldDefinition)
+	err := c.cc.Invoke(ctx, "/task.CustomFields/DefineField", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *customFieldsClient) ListFields(ctx context.Context, in *ListFieldsRequest, opts ...grpc.CallOption) (*ListFieldsResponse, error) {
+	out := new(ListFieldsResponse)
+	err := c.cc.Invoke(ctx, "/task.CustomFields/ListFields", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *customFieldsClient) DeleteField(ctx context.Context, in *DeleteFieldRequest, opts ...grpc.CallOption) (*DeleteFieldResponse, error) {
+	out := new(DeleteFieldResponse)
+	err := c.cc.Invoke(ctx, "/task.CustomFields/DeleteField", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CustomFieldsServer is the server API for CustomFields service.
+// All implementations must embed UnimplementedCustomFieldsServer
+// for forward compatibility
+type CustomFieldsServer interface {
+	DefineField(context.Context, *CustomFieldDefinition) (*CustomFieldDefinition, error)
+	ListFields(context.Context, *ListFieldsRequest) (*ListFieldsResponse, error)
+	DeleteField(context.Context, *DeleteFieldRequest) (*DeleteFieldResponse, error)
+	mustEmbedUnimplementedCustomFieldsServer()
+}
+
+// UnimplementedCustomFieldsServer must be embedded to have forward compatible implementations.
+type UnimplementedCustomFieldsServer struct {
+}
+
+func (UnimplementedCustomFieldsServer) DefineField(context.Context, *CustomFieldDefinition) (*CustomFieldDefinition, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DefineField not implemented")
+}
+func (UnimplementedCustomFieldsServer) ListFields(context.Context, *ListFieldsRequest) (*ListFieldsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListFields not implemented")
+}
+func (UnimplementedCustomFieldsServer) DeleteField(context.Context, *DeleteFieldRequest) (*DeleteFieldResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteField not implemented")
+}
+func (UnimplementedCustomFieldsServer) mustEmbedUnimplementedCustomFieldsServer() {}
+
+// UnsafeCustomFieldsServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to CustomFieldsServer will
+// result in compilation errors.
+type UnsafeCustomFieldsServer interface {
+	mustEmbedUnimplementedCustomFieldsServer()
+}
+
+func RegisterCustomFieldsServer(s grpc.ServiceRegistrar, srv CustomFieldsServer) {
+	s.RegisterService(&CustomFields_ServiceDesc, srv)
+}
+
+func _CustomFields_DefineField_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CustomFieldDefinition)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CustomFieldsServer).DefineField(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/task.CustomFields/DefineField",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CustomFieldsServer).DefineField(ctx, req.(*CustomFieldDefinition))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CustomFields_ListFields_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListFieldsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CustomFieldsServer).ListFields(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/task.CustomFields/ListFields",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CustomFieldsServer).ListFields(ctx, req.(*ListFieldsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CustomFields_DeleteField_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteFieldRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CustomFieldsServer).DeleteField(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/task.CustomFields/DeleteField",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CustomFieldsServer).DeleteField(ctx, req.(*DeleteFieldRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// CustomFields_ServiceDesc is the grpc.ServiceDesc for CustomFields service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var CustomFields_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "task.CustomFields",
+	HandlerType: (*CustomFieldsServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "DefineField",
+			Handler:    _CustomFields_DefineField_Handler,
+		},
+		{
+			MethodName: "ListFields",
+			Handler:    _CustomFields_ListFields_Handler,
+		},
+		{
+			MethodName: "DeleteField",
+			Handler:    _CustomFields_DeleteField_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "tasks/customfield.proto",
+}
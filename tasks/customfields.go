@@ -0,0 +1,284 @@
+package tasks
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/teamwork/log"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// customFieldPrefix starts update mask paths and order_by values which
+// refer to a single custom field.
+const customFieldPrefix = "custom_fields."
+
+// fieldsKey is the meta key the custom field definitions are saved under.
+const fieldsKey = "fields"
+
+// fieldState holds the custom field definitions by project and name.
+type fieldState struct {
+	defs map[string]map[string]*CustomFieldDefinition
+}
+
+func (fs *fieldState) load(tx Tx) error {
+	stored := &StoredFields{}
+	if err := getState(tx, fieldsKey, stored); err != nil {
+		return err
+	}
+	fs.defs = make(map[string]map[string]*CustomFieldDefinition)
+	for _, d := range stored.Fields {
+		if fs.defs[d.Project] == nil {
+			fs.defs[d.Project] = make(map[string]*CustomFieldDefinition)
+		}
+		fs.defs[d.Project][d.Name] = d
+	}
+	return nil
+}
+
+func (fs *fieldState) save(tx Tx) error {
+	stored := &StoredFields{}
+	for _, defs := range fs.defs {
+		for _, d := range defs {
+			stored.Fields = append(stored.Fields, d)
+		}
+	}
+	sort.Slice(stored.Fields, func(i, j int) bool {
+		a, b := stored.Fields[i], stored.Fields[j]
+		if a.Project != b.Project {
+			return a.Project < b.Project
+		}
+		return a.Name < b.Name
+	})
+	return putState(tx, fieldsKey, stored)
+}
+
+type CustomFieldService struct {
+	UnimplementedCustomFieldsServer
+
+	tasks *TaskService
+}
+
+func NewCustomFieldService(tasks *TaskService) *CustomFieldService {
+	return &CustomFieldService{tasks: tasks}
+}
+
+// DefineField adds or replaces a field definition. Replacing a definition
+// fails if any of the project's tasks wouldn't be valid under it.
+func (s *CustomFieldService) DefineField(c context.Context, d *CustomFieldDefinition) (_ *CustomFieldDefinition, err error) {
+	if d.Name == "" || strings.ContainsAny(d.Name, ". ") {
+		return nil, status.Error(codes.InvalidArgument, "field name must be set and can't contain dots or spaces")
+	}
+	if d.Type == CustomFieldDefinition_ENUM && len(d.EnumValues) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "enum fields need at least one value")
+	}
+	if d.Type != CustomFieldDefinition_ENUM && len(d.EnumValues) > 0 {
+		return nil, status.Error(codes.InvalidArgument, "only enum fields can have values")
+	}
+	def := proto.Clone(d).(*CustomFieldDefinition)
+
+	ts := s.tasks
+	ts.mu.Lock()
+	defer ts.unlock(&err)
+
+	defs := ts.fields.defs[d.Project]
+	if defs == nil {
+		defs = make(map[string]*CustomFieldDefinition)
+		ts.fields.defs[d.Project] = defs
+	}
+	defs[d.Name] = def
+	ts.stateChangedLocked(ts.fields)
+
+	for _, t := range ts.tasks {
+		if t.Project != d.Project {
+			continue
+		}
+		if err := ts.validateCustomFieldsLocked(t); err != nil {
+			return nil, status.Errorf(codes.FailedPrecondition, "task %d doesn't fit the new definition: %s", t.Id, status.Convert(err).Message())
+		}
+	}
+
+	log.Infof("Defined custom field %s on project %q", d.Name, d.Project)
+	return proto.Clone(def).(*CustomFieldDefinition), nil
+}
+
+func (s *CustomFieldService) ListFields(c context.Context, r *ListFieldsRequest) (*ListFieldsResponse, error) {
+	ts := s.tasks
+	ts.mu.RLock()
+	defer ts.mu.RUnlock()
+
+	resp := &ListFieldsResponse{}
+	for _, d := range ts.fields.defs[r.Project] {
+		resp.Fields = append(resp.Fields, proto.Clone(d).(*CustomFieldDefinition))
+	}
+	sort.Slice(resp.Fields, func(i, j int) bool {
+		return resp.Fields[i].Name < resp.Fields[j].Name
+	})
+	return resp, nil
+}
+
+// DeleteField removes a definition and the field's value from every task in
+// the project.
+func (s *CustomFieldService) DeleteField(c context.Context, r *DeleteFieldRequest) (_ *DeleteFieldResponse, err error) {
+	ts := s.tasks
+	ts.mu.Lock()
+	defer ts.unlock(&err)
+
+	if _, ok := ts.fields.defs[r.Project][r.Name]; !ok {
+		return nil, status.Errorf(codes.NotFound, "project %q has no custom field %s", r.Project, r.Name)
+	}
+	delete(ts.fields.defs[r.Project], r.Name)
+	ts.stateChangedLocked(ts.fields)
+
+	resp := &DeleteFieldResponse{}
+	for _, t := range ts.matchLocked(&TaskFilter{Project: r.Project, IncludeArchived: true, IncludeSnoozed: true}) {
+		if _, ok := t.CustomFields[r.Name]; !ok {
+			continue
+		}
+		old := proto.Clone(t).(*Task)
+		delete(t.CustomFields, r.Name)
+		ts.changedLocked(old, t)
+		resp.Ids = append(resp.Ids, t.Id)
+	}
+
+	log.Infof("Deleted custom field %s from project %q and %d tasks", r.Name, r.Project, len(resp.Ids))
+	return resp, nil
+}
+
+// validateCustomFieldsLocked checks t's custom field values against its
+// project's definitions. s.mu must be held.
+func (s *TaskService) validateCustomFieldsLocked(t *Task) error {
+	defs := s.fields.defs[t.Project]
+	for name, v := range t.CustomFields {
+		d, ok := defs[name]
+		if !ok {
+			return status.Errorf(codes.InvalidArgument, "project %q has no custom field %s", t.Project, name)
+		}
+		if err := checkValue(d, v); err != nil {
+			return status.Errorf(codes.InvalidArgument, "custom field %s: %s", name, err)
+		}
+	}
+	for name, d := range defs {
+		if _, ok := t.CustomFields[name]; d.Required && !ok {
+			return status.Errorf(codes.InvalidArgument, "custom field %s is required", name)
+		}
+	}
+	return nil
+}
+
+func checkValue(d *CustomFieldDefinition, v *CustomValue) error {
+	switch val := v.GetValue().(type) {
+	case *CustomValue_StringValue:
+		if d.Type == CustomFieldDefinition_STRING {
+			return nil
+		}
+	case *CustomValue_NumberValue:
+		if d.Type == CustomFieldDefinition_NUMBER {
+			return nil
+		}
+	case *CustomValue_EnumValue:
+		if d.Type == CustomFieldDefinition_ENUM {
+			for _, ev := range d.EnumValues {
+				if ev == val.EnumValue {
+					return nil
+				}
+			}
+			return fmt.Errorf("%q is not one of %s", val.EnumValue, strings.Join(d.EnumValues, ", "))
+		}
+	case *CustomValue_DateValue:
+		if d.Type == CustomFieldDefinition_DATE {
+			return val.DateValue.CheckValid()
+		}
+	case *CustomValue_UserValue:
+		if d.Type == CustomFieldDefinition_USER {
+			if val.UserValue == "" {
+				return fmt.Errorf("user can't be empty")
+			}
+			return nil
+		}
+	case nil:
+		return fmt.Errorf("no value set")
+	}
+	return fmt.Errorf("expected a %s value", strings.ToLower(d.Type.String()))
+}
+
+// compareValues orders two custom values, reporting false if they are of
+// different types and can't be compared.
+func compareValues(a, b *CustomValue) (int, bool) {
+	switch av := a.GetValue().(type) {
+	case *CustomValue_NumberValue:
+		bv, ok := b.GetValue().(*CustomValue_NumberValue)
+		if !ok {
+			return 0, false
+		}
+		switch {
+		case av.NumberValue < bv.NumberValue:
+			return -1, true
+		case av.NumberValue > bv.NumberValue:
+			return 1, true
+		}
+		return 0, true
+	case *CustomValue_DateValue:
+		bv, ok := b.GetValue().(*CustomValue_DateValue)
+		if !ok {
+			return 0, false
+		}
+		at, bt := av.DateValue.AsTime(), bv.DateValue.AsTime()
+		switch {
+		case at.Before(bt):
+			return -1, true
+		case at.After(bt):
+			return 1, true
+		}
+		return 0, true
+	case *CustomValue_StringValue:
+		bv, ok := b.GetValue().(*CustomValue_StringValue)
+		if !ok {
+			return 0, false
+		}
+		return strings.Compare(av.StringValue, bv.StringValue), true
+	case *CustomValue_EnumValue:
+		bv, ok := b.GetValue().(*CustomValue_EnumValue)
+		if !ok {
+			return 0, false
+		}
+		return strings.Compare(av.EnumValue, bv.EnumValue), true
+	case *CustomValue_UserValue:
+		bv, ok := b.GetValue().(*CustomValue_UserValue)
+		if !ok {
+			return 0, false
+		}
+		return strings.Compare(av.UserValue, bv.UserValue), true
+	}
+	return 0, false
+}
+
+// matches reports whether t's value for the field passes the filter.
+func (f *CustomFieldFilter) matches(t *Task) bool {
+	v, ok := t.CustomFields[f.Name]
+	if !ok {
+		return f.Op == CustomFieldFilter_NOT_EQUALS
+	}
+	cmp, ok := compareValues(v, f.Value)
+	if !ok {
+		return f.Op == CustomFieldFilter_NOT_EQUALS
+	}
+	switch f.Op {
+	case CustomFieldFilter_EQUALS:
+		return cmp == 0
+	case CustomFieldFilter_NOT_EQUALS:
+		return cmp != 0
+	case CustomFieldFilter_LESS:
+		return cmp < 0
+	case CustomFieldFilter_LESS_OR_EQUAL:
+		return cmp <= 0
+	case CustomFieldFilter_GREATER:
+		return cmp > 0
+	case CustomFieldFilter_GREATER_OR_EQUAL:
+		return cmp >= 0
+	}
+	return false
+}
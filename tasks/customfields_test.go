@@ -0,0 +1,91 @@
+package tasks
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// Values must be defined for the task's project and of the field's type,
+// and required fields must be set.
+func TestCustomFieldValidation(t *testing.T) {
+	ctx := context.Background()
+	ts, err := NewTaskService(NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields := NewCustomFieldService(ts)
+	for _, d := range []*CustomFieldDefinition{
+		{Project: "p", Name: "points", Type: CustomFieldDefinition_NUMBER, Required: true},
+		{Project: "p", Name: "size", Type: CustomFieldDefinition_ENUM, EnumValues: []string{"S", "M", "L"}},
+		{Project: "p", Name: "owner", Type: CustomFieldDefinition_USER},
+	} {
+		if _, err := fields.DefineField(ctx, d); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, bad := range []*CustomFieldDefinition{
+		{Project: "p", Name: "has.dot"},
+		{Project: "p", Name: "kind", Type: CustomFieldDefinition_ENUM},
+		{Project: "p", Name: "text", EnumValues: []string{"a"}},
+	} {
+		if _, err := fields.DefineField(ctx, bad); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("defining %v returned %v", bad, err)
+		}
+	}
+
+	number := func(n float64) *CustomValue { return &CustomValue{Value: &CustomValue_NumberValue{NumberValue: n}} }
+	enum := func(s string) *CustomValue { return &CustomValue{Value: &CustomValue_EnumValue{EnumValue: s}} }
+	for name, values := range map[string]map[string]*CustomValue{
+		"missing required":  {"size": enum("S")},
+		"wrong type":        {"points": enum("S")},
+		"not an enum value": {"points": number(1), "size": enum("XL")},
+		"empty user":        {"points": number(1), "owner": {Value: &CustomValue_UserValue{}}},
+		"no value":          {"points": number(1), "owner": {}},
+		"undefined":         {"points": number(1), "colour": enum("red")},
+	} {
+		_, err := ts.Create(ctx, &TaskRequest{Title: name, Project: "p", CustomFields: values})
+		if status.Code(err) != codes.InvalidArgument {
+			t.Errorf("creating a task with %s returned %v", name, err)
+		}
+	}
+	_, err = ts.Create(ctx, &TaskRequest{Title: "ok", Project: "p", CustomFields: map[string]*CustomValue{
+		"points": number(3),
+		"size":   enum("M"),
+	}})
+	if err != nil {
+		t.Fatalf("creating a valid task: %v", err)
+	}
+	if _, err := ts.Create(ctx, &TaskRequest{Title: "elsewhere", Project: "q"}); err != nil {
+		t.Errorf("another project's required fields were enforced: %v", err)
+	}
+
+	_, err = ts.Update(ctx, &UpdateRequest{
+		Id:         1,
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"custom_fields.points"}},
+	})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("clearing a required field returned %v", err)
+	}
+	_, err = ts.Update(ctx, &UpdateRequest{
+		Id:         1,
+		Task:       &Task{CustomFields: map[string]*CustomValue{"size": enum("L")}},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"custom_fields.size"}},
+	})
+	if err != nil {
+		t.Errorf("setting one field: %v", err)
+	}
+
+	// A definition which an existing task breaks is refused.
+	_, err = fields.DefineField(ctx, &CustomFieldDefinition{Project: "p", Name: "size", Type: CustomFieldDefinition_ENUM, EnumValues: []string{"S"}})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("narrowing an enum a task uses returned %v", err)
+	}
+	task, err := ts.Get(ctx, &GetRequest{Id: 1})
+	if err != nil || task.CustomFields["size"].GetEnumValue() != "L" || task.CustomFields["points"].GetNumberValue() != 3 {
+		t.Errorf("task after the updates is %v, %v", task, err)
+	}
+}
@@ -0,0 +1,135 @@
+package tasks
+
+import (
+	"context"
+
+	"github.com/teamwork/log"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// Link makes r.TaskId depend on r.DependsOn. Linking the same tasks twice
+// does nothing.
+func (s *TaskService) Link(c context.Context, r *LinkRequest) (_ *Task, err error) {
+	s.mu.Lock()
+	defer s.unlock(&err)
+
+	t, ok := s.tasks[r.TaskId]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "task %d not found", r.TaskId)
+	}
+	if err := s.linkLocked(t, r.DependsOn); err != nil {
+		return nil, err
+	}
+	return proto.Clone(t).(*Task), nil
+}
+
+func (s *TaskService) Unlink(c context.Context, r *LinkRequest) (_ *Task, err error) {
+	s.mu.Lock()
+	defer s.unlock(&err)
+
+	t, ok := s.tasks[r.TaskId]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "task %d not found", r.TaskId)
+	}
+	if containsInt(t.DependsOn, r.DependsOn) {
+		old := proto.Clone(t).(*Task)
+		t.DependsOn = removeInt(t.DependsOn, r.DependsOn)
+		s.changedLocked(old, t)
+		log.Infof("Task %d no longer depends on %d", t.Id, r.DependsOn)
+	}
+	return proto.Clone(t).(*Task), nil
+}
+
+// linkLocked makes t depend on id, refusing links which would make a cycle.
+// s.mu must be held.
+func (s *TaskService) linkLocked(t *Task, id int64) error {
+	if _, ok := s.tasks[id]; !ok {
+		return status.Errorf(codes.NotFound, "task %d not found", id)
+	}
+	if containsInt(t.DependsOn, id) {
+		return nil
+	}
+	if s.dependsLocked(id, t.Id) {
+		return status.Errorf(codes.FailedPrecondition, "task %d already depends on task %d", id, t.Id)
+	}
+
+	old := proto.Clone(t).(*Task)
+	t.DependsOn = append(t.DependsOn, id)
+	s.changedLocked(old, t)
+
+	log.Infof("Task %d now depends on %d", t.Id, id)
+	return nil
+}
+
+// dependsLocked reports whether from depends on to, directly or through
+// other tasks. A task counts as depending on itself. s.mu must be held.
+func (s *TaskService) dependsLocked(from, to int64) bool {
+	return s.dependsWithLocked(from, to, nil)
+}
+
+// dependsWithLocked is dependsLocked with the dependencies of the tasks in
+// edges replaced, to check changes before making them. s.mu must be held.
+func (s *TaskService) dependsWithLocked(from, to int64, edges map[int64][]int64) bool {
+	seen := make(map[int64]bool)
+	stack := []int64{from}
+	for len(stack) > 0 {
+		id := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if id == to {
+			return true
+		}
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		if deps, ok := edges[id]; ok {
+			stack = append(stack, deps...)
+		} else if t, ok := s.tasks[id]; ok {
+			stack = append(stack, t.DependsOn...)
+		}
+	}
+	return false
+}
+
+// unlinkAllLocked drops every dependency on the tasks in ids. s.mu must be
+// held.
+func (s *TaskService) unlinkAllLocked(ids []int64) {
+	gone := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		gone[id] = true
+	}
+	for _, t := range s.tasks {
+		var kept []int64
+		for _, id := range t.DependsOn {
+			if !gone[id] {
+				kept = append(kept, id)
+			}
+		}
+		if len(kept) != len(t.DependsOn) {
+			old := proto.Clone(t).(*Task)
+			t.DependsOn = kept
+			s.changedLocked(old, t)
+		}
+	}
+}
+
+func containsInt(ids []int64, id int64) bool {
+	for _, i := range ids {
+		if i == id {
+			return true
+		}
+	}
+	return false
+}
+
+func removeInt(ids []int64, id int64) []int64 {
+	var kept []int64
+	for _, i := range ids {
+		if i != id {
+			kept = append(kept, i)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,144 @@
+package tasks
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/teamwork/log"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// eventBuffer is how many events a watcher can fall behind by before it is
+// disconnected.
+const eventBuffer = 256
+
+type subscriber struct {
+	filter *TaskFilter
+	events chan *TaskEvent
+	// overflowed is closed if the subscriber was dropped for being slow.
+	overflowed chan struct{}
+}
+
+// eventHub fans task changes out to Watch streams. It's a Sink, fed from
+// the outbox by a Relay.
+type eventHub struct {
+	mu   sync.Mutex
+	subs map[*subscriber]bool
+}
+
+func newEventHub() *eventHub {
+	return &eventHub{subs: make(map[*subscriber]bool)}
+}
+
+func (h *eventHub) subscribe(f *TaskFilter) *subscriber {
+	if f == nil {
+		f = &TaskFilter{}
+	}
+	f = proto.Clone(f).(*TaskFilter)
+	f.IncludeArchived = true
+	f.IncludeSnoozed = true
+
+	sub := &subscriber{
+		filter:     f,
+		events:     make(chan *TaskEvent, eventBuffer),
+		overflowed: make(chan struct{}),
+	}
+	h.mu.Lock()
+	h.subs[sub] = true
+	h.mu.Unlock()
+	return sub
+}
+
+func (h *eventHub) unsubscribe(sub *subscriber) {
+	h.mu.Lock()
+	delete(h.subs, sub)
+	h.mu.Unlock()
+}
+
+func (h *eventHub) Name() string {
+	return "watch"
+}
+
+// Deliver passes events to the Watch streams they match. Streams which
+// have fallen behind are dropped rather than waited for, so it never
+// fails.
+func (h *eventHub) Deliver(ctx context.Context, events []*TaskEvent) error {
+	for _, ev := range events {
+		h.publish(ev)
+	}
+	return nil
+}
+
+func (h *eventHub) publish(ev *TaskEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for sub := range h.subs {
+		if !sub.filter.matches(ev.Task) {
+			continue
+		}
+		select {
+		case sub.events <- ev:
+		default:
+			delete(h.subs, sub)
+			close(sub.overflowed)
+		}
+	}
+}
+
+// WatchSink returns the sink feeding Watch streams, which a Relay must
+// deliver to for them to hear of any changes.
+func (s *TaskService) WatchSink() Sink {
+	return s.events
+}
+
+// Watch streams task changes until the client goes away.
+func (s *TaskService) Watch(r *WatchRequest, stream Tasks_WatchServer) error {
+	sub := s.events.subscribe(r.Filter)
+	defer s.events.unsubscribe(sub)
+
+	log.Info("Watcher connected")
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-sub.overflowed:
+			return status.Error(codes.ResourceExhausted, "watcher fell too far behind")
+		case ev := <-sub.events:
+			if err := stream.Send(ev); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// newTaskEvent describes a change to a task made at the given time.
+func newTaskEvent(old, new *Task, at time.Time) *TaskEvent {
+	ev := &TaskEvent{
+		Type: eventType(old, new),
+		Task: new,
+		At:   timestamppb.New(at),
+	}
+	if new == nil {
+		ev.Task = old
+	}
+	return ev
+}
+
+func eventType(old, new *Task) TaskEvent_Type {
+	switch {
+	case old == nil:
+		return TaskEvent_CREATED
+	case new == nil:
+		return TaskEvent_DELETED
+	case old.SnoozedUntil == nil && new.SnoozedUntil != nil:
+		return TaskEvent_SNOOZED
+	case old.SnoozedUntil != nil && new.SnoozedUntil == nil:
+		return TaskEvent_UNSNOOZED
+	default:
+		return TaskEvent_UPDATED
+	}
+}
@@ -0,0 +1,130 @@
+package tasks
+
+import (
+	"context"
+
+	"github.com/teamwork/log"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// maxExecuteOps limits how many operations one Execute can apply.
+const maxExecuteOps = 1000
+
+// Execute applies r.Ops in order as one change, so if any fails the tasks
+// are left as they were and watchers hear of none of it.
+func (s *TaskService) Execute(c context.Context, r *ExecuteRequest) (_ *ExecuteResponse, err error) {
+	if len(r.Ops) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "ops must have at least one operation")
+	}
+	if len(r.Ops) > maxExecuteOps {
+		return nil, status.Errorf(codes.InvalidArgument, "at most %d operations can be executed at once", maxExecuteOps)
+	}
+
+	s.mu.Lock()
+	defer s.unlock(&err)
+
+	// created holds the id of the task each operation created, if any, for
+	// placeholders to refer to.
+	created := make([]int64, len(r.Ops))
+	resp := &ExecuteResponse{}
+	for i, op := range r.Ops {
+		resolve := func(id *int64) error {
+			if *id >= 0 {
+				return nil
+			}
+			// -math.MinInt64 overflows back to itself, so n is checked
+			// for that too.
+			n := -*id
+			if n <= 0 || n > int64(i) || created[n-1] == 0 {
+				return status.Errorf(codes.InvalidArgument, "placeholder %d doesn't refer to an earlier create", *id)
+			}
+			*id = created[n-1]
+			return nil
+		}
+		result, err := s.executeLocked(op, resolve)
+		if err != nil {
+			st := status.Convert(err)
+			return nil, status.Errorf(st.Code(), "operation %d: %s", i+1, st.Message())
+		}
+		if res, ok := result.Result.(*ExecuteResult_Created); ok {
+			created[i] = res.Created.Id
+		}
+		resp.Results = append(resp.Results, result)
+	}
+
+	log.Infof("Executed %d operations", len(r.Ops))
+	return resp, nil
+}
+
+// executeLocked applies one operation, first replacing the placeholders in
+// a copy of it using resolve. s.mu must be held.
+func (s *TaskService) executeLocked(op *ExecuteOp, resolve func(id *int64) error) (*ExecuteResult, error) {
+	switch op := op.Op.(type) {
+	case *ExecuteOp_Create:
+		r := proto.Clone(op.Create).(*TaskRequest)
+		if err := resolve(&r.ParentId); err != nil {
+			return nil, err
+		}
+		resp, err := s.createLocked(r)
+		if err != nil {
+			return nil, err
+		}
+		return &ExecuteResult{Result: &ExecuteResult_Created{Created: resp}}, nil
+
+	case *ExecuteOp_Update:
+		r := proto.Clone(op.Update).(*UpdateRequest)
+		if err := resolve(&r.Id); err != nil {
+			return nil, err
+		}
+		if r.Task != nil {
+			if err := resolve(&r.Task.ParentId); err != nil {
+				return nil, err
+			}
+		}
+		paths := r.UpdateMask.GetPaths()
+		if err := validateMask(paths); err != nil {
+			return nil, err
+		}
+		t, ok := s.tasks[r.Id]
+		if !ok {
+			return nil, status.Errorf(codes.NotFound, "task %d not found", r.Id)
+		}
+		if err := s.updateLocked(t, r.Task, paths); err != nil {
+			return nil, err
+		}
+		return &ExecuteResult{Result: &ExecuteResult_Updated{Updated: proto.Clone(t).(*Task)}}, nil
+
+	case *ExecuteOp_Delete:
+		id := op.Delete.Id
+		if err := resolve(&id); err != nil {
+			return nil, err
+		}
+		if _, ok := s.tasks[id]; !ok {
+			return nil, status.Errorf(codes.NotFound, "task %d not found", id)
+		}
+		ids := s.deleteLocked(id)
+		return &ExecuteResult{Result: &ExecuteResult_Deleted{Deleted: &DeleteResponse{Ids: ids}}}, nil
+
+	case *ExecuteOp_Link:
+		taskID, dependsOn := op.Link.TaskId, op.Link.DependsOn
+		if err := resolve(&taskID); err != nil {
+			return nil, err
+		}
+		if err := resolve(&dependsOn); err != nil {
+			return nil, err
+		}
+		t, ok := s.tasks[taskID]
+		if !ok {
+			return nil, status.Errorf(codes.NotFound, "task %d not found", taskID)
+		}
+		if err := s.linkLocked(t, dependsOn); err != nil {
+			return nil, err
+		}
+		return &ExecuteResult{Result: &ExecuteResult_Linked{Linked: proto.Clone(t).(*Task)}}, nil
+
+	default:
+		return nil, status.Error(codes.InvalidArgument, "operation has nothing to do")
+	}
+}
@@ -0,0 +1,75 @@
+package tasks
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Placeholders refer to tasks created earlier in the same Execute.
+func TestExecutePlaceholders(t *testing.T) {
+	ctx := context.Background()
+	ts, err := NewTaskService(NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := ts.Execute(ctx, &ExecuteRequest{Ops: []*ExecuteOp{
+		{Op: &ExecuteOp_Create{Create: &TaskRequest{Title: "parent"}}},
+		{Op: &ExecuteOp_Create{Create: &TaskRequest{Title: "child", ParentId: -1}}},
+		{Op: &ExecuteOp_Create{Create: &TaskRequest{Title: "first"}}},
+		{Op: &ExecuteOp_Link{Link: &LinkRequest{TaskId: -2, DependsOn: -3}}},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Results) != 4 {
+		t.Fatalf("got %d results, want 4", len(resp.Results))
+	}
+	child, err := ts.Get(ctx, &GetRequest{Id: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if child.ParentId != 1 || len(child.DependsOn) != 1 || child.DependsOn[0] != 3 {
+		t.Errorf("child has parent %d and depends on %v, want 1 and [3]", child.ParentId, child.DependsOn)
+	}
+
+	for _, id := range []int64{-1, -5, math.MinInt64} {
+		_, err := ts.Execute(ctx, &ExecuteRequest{Ops: []*ExecuteOp{
+			{Op: &ExecuteOp_Delete{Delete: &DeleteRequest{Id: 1}}},
+			{Op: &ExecuteOp_Delete{Delete: &DeleteRequest{Id: id}}},
+		}})
+		if status.Code(err) != codes.InvalidArgument {
+			t.Errorf("placeholder %d returned %v", id, err)
+		}
+	}
+}
+
+// If one operation fails, the ones before it are undone.
+func TestExecuteRollback(t *testing.T) {
+	ctx := context.Background()
+	ts, err := NewTaskService(NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ts.Create(ctx, &TaskRequest{Title: "kept"}); err != nil {
+		t.Fatal(err)
+	}
+	_, err = ts.Execute(ctx, &ExecuteRequest{Ops: []*ExecuteOp{
+		{Op: &ExecuteOp_Create{Create: &TaskRequest{Title: "undone"}}},
+		{Op: &ExecuteOp_Delete{Delete: &DeleteRequest{Id: 1}}},
+		{Op: &ExecuteOp_Delete{Delete: &DeleteRequest{Id: 10}}},
+	}})
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("deleting a missing task returned %v", err)
+	}
+	list, err := ts.List(ctx, &ListRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Tasks) != 1 || list.Tasks[0].Title != "kept" {
+		t.Errorf("tasks after a failed Execute are %v, want only the one kept", list.Tasks)
+	}
+}
@@ -0,0 +1,752 @@
+// Package filestore keeps tasks in a directory on local disk. Every
+// transaction is appended to a checksummed write-ahead log before it's
+// applied, and the log is folded into a snapshot from time to time so it
+// doesn't grow forever.
+//
+// Importing the package registers it with tasks.OpenStore as "file", taking
+// a directory and optional settings, as in
+// "file:/var/lib/tasks?sync=100ms&segment=16777216&snapshot=67108864".
+package filestore
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/andyantrim/grpc-example/tasks"
+	"github.com/teamwork/log"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	DefaultSegmentSize  = 16 << 20
+	DefaultSnapshotSize = 64 << 20
+)
+
+type Options struct {
+	// SyncInterval batches fsyncs of the log. When it's 0 every commit is
+	// synced before it returns. Otherwise the log is synced this often, and
+	// a crash can lose commits made since the last sync.
+	SyncInterval time.Duration
+	// SegmentSize is how large a log segment grows before a new one is
+	// started.
+	SegmentSize int64
+	// SnapshotSize is how much log is written between snapshots.
+	SnapshotSize int64
+}
+
+func init() {
+	tasks.RegisterStore("file", openConfig)
+}
+
+// openConfig opens a store from the part of a tasks.OpenStore config after
+// "file:".
+func openConfig(addr string) (tasks.Store, error) {
+	dir, query := addr, ""
+	if i := strings.IndexByte(addr, '?'); i >= 0 {
+		dir, query = addr[:i], addr[i+1:]
+	}
+	if dir == "" {
+		return nil, fmt.Errorf("file store needs a directory, as in file:/var/lib/tasks")
+	}
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		return nil, fmt.Errorf("file store settings: %v", err)
+	}
+
+	var opts Options
+	for key := range values {
+		v := values.Get(key)
+		switch key {
+		case "sync":
+			opts.SyncInterval, err = time.ParseDuration(v)
+		case "segment":
+			opts.SegmentSize, err = strconv.ParseInt(v, 10, 64)
+		case "snapshot":
+			opts.SnapshotSize, err = strconv.ParseInt(v, 10, 64)
+		default:
+			err = fmt.Errorf("unknown setting")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("file store setting %s: %v", key, err)
+		}
+	}
+	return Open(dir, opts)
+}
+
+// state is everything stored. Tasks are kept encoded.
+type state struct {
+	tasks  map[int64][]byte
+	blobs  map[string][]byte
+	meta   map[string][]byte
+	outbox map[int64][]byte
+}
+
+func newState() *state {
+	return &state{
+		tasks:  make(map[int64][]byte),
+		blobs:  make(map[string][]byte),
+		meta:   make(map[string][]byte),
+		outbox: make(map[int64][]byte),
+	}
+}
+
+func (st *state) apply(o op) {
+	switch o.kind {
+	case opPutTask:
+		st.tasks[o.id] = o.value
+	case opDeleteTask:
+		delete(st.tasks, o.id)
+	case opPutBlob:
+		st.blobs[o.key] = o.value
+	case opPutMeta:
+		st.meta[o.key] = o.value
+	case opPutOutbox:
+		st.outbox[o.id] = o.value
+	case opTrimOutbox:
+		for seq := range st.outbox {
+			if seq <= o.id {
+				delete(st.outbox, seq)
+			}
+		}
+	}
+}
+
+type Store struct {
+	dir  string
+	opts Options
+
+	// mu is held by transactions, read-only ones sharing it, which keeps
+	// them isolated.
+	mu     sync.RWMutex
+	st     *state
+	lsn    uint64
+	closed bool
+
+	// fileMu guards the log file. Commits take it while holding mu.
+	fileMu        sync.Mutex
+	seg           *os.File
+	segStart      uint64
+	segSize       int64
+	sinceSnapshot int64
+	unsynced      bool
+	// failed is set when the log couldn't be written. The store refuses
+	// writes from then on, as it no longer knows what's on disk.
+	failed error
+
+	snapMu      sync.Mutex
+	snapLSN     uint64
+	snapRunning int32
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// Open opens the store in dir, creating it if needed, and recovers
+// anything committed before the last shutdown or crash.
+func Open(dir string, opts Options) (*Store, error) {
+	if opts.SegmentSize <= 0 {
+		opts.SegmentSize = DefaultSegmentSize
+	}
+	if opts.SnapshotSize <= 0 {
+		opts.SnapshotSize = DefaultSnapshotSize
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	s := &Store{dir: dir, opts: opts, st: newState()}
+	if err := s.recover(); err != nil {
+		return nil, fmt.Errorf("recovering %s: %w", dir, err)
+	}
+
+	if opts.SyncInterval > 0 {
+		s.stop = make(chan struct{})
+		s.done = make(chan struct{})
+		go s.syncLoop()
+	}
+	return s, nil
+}
+
+// recover loads the newest snapshot and replays the log after it.
+func (s *Store) recover() error {
+	tmps, _ := filepath.Glob(filepath.Join(s.dir, "*.tmp"))
+	for _, tmp := range tmps {
+		os.Remove(tmp)
+	}
+
+	snaps, err := s.files("snap-", ".snap")
+	if err != nil {
+		return err
+	}
+	if len(snaps) > 0 {
+		newest := snaps[len(snaps)-1]
+		lsn, st, err := readSnapshot(s.path("snap-", newest, ".snap"))
+		if err != nil {
+			return err
+		}
+		s.lsn, s.st, s.snapLSN = lsn, st, lsn
+	}
+
+	segs, err := s.files("wal-", ".log")
+	if err != nil {
+		return err
+	}
+	for i, start := range segs {
+		path := s.path("wal-", start, ".log")
+		records, err := readSegment(path)
+		last := i == len(segs)-1
+		if err == errTorn && last {
+			// A crash part way through a write leaves a torn record at the
+			// end of the log. It was never committed, so it's dropped.
+			var end int64
+			if len(records) > 0 {
+				end = records[len(records)-1].end
+			}
+			log.Infof("Dropping a torn record at the end of %s", filepath.Base(path))
+			if err := os.Truncate(path, end); err != nil {
+				return err
+			}
+		} else if err != nil {
+			if err == errTorn {
+				// Segments are synced before the next is started, so only
+				// the last can have been cut short.
+				err = fmt.Errorf("%w: %v before the last segment", tasks.ErrCorrupt, err)
+			}
+			err = fmt.Errorf("%s: %w", filepath.Base(path), err)
+			log.Error(err, "Task log is damaged, refusing to open it")
+			return err
+		}
+
+		for _, r := range records {
+			if r.lsn <= s.lsn {
+				continue
+			}
+			if r.lsn != s.lsn+1 {
+				return fmt.Errorf("log is missing records %d to %d", s.lsn+1, r.lsn-1)
+			}
+			for _, o := range r.ops {
+				s.st.apply(o)
+			}
+			s.lsn = r.lsn
+		}
+	}
+
+	// Carry on writing to the last segment, or start the first.
+	start := s.lsn + 1
+	if len(segs) > 0 {
+		start = segs[len(segs)-1]
+	}
+	return s.openSegment(start)
+}
+
+// files returns the sequence numbers in the names of the files with the
+// given prefix and suffix, in order.
+func (s *Store) files(prefix, suffix string) ([]uint64, error) {
+	infos, err := ioutil.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+	var seqs []uint64
+	for _, fi := range infos {
+		name := fi.Name()
+		if !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, suffix) {
+			continue
+		}
+		n, err := strconv.ParseUint(strings.TrimSuffix(strings.TrimPrefix(name, prefix), suffix), 10, 64)
+		if err != nil {
+			continue
+		}
+		seqs = append(seqs, n)
+	}
+	sort.Slice(seqs, func(i, j int) bool { return seqs[i] < seqs[j] })
+	return seqs, nil
+}
+
+func (s *Store) path(prefix string, seq uint64, suffix string) string {
+	return filepath.Join(s.dir, fmt.Sprintf("%s%020d%s", prefix, seq, suffix))
+}
+
+// openSegment opens the log segment whose records start at start for
+// appending. s.fileMu must be held, or s not yet shared.
+func (s *Store) openSegment(start uint64) error {
+	f, err := os.OpenFile(s.path("wal-", start, ".log"), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	if err := syncDir(s.dir); err != nil {
+		f.Close()
+		return err
+	}
+	s.seg, s.segStart, s.segSize = f, start, fi.Size()
+	return nil
+}
+
+// append writes a record to the log, syncing it unless syncs are batched.
+func (s *Store) append(lsn uint64, rec []byte) error {
+	s.fileMu.Lock()
+	defer s.fileMu.Unlock()
+
+	if s.failed != nil {
+		return s.failed
+	}
+	if _, err := s.seg.Write(rec); err != nil {
+		return s.fail(err)
+	}
+	s.segSize += int64(len(rec))
+	s.sinceSnapshot += int64(len(rec))
+	if s.opts.SyncInterval == 0 {
+		if err := s.seg.Sync(); err != nil {
+			return s.fail(err)
+		}
+	} else {
+		s.unsynced = true
+	}
+
+	if s.segSize >= s.opts.SegmentSize {
+		if err := s.seg.Sync(); err != nil {
+			return s.fail(err)
+		}
+		s.unsynced = false
+		s.seg.Close()
+		if err := s.openSegment(lsn + 1); err != nil {
+			return s.fail(err)
+		}
+	}
+	return nil
+}
+
+// fail stops any more writes after the log couldn't be written. s.fileMu
+// must be held.
+func (s *Store) fail(err error) error {
+	s.failed = fmt.Errorf("writing the task log failed, no more changes can be saved until restart: %v", err)
+	log.Error(err, "Failed to write the task log")
+	return s.failed
+}
+
+func (s *Store) syncLoop() {
+	defer close(s.done)
+	ticker := time.NewTicker(s.opts.SyncInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			s.sync()
+		}
+	}
+}
+
+func (s *Store) sync() {
+	s.fileMu.Lock()
+	defer s.fileMu.Unlock()
+
+	if !s.unsynced || s.failed != nil {
+		return
+	}
+	if err := s.seg.Sync(); err != nil {
+		s.fail(err)
+		return
+	}
+	s.unsynced = false
+}
+
+// Snapshot writes everything committed so far to a snapshot, then deletes
+// the log segments and older snapshots it replaces. It runs by itself once
+// enough log has been written, but can also be called directly.
+func (s *Store) Snapshot() error {
+	s.snapMu.Lock()
+	defer s.snapMu.Unlock()
+
+	s.mu.RLock()
+	lsn := s.lsn
+	var data []byte
+	if !s.closed && lsn != s.snapLSN {
+		data = encodeSnapshot(lsn, s.st)
+	}
+	s.mu.RUnlock()
+	if data == nil {
+		return nil
+	}
+
+	if err := writeFileSync(s.path("snap-", lsn, ".snap"), data); err != nil {
+		return err
+	}
+	s.snapLSN = lsn
+
+	s.fileMu.Lock()
+	current := s.segStart
+	s.sinceSnapshot = 0
+	s.fileMu.Unlock()
+
+	// A segment holds the records from its own start up to the next
+	// segment's start, so it can go once the next one starts after lsn.
+	segs, err := s.files("wal-", ".log")
+	if err != nil {
+		return err
+	}
+	for i := 0; i+1 < len(segs) && segs[i] != current; i++ {
+		if segs[i+1] <= lsn+1 {
+			os.Remove(s.path("wal-", segs[i], ".log"))
+		}
+	}
+	snaps, err := s.files("snap-", ".snap")
+	if err != nil {
+		return err
+	}
+	for _, n := range snaps {
+		if n < lsn {
+			os.Remove(s.path("snap-", n, ".snap"))
+		}
+	}
+
+	log.Infof("Wrote task snapshot at log record %d", lsn)
+	return nil
+}
+
+// snapshotSoon starts a snapshot in the background unless one is running.
+func (s *Store) snapshotSoon() {
+	if !atomic.CompareAndSwapInt32(&s.snapRunning, 0, 1) {
+		return
+	}
+	go func() {
+		defer atomic.StoreInt32(&s.snapRunning, 0)
+		if err := s.Snapshot(); err != nil {
+			log.Error(err, "Failed to write task snapshot")
+		}
+	}()
+}
+
+func (s *Store) Begin(writable bool) (tasks.Tx, error) {
+	if writable {
+		s.mu.Lock()
+	} else {
+		s.mu.RLock()
+	}
+	tx := &fileTx{s: s, writable: writable}
+	if s.closed {
+		tx.unlock()
+		return nil, fmt.Errorf("file store %s is closed", s.dir)
+	}
+	if writable {
+		tx.tasks = make(map[int64][]byte)
+		tx.blobs = make(map[string][]byte)
+		tx.meta = make(map[string][]byte)
+		tx.outbox = make(map[int64][]byte)
+	}
+	return tx, nil
+}
+
+// Close syncs the log and closes it. Snapshots in progress are finished
+// first.
+func (s *Store) Close() error {
+	s.snapMu.Lock()
+	defer s.snapMu.Unlock()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	if s.stop != nil {
+		close(s.stop)
+		<-s.done
+	}
+
+	s.fileMu.Lock()
+	defer s.fileMu.Unlock()
+	err := s.seg.Sync()
+	if cerr := s.seg.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// fileTx collects a writable transaction's changes, which are logged and
+// applied when it commits. A nil task marks a deletion, and outbox entries
+// up to trim are removed before those added.
+type fileTx struct {
+	s        *Store
+	writable bool
+	done     bool
+
+	tasks  map[int64][]byte
+	blobs  map[string][]byte
+	meta   map[string][]byte
+	outbox map[int64][]byte
+	trim   int64
+}
+
+func (tx *fileTx) Get(id int64) (*tasks.Task, error) {
+	if tx.done {
+		return nil, tasks.ErrTxDone
+	}
+	b, ok := tx.tasks[id]
+	if !ok {
+		b, ok = tx.s.st.tasks[id]
+	}
+	if !ok || b == nil {
+		return nil, tasks.ErrNotFound
+	}
+	t := &tasks.Task{}
+	if err := proto.Unmarshal(b, t); err != nil {
+		return nil, fmt.Errorf("decoding task %d: %v", id, err)
+	}
+	return t, nil
+}
+
+func (tx *fileTx) List(after int64, limit int) ([]*tasks.Task, error) {
+	if tx.done {
+		return nil, tasks.ErrTxDone
+	}
+	var ids []int64
+	for id := range tx.s.st.tasks {
+		if _, changed := tx.tasks[id]; id > after && !changed {
+			ids = append(ids, id)
+		}
+	}
+	for id, b := range tx.tasks {
+		if id > after && b != nil {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	if limit > 0 && len(ids) > limit {
+		ids = ids[:limit]
+	}
+
+	list := make([]*tasks.Task, 0, len(ids))
+	for _, id := range ids {
+		t, err := tx.Get(id)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, t)
+	}
+	return list, nil
+}
+
+func (tx *fileTx) Put(t *tasks.Task) error {
+	if err := tx.check(); err != nil {
+		return err
+	}
+	b, err := proto.Marshal(t)
+	if err != nil {
+		return err
+	}
+	tx.tasks[t.Id] = b
+	return nil
+}
+
+func (tx *fileTx) Delete(id int64) error {
+	if err := tx.check(); err != nil {
+		return err
+	}
+	tx.tasks[id] = nil
+	return nil
+}
+
+func (tx *fileTx) GetBlob(key string) ([]byte, error) {
+	return tx.getBytes(tx.blobs, tx.s.st.blobs, key)
+}
+
+func (tx *fileTx) PutBlob(key string, data []byte) error {
+	if err := tx.check(); err != nil {
+		return err
+	}
+	tx.blobs[key] = append([]byte{}, data...)
+	return nil
+}
+
+func (tx *fileTx) GetMeta(key string) ([]byte, error) {
+	return tx.getBytes(tx.meta, tx.s.st.meta, key)
+}
+
+func (tx *fileTx) PutMeta(key string, value []byte) error {
+	if err := tx.check(); err != nil {
+		return err
+	}
+	tx.meta[key] = append([]byte{}, value...)
+	return nil
+}
+
+func (tx *fileTx) ListMeta() ([]string, error) {
+	if tx.done {
+		return nil, tasks.ErrTxDone
+	}
+	keys := make([]string, 0, len(tx.s.st.meta))
+	for key := range tx.s.st.meta {
+		keys = append(keys, key)
+	}
+	for key := range tx.meta {
+		if _, ok := tx.s.st.meta[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+func (tx *fileTx) PutOutbox(seq int64, data []byte) error {
+	if err := tx.check(); err != nil {
+		return err
+	}
+	tx.outbox[seq] = append([]byte{}, data...)
+	return nil
+}
+
+func (tx *fileTx) ListOutbox(after int64, limit int) ([]tasks.OutboxEntry, error) {
+	if tx.done {
+		return nil, tasks.ErrTxDone
+	}
+	if after < tx.trim {
+		after = tx.trim
+	}
+	var seqs []int64
+	for seq := range tx.s.st.outbox {
+		if _, added := tx.outbox[seq]; seq > after && !added {
+			seqs = append(seqs, seq)
+		}
+	}
+	for seq := range tx.outbox {
+		if seq > after {
+			seqs = append(seqs, seq)
+		}
+	}
+	sort.Slice(seqs, func(i, j int) bool { return seqs[i] < seqs[j] })
+	if limit > 0 && len(seqs) > limit {
+		seqs = seqs[:limit]
+	}
+
+	list := make([]tasks.OutboxEntry, 0, len(seqs))
+	for _, seq := range seqs {
+		data, ok := tx.outbox[seq]
+		if !ok {
+			data = tx.s.st.outbox[seq]
+		}
+		list = append(list, tasks.OutboxEntry{Seq: seq, Data: append([]byte{}, data...)})
+	}
+	return list, nil
+}
+
+func (tx *fileTx) TrimOutbox(upTo int64) error {
+	if err := tx.check(); err != nil {
+		return err
+	}
+	if upTo > tx.trim {
+		tx.trim = upTo
+	}
+	for seq := range tx.outbox {
+		if seq <= upTo {
+			delete(tx.outbox, seq)
+		}
+	}
+	return nil
+}
+
+func (tx *fileTx) getBytes(pending, stored map[string][]byte, key string) ([]byte, error) {
+	if tx.done {
+		return nil, tasks.ErrTxDone
+	}
+	b, ok := pending[key]
+	if !ok {
+		b, ok = stored[key]
+	}
+	if !ok {
+		return nil, tasks.ErrNotFound
+	}
+	return append([]byte{}, b...), nil
+}
+
+// Commit logs the transaction, then applies it.
+func (tx *fileTx) Commit() error {
+	if tx.done {
+		return tasks.ErrTxDone
+	}
+	defer tx.unlock()
+	if !tx.writable {
+		return nil
+	}
+
+	var ops []op
+	for _, id := range sortedIDs(tx.tasks) {
+		if b := tx.tasks[id]; b == nil {
+			ops = append(ops, op{kind: opDeleteTask, id: id})
+		} else {
+			ops = append(ops, op{kind: opPutTask, id: id, value: b})
+		}
+	}
+	for _, key := range sortedKeys(tx.blobs) {
+		ops = append(ops, op{kind: opPutBlob, key: key, value: tx.blobs[key]})
+	}
+	for _, key := range sortedKeys(tx.meta) {
+		ops = append(ops, op{kind: opPutMeta, key: key, value: tx.meta[key]})
+	}
+	if tx.trim > 0 {
+		ops = append(ops, op{kind: opTrimOutbox, id: tx.trim})
+	}
+	for _, seq := range sortedIDs(tx.outbox) {
+		ops = append(ops, op{kind: opPutOutbox, id: seq, value: tx.outbox[seq]})
+	}
+	if len(ops) == 0 {
+		return nil
+	}
+
+	s := tx.s
+	lsn := s.lsn + 1
+	if err := s.append(lsn, encodeRecord(lsn, ops)); err != nil {
+		return err
+	}
+	s.lsn = lsn
+	for _, o := range ops {
+		s.st.apply(o)
+	}
+
+	s.fileMu.Lock()
+	due := s.sinceSnapshot >= s.opts.SnapshotSize
+	s.fileMu.Unlock()
+	if due {
+		s.snapshotSoon()
+	}
+	return nil
+}
+
+func (tx *fileTx) Rollback() error {
+	if !tx.done {
+		tx.unlock()
+	}
+	return nil
+}
+
+func (tx *fileTx) check() error {
+	if tx.done {
+		return tasks.ErrTxDone
+	}
+	if !tx.writable {
+		return tasks.ErrReadOnly
+	}
+	return nil
+}
+
+func (tx *fileTx) unlock() {
+	tx.done = true
+	if tx.writable {
+		tx.s.mu.Unlock()
+	} else {
+		tx.s.mu.RUnlock()
+	}
+}
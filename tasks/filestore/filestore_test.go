@@ -0,0 +1,194 @@
+package filestore
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/andyantrim/grpc-example/tasks"
+	"github.com/andyantrim/grpc-example/tasks/storetest"
+)
+
+func TestStore(t *testing.T) {
+	for name, opts := range map[string]Options{
+		"Defaults": {},
+		// Small enough that the checks roll over segments and take
+		// snapshots.
+		"Small": {SegmentSize: 512, SnapshotSize: 2048},
+	} {
+		opts := opts
+		t.Run(name, func(t *testing.T) {
+			storetest.Run(t, storetest.Backend{
+				Open: func(t *testing.T) tasks.Store {
+					s, err := Open(t.TempDir(), opts)
+					if err != nil {
+						t.Fatal(err)
+					}
+					return s
+				},
+				Reopen: func(t *testing.T, s tasks.Store) tasks.Store {
+					dir := s.(*Store).dir
+					if err := s.Close(); err != nil {
+						t.Fatal(err)
+					}
+					s, err := Open(dir, opts)
+					if err != nil {
+						t.Fatal(err)
+					}
+					return s
+				},
+			})
+		})
+	}
+}
+
+// put saves a task for each title, numbering them from 1, in a transaction
+// each.
+func put(t *testing.T, s tasks.Store, titles ...string) {
+	t.Helper()
+	for i, title := range titles {
+		err := tasks.WriteTx(s, func(tx tasks.Tx) error {
+			return tx.Put(&tasks.Task{Id: int64(i + 1), Title: title})
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// checkTitles checks s holds exactly the given tasks, numbered from 1.
+func checkTitles(t *testing.T, s tasks.Store, titles ...string) {
+	t.Helper()
+	err := tasks.ReadTx(s, func(tx tasks.Tx) error {
+		list, err := tx.List(0, 0)
+		if err != nil {
+			return err
+		}
+		if len(list) != len(titles) {
+			t.Errorf("store has %d tasks, want %d", len(list), len(titles))
+			return nil
+		}
+		for i, task := range list {
+			if task.Id != int64(i+1) || task.Title != titles[i] {
+				t.Errorf("task %d is %d %q, want %q", i+1, task.Id, task.Title, titles[i])
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// lastSegment returns the path of the segment s is writing to.
+func lastSegment(t *testing.T, s *Store) string {
+	t.Helper()
+	segs, err := s.files("wal-", ".log")
+	if err != nil || len(segs) == 0 {
+		t.Fatalf("listing segments: %v, %v", segs, err)
+	}
+	return s.path("wal-", segs[len(segs)-1], ".log")
+}
+
+// A record cut short at the end of the log was never committed, so it's
+// dropped and writing carries on after the last good one.
+func TestTornRecord(t *testing.T) {
+	dir := t.TempDir()
+	s, err := Open(dir, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	put(t, s, "a", "b")
+	path := lastSegment(t, s)
+	s.Close()
+
+	rec := encodeRecord(3, []op{{kind: opPutTask, id: 3, value: []byte("lost")}})
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Write(rec[:len(rec)/2])
+	f.Close()
+
+	s, err = Open(dir, Options{})
+	if err != nil {
+		t.Fatalf("opening with a torn record: %v", err)
+	}
+	checkTitles(t, s, "a", "b")
+	put(t, s, "a", "b", "c")
+	s.Close()
+	s, err = Open(dir, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	checkTitles(t, s, "a", "b", "c")
+}
+
+// A damaged record with committed ones after it isn't dropped along with
+// them: the store refuses to open instead.
+func TestCorruptRecord(t *testing.T) {
+	dir := t.TempDir()
+	s, err := Open(dir, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	put(t, s, "a", "b", "c")
+	path := lastSegment(t, s)
+	s.Close()
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b[headerSize+2] ^= 1
+	if err := ioutil.WriteFile(path, b, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Open(dir, Options{}); !errors.Is(err, tasks.ErrCorrupt) {
+		t.Fatalf("opening with a bad record mid-segment returned %v", err)
+	}
+	after, err := ioutil.ReadFile(path)
+	if err != nil || !bytes.Equal(after, b) {
+		t.Errorf("segment was changed by the failed open: %v", err)
+	}
+}
+
+// Reopening after a snapshot has replaced old segments loads the snapshot
+// and replays the log written since.
+func TestReopenAfterSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	opts := Options{SegmentSize: 256}
+	s, err := Open(dir, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var titles []string
+	for i := 0; i < 20; i++ {
+		titles = append(titles, strings.Repeat("x", i+1))
+	}
+	put(t, s, titles[:15]...)
+	if err := s.Snapshot(); err != nil {
+		t.Fatal(err)
+	}
+	put(t, s, titles...)
+	segs, err := s.files("wal-", ".log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if segs[0] == 1 {
+		t.Errorf("the snapshot didn't replace the first segment: %v", segs)
+	}
+	s.Close()
+
+	s, err = Open(dir, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	checkTitles(t, s, titles...)
+}
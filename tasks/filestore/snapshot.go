@@ -0,0 +1,136 @@
+package filestore
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// A snapshot file is:
+//
+//	magic   "TASKSNP1"
+//	lsn     uvarint, the last record the snapshot includes
+//	ops     a put for every stored value
+//	crc     uint32, Castagnoli checksum of everything before it
+//
+// Snapshots are written to a temporary file and renamed into place, so a
+// crash never leaves a partial one.
+
+var snapMagic = []byte("TASKSNP1")
+
+// encodeSnapshot encodes st, which must not change while it runs.
+func encodeSnapshot(lsn uint64, st *state) []byte {
+	b := append([]byte{}, snapMagic...)
+	b = appendUvarint(b, lsn)
+
+	for _, id := range sortedIDs(st.tasks) {
+		b = appendOp(b, op{kind: opPutTask, id: id, value: st.tasks[id]})
+	}
+	for _, key := range sortedKeys(st.blobs) {
+		b = appendOp(b, op{kind: opPutBlob, key: key, value: st.blobs[key]})
+	}
+	for _, key := range sortedKeys(st.meta) {
+		b = appendOp(b, op{kind: opPutMeta, key: key, value: st.meta[key]})
+	}
+	for _, seq := range sortedIDs(st.outbox) {
+		b = appendOp(b, op{kind: opPutOutbox, id: seq, value: st.outbox[seq]})
+	}
+
+	var sum [4]byte
+	binary.LittleEndian.PutUint32(sum[:], crc32.Checksum(b, crcTable))
+	return append(b, sum[:]...)
+}
+
+func decodeSnapshot(b []byte) (uint64, *state, error) {
+	if len(b) < len(snapMagic)+4 || !bytes.Equal(b[:len(snapMagic)], snapMagic) {
+		return 0, nil, errors.New("not a snapshot")
+	}
+	body, sum := b[:len(b)-4], b[len(b)-4:]
+	if crc32.Checksum(body, crcTable) != binary.LittleEndian.Uint32(sum) {
+		return 0, nil, errors.New("checksum mismatch")
+	}
+	body = body[len(snapMagic):]
+	lsn, n := binary.Uvarint(body)
+	if n <= 0 {
+		return 0, nil, errors.New("bad sequence number")
+	}
+	ops, err := decodeOps(body[n:])
+	if err != nil {
+		return 0, nil, err
+	}
+	st := newState()
+	for _, o := range ops {
+		st.apply(o)
+	}
+	return lsn, st, nil
+}
+
+// writeFileSync writes data to path by way of a temporary file, syncing
+// both the file and its directory.
+func writeFileSync(path string, data []byte) error {
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return err
+	}
+	return syncDir(filepath.Dir(path))
+}
+
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
+
+func readSnapshot(path string) (uint64, *state, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return 0, nil, err
+	}
+	lsn, st, err := decodeSnapshot(b)
+	if err != nil {
+		return 0, nil, fmt.Errorf("%s: %v", filepath.Base(path), err)
+	}
+	return lsn, st, nil
+}
+
+func sortedKeys(m map[string][]byte) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedIDs(m map[int64][]byte) []int64 {
+	ids := make([]int64, 0, len(m))
+	for id := range m {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
@@ -0,0 +1,205 @@
+package filestore
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+
+	"github.com/andyantrim/grpc-example/tasks"
+)
+
+// Each log record is a transaction:
+//
+//	length  uint32, of the payload
+//	crc     uint32, Castagnoli checksum of the payload
+//	payload uvarint LSN followed by the transaction's ops
+//
+// Snapshots hold the same ops, one for every stored value.
+
+const headerSize = 8
+
+// maxRecord guards against reading a huge length from a damaged header.
+const maxRecord = 1 << 30
+
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+const (
+	opPutTask byte = 1 + iota
+	opDeleteTask
+	opPutBlob
+	opPutMeta
+	opPutOutbox
+	opTrimOutbox
+)
+
+// op is one change. Tasks and outbox entries use id, holding the task id or
+// outbox seq, and the others key.
+type op struct {
+	kind  byte
+	id    int64
+	key   string
+	value []byte
+}
+
+// errTorn means a segment ends part way through a record, as a crash while
+// appending leaves it.
+var errTorn = errors.New("torn record at the end of the segment")
+
+func appendOp(b []byte, o op) []byte {
+	b = append(b, o.kind)
+	switch o.kind {
+	case opPutTask, opDeleteTask, opPutOutbox, opTrimOutbox:
+		b = appendUvarint(b, uint64(o.id))
+	default:
+		b = appendBytes(b, []byte(o.key))
+	}
+	if hasValue(o.kind) {
+		b = appendBytes(b, o.value)
+	}
+	return b
+}
+
+// hasValue reports whether ops of kind carry a value.
+func hasValue(kind byte) bool {
+	return kind != opDeleteTask && kind != opTrimOutbox
+}
+
+func appendBytes(b, data []byte) []byte {
+	b = appendUvarint(b, uint64(len(data)))
+	return append(b, data...)
+}
+
+// decodeOps reads ops until b runs out.
+func decodeOps(b []byte) ([]op, error) {
+	var ops []op
+	for len(b) > 0 {
+		o := op{kind: b[0]}
+		b = b[1:]
+		var err error
+		switch o.kind {
+		case opPutTask, opDeleteTask, opPutOutbox, opTrimOutbox:
+			id, n := binary.Uvarint(b)
+			if n <= 0 {
+				return nil, errors.New("bad task id or outbox seq")
+			}
+			o.id, b = int64(id), b[n:]
+		case opPutBlob, opPutMeta:
+			var key []byte
+			if key, b, err = readBytes(b); err != nil {
+				return nil, err
+			}
+			o.key = string(key)
+		default:
+			return nil, fmt.Errorf("unknown op %d", o.kind)
+		}
+		if hasValue(o.kind) {
+			if o.value, b, err = readBytes(b); err != nil {
+				return nil, err
+			}
+		}
+		ops = append(ops, o)
+	}
+	return ops, nil
+}
+
+func readBytes(b []byte) ([]byte, []byte, error) {
+	n, size := binary.Uvarint(b)
+	if size <= 0 || uint64(len(b)-size) < n {
+		return nil, nil, errors.New("value runs past the end of the record")
+	}
+	b = b[size:]
+	return b[:n:n], b[n:], nil
+}
+
+// encodeRecord frames a transaction for the log.
+func encodeRecord(lsn uint64, ops []op) []byte {
+	b := make([]byte, headerSize, 256)
+	b = appendUvarint(b, lsn)
+	for _, o := range ops {
+		b = appendOp(b, o)
+	}
+	binary.LittleEndian.PutUint32(b[0:4], uint32(len(b)-headerSize))
+	binary.LittleEndian.PutUint32(b[4:8], crc32.Checksum(b[headerSize:], crcTable))
+	return b
+}
+
+// record is a transaction read back from the log.
+type record struct {
+	lsn uint64
+	ops []op
+	// end is the offset just past the record in its segment.
+	end int64
+}
+
+// readSegment reads every record in a segment. If the last record is torn
+// it returns the good records before it along with errTorn. Damage
+// anywhere else is reported as tasks.ErrCorrupt, as those records were
+// committed.
+func readSegment(path string) ([]record, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		records []record
+		offset  int64
+		header  [headerSize]byte
+	)
+	r := bufio.NewReader(f)
+	for {
+		if _, err := io.ReadFull(r, header[:]); err == io.EOF {
+			return records, nil
+		} else if err == io.ErrUnexpectedEOF {
+			return records, errTorn
+		} else if err != nil {
+			return records, err
+		}
+		size := binary.LittleEndian.Uint32(header[0:4])
+		end := offset + headerSize + int64(size)
+		if end > fi.Size() {
+			return records, errTorn
+		}
+		if size > maxRecord {
+			return records, fmt.Errorf("%w: record at offset %d claims to be %d bytes", tasks.ErrCorrupt, offset, size)
+		}
+		payload := make([]byte, size)
+		if _, err := io.ReadFull(r, payload); err != nil {
+			return records, err
+		}
+		if crc32.Checksum(payload, crcTable) != binary.LittleEndian.Uint32(header[4:8]) {
+			if end == fi.Size() {
+				// The file grew to fit the last record, but not all of it
+				// was written before the crash.
+				return records, errTorn
+			}
+			return records, fmt.Errorf("%w: record at offset %d doesn't match its checksum", tasks.ErrCorrupt, offset)
+		}
+
+		lsn, n := binary.Uvarint(payload)
+		if n <= 0 {
+			return records, fmt.Errorf("%w: record at offset %d has no sequence number", tasks.ErrCorrupt, offset)
+		}
+		ops, err := decodeOps(payload[n:])
+		if err != nil {
+			// The checksum matched, so this isn't a torn write.
+			return records, fmt.Errorf("%w: record %d: %v", tasks.ErrCorrupt, lsn, err)
+		}
+		offset = end
+		records = append(records, record{lsn: lsn, ops: ops, end: offset})
+	}
+}
+
+func appendUvarint(b []byte, v uint64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	return append(b, buf[:binary.PutUvarint(buf[:], v)]...)
+}
@@ -0,0 +1,59 @@
+package tasks
+
+import "strings"
+
+// matches reports whether t is selected by f. A nil filter matches every
+// task that isn't archived or snoozed.
+func (f *TaskFilter) matches(t *Task) bool {
+	if t.ArchivedAt != nil && !f.GetIncludeArchived() {
+		return false
+	}
+	if t.SnoozedUntil != nil && !f.GetIncludeSnoozed() {
+		return false
+	}
+	if f == nil {
+		return true
+	}
+	if f.Project != "" && t.Project != f.Project {
+		return false
+	}
+	if f.Assignee != "" && t.Assignee != f.Assignee {
+		return false
+	}
+	if f.ParentId != 0 && t.ParentId != f.ParentId {
+		return false
+	}
+	if f.TitleContains != "" && !strings.Contains(strings.ToLower(t.Title), strings.ToLower(f.TitleContains)) {
+		return false
+	}
+	if len(f.Statuses) > 0 {
+		found := false
+		for _, st := range f.Statuses {
+			if t.Status == st {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	for _, want := range f.Labels {
+		found := false
+		for _, l := range t.Labels {
+			if l == want {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	for _, cf := range f.CustomFields {
+		if !cf.matches(t) {
+			return false
+		}
+	}
+	return true
+}
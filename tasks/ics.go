@@ -0,0 +1,121 @@
+package tasks
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// maxEventDays stops a bad DTEND from turning one event into years of
+// holidays.
+const maxEventDays = 366
+
+// parseICS reads the events in an iCalendar file as holidays, one for each
+// day an event covers. Recurring events only count once.
+func parseICS(data []byte) ([]*Holiday, error) {
+	var (
+		holidays   []*Holiday
+		inEvent    bool
+		start, end string
+		summary    string
+	)
+	lines := unfoldICS(data)
+	for n, line := range lines {
+		name, value := splitICSLine(line)
+		switch {
+		case name == "BEGIN" && value == "VEVENT":
+			inEvent = true
+			start, end, summary = "", "", ""
+		case name == "END" && value == "VEVENT":
+			if !inEvent {
+				return nil, fmt.Errorf("line %d: END:VEVENT without BEGIN", n+1)
+			}
+			inEvent = false
+			days, err := eventDays(start, end)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %v", n+1, err)
+			}
+			for _, d := range days {
+				holidays = append(holidays, &Holiday{Date: d, Name: summary})
+			}
+		case !inEvent:
+		case name == "DTSTART":
+			start = value
+		case name == "DTEND":
+			end = value
+		case name == "SUMMARY":
+			summary = unescapeICS(value)
+		}
+	}
+	if inEvent {
+		return nil, fmt.Errorf("unterminated VEVENT")
+	}
+	return holidays, nil
+}
+
+// unfoldICS splits data into content lines, joining lines which were
+// folded onto the next line.
+func unfoldICS(data []byte) []string {
+	var lines []string
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	sc.Buffer(nil, 1<<20)
+	for sc.Scan() {
+		line := strings.TrimRight(sc.Text(), "\r")
+		if len(lines) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// splitICSLine returns a content line's property name, without any
+// parameters, and its value.
+func splitICSLine(line string) (string, string) {
+	i := strings.IndexByte(line, ':')
+	if i < 0 {
+		return "", ""
+	}
+	name := line[:i]
+	if j := strings.IndexByte(name, ';'); j >= 0 {
+		name = name[:j]
+	}
+	return strings.ToUpper(name), line[i+1:]
+}
+
+// eventDays returns the dates covered by an event. All-day events end the
+// day before DTEND; timed events end on DTEND's date unless it's midnight.
+func eventDays(start, end string) ([]string, error) {
+	if len(start) < 8 {
+		return nil, fmt.Errorf("event has no DTSTART")
+	}
+	first, err := time.Parse("20060102", start[:8])
+	if err != nil {
+		return nil, fmt.Errorf("bad DTSTART %q", start)
+	}
+	last := first
+	if len(end) >= 8 {
+		if last, err = time.Parse("20060102", end[:8]); err != nil {
+			return nil, fmt.Errorf("bad DTEND %q", end)
+		}
+		if len(end) == 8 || strings.HasPrefix(end[8:], "T000000") {
+			last = last.AddDate(0, 0, -1)
+		}
+	}
+	if last.Before(first) {
+		last = first
+	}
+
+	var days []string
+	for d := first; !d.After(last) && len(days) < maxEventDays; d = d.AddDate(0, 0, 1) {
+		days = append(days, d.Format(dateLayout))
+	}
+	return days, nil
+}
+
+func unescapeICS(s string) string {
+	return strings.NewReplacer(`\n`, " ", `\N`, " ", `\,`, ",", `\;`, ";", `\\`, `\`).Replace(s)
+}
@@ -0,0 +1,168 @@
+package tasks
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/teamwork/log"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// TaskLink is the href used for #123 references in descriptions. %d is
+// replaced with the task id.
+var TaskLink = "/tasks/%d"
+
+// allowedSchemes are the URL schemes descriptions may link to. Links
+// without a scheme are relative, and always allowed.
+var allowedSchemes = map[string]bool{
+	"http":   true,
+	"https":  true,
+	"mailto": true,
+}
+
+// markdown renders descriptions. goldmark leaves out raw HTML, and
+// safeLinks removes links and images to any scheme not in allowedSchemes,
+// so the output is safe to show in a browser.
+var markdown = goldmark.New(
+	goldmark.WithExtensions(extension.GFM),
+	goldmark.WithParserOptions(
+		parser.WithInlineParsers(util.Prioritized(taskRefParser{}, 500)),
+		parser.WithASTTransformers(util.Prioritized(safeLinks{}, 500)),
+	),
+)
+
+// renderDescription returns t's description as HTML.
+func renderDescription(t *Task) string {
+	if t.Description == "" {
+		return ""
+	}
+	if t.DescriptionFormat != DescriptionFormat_MARKDOWN {
+		paragraphs := strings.Split(strings.TrimSpace(t.Description), "\n\n")
+		var b strings.Builder
+		for _, p := range paragraphs {
+			p = strings.ReplaceAll(html.EscapeString(strings.TrimSpace(p)), "\n", "<br>\n")
+			fmt.Fprintf(&b, "<p>%s</p>\n", p)
+		}
+		return b.String()
+	}
+
+	var buf bytes.Buffer
+	if err := markdown.Convert([]byte(t.Description), &buf); err != nil {
+		log.Error(err, fmt.Sprintf("Failed to render description of task %d", t.Id))
+		return "<p>" + html.EscapeString(t.Description) + "</p>\n"
+	}
+	return buf.String()
+}
+
+// safeLinks replaces links, autolinks and images whose URL isn't allowed
+// with their text.
+type safeLinks struct{}
+
+func (safeLinks) Transform(doc *ast.Document, reader text.Reader, pc parser.Context) {
+	source := reader.Source()
+	var unsafe []ast.Node
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch n := n.(type) {
+		case *ast.Link:
+			if !allowedURL(util.URLEscape(n.Destination, true)) {
+				unsafe = append(unsafe, n)
+			}
+		case *ast.Image:
+			if !allowedURL(util.URLEscape(n.Destination, true)) {
+				unsafe = append(unsafe, n)
+			}
+		case *ast.AutoLink:
+			// Email autolinks are rendered as mailto: links.
+			if n.AutoLinkType == ast.AutoLinkURL && !allowedURL(util.URLEscape(n.URL(source), false)) {
+				unsafe = append(unsafe, n)
+			}
+		}
+		return ast.WalkContinue, nil
+	})
+
+	for _, n := range unsafe {
+		parent := n.Parent()
+		if link, ok := n.(*ast.AutoLink); ok {
+			parent.ReplaceChild(parent, n, ast.NewString(link.Label(source)))
+			continue
+		}
+		for c := n.FirstChild(); c != nil; c = n.FirstChild() {
+			parent.InsertBefore(parent, n, c)
+		}
+		parent.RemoveChild(parent, n)
+	}
+}
+
+// allowedURL reports whether url is relative or has one of allowedSchemes.
+// url must be escaped the way the renderer writes it, with entities
+// resolved. Browsers ignore whitespace and control characters in a scheme,
+// so they're ignored here too.
+func allowedURL(url []byte) bool {
+	i := bytes.IndexAny(url, ":/?#")
+	if i < 0 || url[i] != ':' {
+		return true
+	}
+	scheme := strings.Map(func(r rune) rune {
+		if r <= ' ' || r == 0x7f {
+			return -1
+		}
+		return unicode.ToLower(r)
+	}, string(url[:i]))
+	return allowedSchemes[scheme]
+}
+
+// taskRefParser turns #123 into a link to task 123.
+type taskRefParser struct{}
+
+func (taskRefParser) Trigger() []byte {
+	return []byte{'#'}
+}
+
+func (taskRefParser) Parse(parent ast.Node, block text.Reader, pc parser.Context) ast.Node {
+	if prev := block.PrecendingCharacter(); prev == '&' || isWordRune(prev) {
+		return nil
+	}
+	line, seg := block.PeekLine()
+	n := 1
+	for n < len(line) && line[n] >= '0' && line[n] <= '9' {
+		n++
+	}
+	if n == 1 || n > 19 {
+		return nil
+	}
+	if r, _ := utf8.DecodeRune(line[n:]); isWordRune(r) {
+		return nil
+	}
+	block.Advance(n)
+
+	link := ast.NewLink()
+	link.Destination = []byte(fmt.Sprintf(TaskLink, mustAtoi(line[1:n])))
+	link.SetAttributeString("class", []byte("task-ref"))
+	link.AppendChild(link, ast.NewTextSegment(text.NewSegment(seg.Start, seg.Start+n)))
+	return link
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// mustAtoi parses a run of at most 19 ASCII digits.
+func mustAtoi(b []byte) int64 {
+	var n int64
+	for _, c := range b {
+		n = n*10 + int64(c-'0')
+	}
+	return n
+}
@@ -0,0 +1,51 @@
+package tasks
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMarkdownUnsafe(t *testing.T) {
+	for _, tt := range []struct {
+		name, description string
+		want              []string
+	}{
+		{"autolink", "<javascript:alert(1)>", []string{"javascript:alert(1)"}},
+		{"autolink case", "<JavaScript:alert(1)>", []string{"JavaScript:alert(1)"}},
+		{"inline link", "[x](javascript:alert(1))", []string{"<p>x</p>"}},
+		{"reference link", "[x][r]\n\n[r]: javascript:alert(1)", []string{"<p>x</p>"}},
+		{"entity", "[x](javascript&colon;alert(1))", []string{"<p>x</p>"}},
+		{"vbscript", "[x](vbscript:msgbox)", []string{"<p>x</p>"}},
+		{"image", "![alt](data:text/html;base64,PHNjcmlwdD4=)", []string{"<p>alt</p>"}},
+		{"raw html", "<script>alert(1)</script>\n\n<a href=\"javascript:alert(1)\">x</a>", nil},
+		{"onerror", "<img src=x onerror=alert(1)>", nil},
+	} {
+		got := renderDescription(&Task{Description: tt.description, DescriptionFormat: DescriptionFormat_MARKDOWN})
+		if strings.Contains(strings.ToLower(got), "href=\"javascript") || strings.Contains(got, "<script") ||
+			strings.Contains(got, "<img") || strings.Contains(got, "<a ") {
+			t.Errorf("%s rendered as %q", tt.name, got)
+		}
+		for _, w := range tt.want {
+			if !strings.Contains(got, w) {
+				t.Errorf("%s rendered as %q, want it to contain %q", tt.name, got, w)
+			}
+		}
+	}
+}
+
+func TestMarkdownLinks(t *testing.T) {
+	description := "#12, [docs](https://example.com/a), <http://example.com>, <ann@example.com>, [m](mailto:bob@example.com) and ![i](/img.png)"
+	got := renderDescription(&Task{Description: description, DescriptionFormat: DescriptionFormat_MARKDOWN})
+	for _, w := range []string{
+		`<a href="/tasks/12" class="task-ref">#12</a>`,
+		`<a href="https://example.com/a">docs</a>`,
+		`<a href="http://example.com">http://example.com</a>`,
+		`<a href="mailto:ann@example.com">ann@example.com</a>`,
+		`<a href="mailto:bob@example.com">m</a>`,
+		`<img src="/img.png" alt="i">`,
+	} {
+		if !strings.Contains(got, w) {
+			t.Errorf("rendered %q, want it to contain %q", got, w)
+		}
+	}
+}
@@ -0,0 +1,301 @@
+package tasks
+
+import (
+	"errors"
+	"sort"
+	"sync"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// memoryStore keeps everything in maps, so nothing survives a restart. It's
+// the reference Store implementation. Transactions hold its lock until they
+// finish, which keeps them isolated without copying the maps.
+type memoryStore struct {
+	mu     sync.RWMutex
+	closed bool
+	tasks  map[int64]*Task
+	blobs  map[string][]byte
+	meta   map[string][]byte
+	outbox map[int64][]byte
+}
+
+// NewMemoryStore returns an empty Store which lives in memory.
+func NewMemoryStore() Store {
+	return &memoryStore{
+		tasks:  make(map[int64]*Task),
+		blobs:  make(map[string][]byte),
+		meta:   make(map[string][]byte),
+		outbox: make(map[int64][]byte),
+	}
+}
+
+func (s *memoryStore) Begin(writable bool) (Tx, error) {
+	if writable {
+		s.mu.Lock()
+	} else {
+		s.mu.RLock()
+	}
+	tx := &memoryTx{s: s, writable: writable}
+	if s.closed {
+		tx.unlock()
+		return nil, errors.New("tasks: store is closed")
+	}
+	if writable {
+		tx.tasks = make(map[int64]*Task)
+		tx.blobs = make(map[string][]byte)
+		tx.meta = make(map[string][]byte)
+		tx.outbox = make(map[int64][]byte)
+	}
+	return tx, nil
+}
+
+func (s *memoryStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	return nil
+}
+
+// memoryTx collects a writable transaction's changes, applying them to the
+// store when it commits. A nil task marks a deletion, and outbox entries up
+// to trim are removed before those added.
+type memoryTx struct {
+	s        *memoryStore
+	writable bool
+	done     bool
+
+	tasks  map[int64]*Task
+	blobs  map[string][]byte
+	meta   map[string][]byte
+	outbox map[int64][]byte
+	trim   int64
+}
+
+func (tx *memoryTx) Get(id int64) (*Task, error) {
+	if tx.done {
+		return nil, ErrTxDone
+	}
+	t, ok := tx.tasks[id]
+	if !ok {
+		t, ok = tx.s.tasks[id]
+	}
+	if !ok || t == nil {
+		return nil, ErrNotFound
+	}
+	return proto.Clone(t).(*Task), nil
+}
+
+func (tx *memoryTx) List(after int64, limit int) ([]*Task, error) {
+	if tx.done {
+		return nil, ErrTxDone
+	}
+	var ids []int64
+	for id := range tx.s.tasks {
+		if _, changed := tx.tasks[id]; id > after && !changed {
+			ids = append(ids, id)
+		}
+	}
+	for id, t := range tx.tasks {
+		if id > after && t != nil {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	if limit > 0 && len(ids) > limit {
+		ids = ids[:limit]
+	}
+
+	list := make([]*Task, 0, len(ids))
+	for _, id := range ids {
+		t, err := tx.Get(id)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, t)
+	}
+	return list, nil
+}
+
+func (tx *memoryTx) Put(t *Task) error {
+	if err := tx.check(); err != nil {
+		return err
+	}
+	tx.tasks[t.Id] = proto.Clone(t).(*Task)
+	return nil
+}
+
+func (tx *memoryTx) Delete(id int64) error {
+	if err := tx.check(); err != nil {
+		return err
+	}
+	tx.tasks[id] = nil
+	return nil
+}
+
+func (tx *memoryTx) GetBlob(key string) ([]byte, error) {
+	return tx.getBytes(tx.blobs, tx.s.blobs, key)
+}
+
+func (tx *memoryTx) PutBlob(key string, data []byte) error {
+	if err := tx.check(); err != nil {
+		return err
+	}
+	tx.blobs[key] = append([]byte{}, data...)
+	return nil
+}
+
+func (tx *memoryTx) GetMeta(key string) ([]byte, error) {
+	return tx.getBytes(tx.meta, tx.s.meta, key)
+}
+
+func (tx *memoryTx) PutMeta(key string, value []byte) error {
+	if err := tx.check(); err != nil {
+		return err
+	}
+	tx.meta[key] = append([]byte{}, value...)
+	return nil
+}
+
+func (tx *memoryTx) ListMeta() ([]string, error) {
+	if tx.done {
+		return nil, ErrTxDone
+	}
+	keys := make([]string, 0, len(tx.s.meta))
+	for key := range tx.s.meta {
+		keys = append(keys, key)
+	}
+	for key := range tx.meta {
+		if _, ok := tx.s.meta[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+func (tx *memoryTx) PutOutbox(seq int64, data []byte) error {
+	if err := tx.check(); err != nil {
+		return err
+	}
+	tx.outbox[seq] = append([]byte{}, data...)
+	return nil
+}
+
+func (tx *memoryTx) ListOutbox(after int64, limit int) ([]OutboxEntry, error) {
+	if tx.done {
+		return nil, ErrTxDone
+	}
+	if after < tx.trim {
+		after = tx.trim
+	}
+	var seqs []int64
+	for seq := range tx.s.outbox {
+		if _, added := tx.outbox[seq]; seq > after && !added {
+			seqs = append(seqs, seq)
+		}
+	}
+	for seq := range tx.outbox {
+		if seq > after {
+			seqs = append(seqs, seq)
+		}
+	}
+	sort.Slice(seqs, func(i, j int) bool { return seqs[i] < seqs[j] })
+	if limit > 0 && len(seqs) > limit {
+		seqs = seqs[:limit]
+	}
+
+	list := make([]OutboxEntry, 0, len(seqs))
+	for _, seq := range seqs {
+		data, ok := tx.outbox[seq]
+		if !ok {
+			data = tx.s.outbox[seq]
+		}
+		list = append(list, OutboxEntry{Seq: seq, Data: append([]byte{}, data...)})
+	}
+	return list, nil
+}
+
+func (tx *memoryTx) TrimOutbox(upTo int64) error {
+	if err := tx.check(); err != nil {
+		return err
+	}
+	if upTo > tx.trim {
+		tx.trim = upTo
+	}
+	for seq := range tx.outbox {
+		if seq <= upTo {
+			delete(tx.outbox, seq)
+		}
+	}
+	return nil
+}
+
+func (tx *memoryTx) getBytes(pending, stored map[string][]byte, key string) ([]byte, error) {
+	if tx.done {
+		return nil, ErrTxDone
+	}
+	b, ok := pending[key]
+	if !ok {
+		b, ok = stored[key]
+	}
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return append([]byte{}, b...), nil
+}
+
+func (tx *memoryTx) Commit() error {
+	if tx.done {
+		return ErrTxDone
+	}
+	for id, t := range tx.tasks {
+		if t == nil {
+			delete(tx.s.tasks, id)
+		} else {
+			tx.s.tasks[id] = t
+		}
+	}
+	for k, b := range tx.blobs {
+		tx.s.blobs[k] = b
+	}
+	for k, b := range tx.meta {
+		tx.s.meta[k] = b
+	}
+	for seq := range tx.s.outbox {
+		if seq <= tx.trim {
+			delete(tx.s.outbox, seq)
+		}
+	}
+	for seq, data := range tx.outbox {
+		tx.s.outbox[seq] = data
+	}
+	tx.unlock()
+	return nil
+}
+
+func (tx *memoryTx) Rollback() error {
+	if !tx.done {
+		tx.unlock()
+	}
+	return nil
+}
+
+func (tx *memoryTx) check() error {
+	if tx.done {
+		return ErrTxDone
+	}
+	if !tx.writable {
+		return ErrReadOnly
+	}
+	return nil
+}
+
+func (tx *memoryTx) unlock() {
+	tx.done = true
+	if tx.writable {
+		tx.s.mu.Unlock()
+	} else {
+		tx.s.mu.RUnlock()
+	}
+}
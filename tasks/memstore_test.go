@@ -0,0 +1,16 @@
+package tasks_test
+
+import (
+	"testing"
+
+	"github.com/andyantrim/grpc-example/tasks"
+	"github.com/andyantrim/grpc-example/tasks/storetest"
+)
+
+func TestMemoryStore(t *testing.T) {
+	storetest.Run(t, storetest.Backend{
+		Open: func(t *testing.T) tasks.Store {
+			return tasks.NewMemoryStore()
+		},
+	})
+}
@@ -0,0 +1,135 @@
+package tasks
+
+import (
+	"context"
+	"sort"
+
+	"github.com/teamwork/log"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// Merge folds the source task into the target. The target gains the
+// source's labels, watchers, comments, attachments and subtasks, and
+// dependencies on either task point at the target. The source is cancelled
+// and left as a redirect to the target. Everything is checked before
+// anything changes, so a merge is all or nothing.
+func (s *TaskService) Merge(c context.Context, r *MergeRequest) (_ *Task, err error) {
+	if r.SourceId == r.TargetId {
+		return nil, status.Error(codes.InvalidArgument, "a task can't be merged into itself")
+	}
+
+	s.mu.Lock()
+	defer s.unlock(&err)
+
+	source, ok := s.tasks[r.SourceId]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "task %d not found", r.SourceId)
+	}
+	target, ok := s.tasks[r.TargetId]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "task %d not found", r.TargetId)
+	}
+	for _, t := range []*Task{source, target} {
+		if t.DuplicateOf != 0 {
+			return nil, status.Errorf(codes.FailedPrecondition, "task %d was already merged into task %d", t.Id, t.DuplicateOf)
+		}
+	}
+	if s.ancestorLocked(source.Id, target.Id) || s.ancestorLocked(target.Id, source.Id) {
+		return nil, status.Error(codes.InvalidArgument, "a task can't be merged with its own subtask")
+	}
+
+	// Work out the new dependencies, then make sure they don't go round in
+	// a circle.
+	edges := make(map[int64][]int64)
+	var deps []int64
+	for _, id := range append(append([]int64(nil), target.DependsOn...), source.DependsOn...) {
+		if id != source.Id && id != target.Id && !containsInt(deps, id) {
+			deps = append(deps, id)
+		}
+	}
+	edges[target.Id] = deps
+	edges[source.Id] = nil
+	for _, t := range s.tasks {
+		if t == source || t == target || !containsInt(t.DependsOn, source.Id) {
+			continue
+		}
+		deps := removeInt(t.DependsOn, source.Id)
+		if !containsInt(deps, target.Id) {
+			deps = append(deps, target.Id)
+		}
+		edges[t.Id] = deps
+	}
+	for _, id := range edges[target.Id] {
+		if s.dependsWithLocked(id, target.Id, edges) {
+			return nil, status.Errorf(codes.FailedPrecondition, "merging would make task %d depend on itself", target.Id)
+		}
+	}
+
+	old := proto.Clone(target).(*Task)
+	for _, l := range source.Labels {
+		if !containsString(target.Labels, l) {
+			target.Labels = append(target.Labels, l)
+		}
+	}
+	for _, w := range source.Watchers {
+		if !containsString(target.Watchers, w) {
+			target.Watchers = append(target.Watchers, w)
+		}
+	}
+	for _, comment := range source.Comments {
+		target.Comments = append(target.Comments, proto.Clone(comment).(*Comment))
+	}
+	// Comment ids are handed out in order, so this interleaves the two
+	// conversations by time.
+	sort.SliceStable(target.Comments, func(i, j int) bool {
+		return target.Comments[i].Id < target.Comments[j].Id
+	})
+	for _, a := range source.Attachments {
+		target.Attachments = append(target.Attachments, proto.Clone(a).(*Attachment))
+	}
+	target.DependsOn = edges[target.Id]
+	s.changedLocked(old, target)
+
+	// Go through the dependants in id order so their events are written in
+	// the same order every time.
+	var dependants []int64
+	for id := range edges {
+		if id != target.Id && id != source.Id {
+			dependants = append(dependants, id)
+		}
+	}
+	sort.Slice(dependants, func(i, j int) bool { return dependants[i] < dependants[j] })
+	for _, id := range dependants {
+		t := s.tasks[id]
+		old := proto.Clone(t).(*Task)
+		t.DependsOn = edges[id]
+		s.changedLocked(old, t)
+	}
+	for _, child := range s.childrenLocked(source.Id) {
+		old := proto.Clone(child).(*Task)
+		child.ParentId = target.Id
+		s.changedLocked(old, child)
+	}
+
+	old = proto.Clone(source).(*Task)
+	source.Status = Status_CANCELLED
+	source.DuplicateOf = target.Id
+	source.DependsOn = nil
+	s.changedLocked(old, source)
+
+	log.Infof("Merged task %d into %d", source.Id, target.Id)
+	return proto.Clone(target).(*Task), nil
+}
+
+// ancestorLocked reports whether id is above t in the task tree. s.mu must
+// be held.
+func (s *TaskService) ancestorLocked(id, t int64) bool {
+	for p := s.tasks[t].GetParentId(); p != 0; p = s.tasks[p].GetParentId() {
+		if p == id {
+			return true
+		}
+	}
+	return false
+}
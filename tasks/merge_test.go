@@ -0,0 +1,101 @@
+package tasks
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// Merging moves the source's subtasks and dependants onto the target, in
+// the same order every time, and leaves the source redirecting to it.
+func TestMergeReparents(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	ts, err := NewTaskService(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range []*TaskRequest{
+		{Title: "source", Labels: []string{"bug"}},
+		{Title: "target", Labels: []string{"ui"}},
+		{Title: "first child", ParentId: 1},
+		{Title: "second child", ParentId: 1},
+	} {
+		if _, err := ts.Create(ctx, r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Enough dependants that map order would show.
+	for id := int64(5); id <= 12; id++ {
+		if _, err := ts.Create(ctx, &TaskRequest{Title: fmt.Sprintf("dependant %d", id)}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := ts.Link(ctx, &LinkRequest{TaskId: id, DependsOn: 1}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := ts.Link(ctx, &LinkRequest{TaskId: 6, DependsOn: 2}); err != nil {
+		t.Fatal(err)
+	}
+	var before int
+	err = ReadTx(store, func(tx Tx) error {
+		entries, err := tx.ListOutbox(0, 0)
+		before = len(entries)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	target, err := ts.Merge(ctx, &MergeRequest{SourceId: 1, TargetId: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(target.Labels, []string{"ui", "bug"}) {
+		t.Errorf("merged labels are %v", target.Labels)
+	}
+	for id := int64(3); id <= 12; id++ {
+		task, err := ts.Get(ctx, &GetRequest{Id: id})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if id <= 4 && task.ParentId != 2 {
+			t.Errorf("task %d is under %d after the merge, want 2", id, task.ParentId)
+		}
+		if id > 4 && !reflect.DeepEqual(task.DependsOn, []int64{2}) {
+			t.Errorf("task %d depends on %v after the merge, want [2]", id, task.DependsOn)
+		}
+	}
+	if got, err := ts.Get(ctx, &GetRequest{Id: 1, FollowRedirect: true}); err != nil || got.Id != 2 {
+		t.Errorf("following task 1's redirect got %v, %v", got, err)
+	}
+	source, err := ts.Get(ctx, &GetRequest{Id: 1})
+	if err != nil || source.Status != Status_CANCELLED || source.DuplicateOf != 2 || len(source.DependsOn) != 0 {
+		t.Errorf("source after the merge is %v, %v", source, err)
+	}
+
+	var order []int64
+	err = ReadTx(store, func(tx Tx) error {
+		entries, err := tx.ListOutbox(0, 0)
+		if err != nil {
+			return err
+		}
+		for _, e := range entries[before:] {
+			var ev TaskEvent
+			if err := proto.Unmarshal(e.Data, &ev); err != nil {
+				return err
+			}
+			order = append(order, ev.Task.Id)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int64{2, 5, 6, 7, 8, 9, 10, 11, 12, 3, 4, 1}; !reflect.DeepEqual(order, want) {
+		t.Errorf("merge wrote events for %v, want %v", order, want)
+	}
+}
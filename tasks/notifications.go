@@ -0,0 +1,391 @@
+package tasks
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/teamwork/log"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// maxInbox is how many notifications are kept for each user. Older ones are
+// dropped.
+const maxInbox = 1000
+
+// inboxKey is the meta key listing the users with an inbox. Each inbox is
+// saved under inboxKey, a slash and the user.
+const inboxKey = "inbox"
+
+var mentionRe = regexp.MustCompile(`(?:^|[^\w@])@([A-Za-z0-9_](?:[A-Za-z0-9_.-]*[A-Za-z0-9_])?)`)
+
+// inboxState holds each user's notifications, oldest first. Only the
+// inboxes in changed are saved, and the list of users only when someone
+// gets their first notification.
+type inboxState struct {
+	inbox    map[string][]*Notification
+	nextID   int64
+	changed  map[string]bool
+	newUsers bool
+	// unsent holds the notifications being saved, which are sent to
+	// subscribers once they are.
+	unsent []*Notification
+}
+
+func (is *inboxState) load(tx Tx) error {
+	users := &StoredNames{}
+	if err := getState(tx, inboxKey, users); err != nil {
+		return err
+	}
+	is.inbox = make(map[string][]*Notification)
+	is.nextID = 0
+	for _, user := range users.Names {
+		stored := &StoredInbox{}
+		if err := getState(tx, inboxKey+"/"+user, stored); err != nil {
+			return err
+		}
+		is.inbox[user] = stored.Notifications
+		is.nextID = maxInt64(is.nextID, stored.LastId)
+	}
+	is.changed = make(map[string]bool)
+	is.newUsers = false
+	is.unsent = nil
+	return nil
+}
+
+func (is *inboxState) save(tx Tx) error {
+	for user := range is.changed {
+		stored := &StoredInbox{Notifications: is.inbox[user], LastId: is.nextID}
+		if err := putState(tx, inboxKey+"/"+user, stored); err != nil {
+			return err
+		}
+	}
+	if is.newUsers {
+		users := &StoredNames{}
+		for user := range is.inbox {
+			users.Names = append(users.Names, user)
+		}
+		sort.Strings(users.Names)
+		if err := putState(tx, inboxKey, users); err != nil {
+			return err
+		}
+	}
+	is.changed = make(map[string]bool)
+	is.newUsers = false
+	return nil
+}
+
+type inboxSub struct {
+	notifications chan *Notification
+	overflowed    chan struct{}
+}
+
+type NotificationService struct {
+	UnimplementedNotificationsServer
+
+	tasks *TaskService
+
+	mu   sync.Mutex
+	subs map[string]map[*inboxSub]bool
+}
+
+func NewNotificationService(tasks *TaskService) *NotificationService {
+	s := &NotificationService{
+		tasks: tasks,
+		subs:  make(map[string]map[*inboxSub]bool),
+	}
+	tasks.onChange(s.publish)
+	return s
+}
+
+func (s *NotificationService) WatchTask(c context.Context, r *WatchTaskRequest) (*Task, error) {
+	return s.setWatching(r, true)
+}
+
+func (s *NotificationService) UnwatchTask(c context.Context, r *WatchTaskRequest) (*Task, error) {
+	return s.setWatching(r, false)
+}
+
+func (s *NotificationService) setWatching(r *WatchTaskRequest, watch bool) (_ *Task, err error) {
+	if r.User == "" {
+		return nil, status.Error(codes.InvalidArgument, "user is required")
+	}
+
+	ts := s.tasks
+	ts.mu.Lock()
+	defer ts.unlock(&err)
+
+	t, ok := ts.tasks[r.TaskId]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "task %d not found", r.TaskId)
+	}
+	old := proto.Clone(t).(*Task)
+	t.Watchers = removeString(t.Watchers, r.User)
+	if watch {
+		t.Watchers = append(t.Watchers, r.User)
+	}
+	ts.changedLocked(old, t)
+
+	log.Infof("%s watching task %d: %t", r.User, t.Id, watch)
+	return proto.Clone(t).(*Task), nil
+}
+
+func (s *NotificationService) ListNotifications(c context.Context, r *ListNotificationsRequest) (*ListNotificationsResponse, error) {
+	s.tasks.mu.RLock()
+	defer s.tasks.mu.RUnlock()
+
+	resp := &ListNotificationsResponse{}
+	inbox := s.tasks.inbox.inbox[r.User]
+	for i := len(inbox) - 1; i >= 0; i-- {
+		n := inbox[i]
+		if !n.Read {
+			resp.Unread++
+		}
+		if !r.UnreadOnly || !n.Read {
+			resp.Notifications = append(resp.Notifications, proto.Clone(n).(*Notification))
+		}
+	}
+	return resp, nil
+}
+
+func (s *NotificationService) MarkRead(c context.Context, r *MarkReadRequest) (_ *MarkReadResponse, err error) {
+	ids := make(map[int64]bool, len(r.Ids))
+	for _, id := range r.Ids {
+		ids[id] = true
+	}
+
+	ts := s.tasks
+	ts.mu.Lock()
+	defer ts.unlock(&err)
+
+	resp := &MarkReadResponse{}
+	for _, n := range ts.inbox.inbox[r.User] {
+		if !n.Read && (r.All || ids[n.Id]) {
+			n.Read = true
+			ts.inbox.changed[r.User] = true
+			ts.stateChangedLocked(ts.inbox)
+		}
+		if !n.Read {
+			resp.Unread++
+		}
+	}
+	return resp, nil
+}
+
+// SubscribeNotifications streams the user's new notifications as they
+// arrive.
+func (s *NotificationService) SubscribeNotifications(r *SubscribeNotificationsRequest, stream Notifications_SubscribeNotificationsServer) error {
+	if r.User == "" {
+		return status.Error(codes.InvalidArgument, "user is required")
+	}
+
+	sub := &inboxSub{
+		notifications: make(chan *Notification, eventBuffer),
+		overflowed:    make(chan struct{}),
+	}
+	s.mu.Lock()
+	if s.subs[r.User] == nil {
+		s.subs[r.User] = make(map[*inboxSub]bool)
+	}
+	s.subs[r.User][sub] = true
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.subs[r.User], sub)
+		s.mu.Unlock()
+	}()
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-sub.overflowed:
+			return status.Error(codes.ResourceExhausted, "subscriber fell too far behind")
+		case n := <-sub.notifications:
+			if err := stream.Send(n); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// notifyLocked drops a notification in the inbox of everyone watching a
+// changed task. It's called for each change as it's saved, so the
+// notifications are saved with it. s.mu must be held.
+func (s *TaskService) notifyLocked(old, new *Task) {
+	t := new
+	if t == nil {
+		t = old
+	}
+	if len(t.Watchers) == 0 {
+		return
+	}
+
+	typ := eventType(old, new)
+	var summary string
+	skip := ""
+	switch typ {
+	case TaskEvent_CREATED:
+		summary = fmt.Sprintf("Task %d created: %s", t.Id, t.Title)
+	case TaskEvent_DELETED:
+		summary = fmt.Sprintf("Task %d deleted", t.Id)
+	case TaskEvent_SNOOZED:
+		summary = fmt.Sprintf("Task %d snoozed", t.Id)
+	case TaskEvent_UNSNOOZED:
+		summary = fmt.Sprintf("Task %d is no longer snoozed", t.Id)
+	default:
+		// Who is watching isn't news to the watchers.
+		changed := removeString(changedFields(old, new), "watchers")
+		switch {
+		case len(changed) == 0:
+			return
+		case len(changed) == 1 && changed[0] == "comments" && len(new.Comments) > len(old.Comments):
+			comment := new.Comments[len(new.Comments)-1]
+			summary = fmt.Sprintf("%s commented on task %d", comment.Author, t.Id)
+			skip = comment.Author
+		default:
+			summary = fmt.Sprintf("Task %d updated: %s", t.Id, strings.Join(changed, ", "))
+		}
+	}
+
+	is := s.inbox
+	now := timestamppb.New(s.now())
+	for _, user := range t.Watchers {
+		if user == skip {
+			continue
+		}
+		is.nextID++
+		n := &Notification{
+			Id:      is.nextID,
+			User:    user,
+			TaskId:  t.Id,
+			Type:    typ,
+			Summary: summary,
+			At:      now,
+		}
+		inbox, ok := is.inbox[user]
+		if !ok {
+			is.newUsers = true
+		}
+		inbox = append(inbox, n)
+		if len(inbox) > maxInbox {
+			inbox = inbox[len(inbox)-maxInbox:]
+		}
+		is.inbox[user] = inbox
+		is.changed[user] = true
+		is.unsent = append(is.unsent, n)
+		s.stateChangedLocked(is)
+	}
+}
+
+// publish is registered with the TaskService and sends the notifications
+// saved with a change to their users' subscribers.
+func (s *NotificationService) publish(old, new *Task) {
+	is := s.tasks.inbox
+	if len(is.unsent) == 0 {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, n := range is.unsent {
+		for sub := range s.subs[n.User] {
+			select {
+			case sub.notifications <- proto.Clone(n).(*Notification):
+			default:
+				delete(s.subs[n.User], sub)
+				close(sub.overflowed)
+			}
+		}
+	}
+	is.unsent = nil
+}
+
+// autoWatch adds the assignee and anyone @mentioned on new as watchers,
+// unless they were already involved in old. That way someone who unwatches
+// a task stays unwatched until they are assigned or mentioned again.
+func autoWatch(old, new *Task) {
+	before := map[string]bool{}
+	if old != nil {
+		before = participants(old)
+	}
+	var added []string
+	for user := range participants(new) {
+		if !before[user] && !containsString(new.Watchers, user) {
+			added = append(added, user)
+		}
+	}
+	sort.Strings(added)
+	new.Watchers = append(new.Watchers, added...)
+}
+
+// participants returns the assignee and everyone @mentioned in the
+// description or comments of t.
+func participants(t *Task) map[string]bool {
+	users := make(map[string]bool)
+	if t.Assignee != "" {
+		users[t.Assignee] = true
+	}
+	texts := []string{t.Description}
+	for _, c := range t.Comments {
+		texts = append(texts, c.Body)
+	}
+	for _, text := range texts {
+		for _, m := range mentionRe.FindAllStringSubmatch(text, -1) {
+			users[m[1]] = true
+		}
+	}
+	return users
+}
+
+// changedFields names the fields which differ between old and new, leaving
+// out updated_at.
+func changedFields(old, new *Task) []string {
+	o, n := old.ProtoReflect(), new.ProtoReflect()
+	fields := o.Descriptor().Fields()
+	var names []string
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		if fd.Name() == "updated_at" {
+			continue
+		}
+		a, b := &Task{}, &Task{}
+		if o.Has(fd) {
+			a.ProtoReflect().Set(fd, o.Get(fd))
+		}
+		if n.Has(fd) {
+			b.ProtoReflect().Set(fd, n.Get(fd))
+		}
+		if !proto.Equal(a, b) {
+			names = append(names, string(fd.Name()))
+		}
+	}
+	return names
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func removeString(list []string, s string) []string {
+	out := list[:0]
+	for _, v := range list {
+		if v != s {
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,97 @@
+package tasks
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// Watchers get a notification in their inbox for each change, and
+// subscribers are sent it once it's saved.
+func TestInboxDelivery(t *testing.T) {
+	ctx := context.Background()
+	ts, err := NewTaskService(NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ns := NewNotificationService(ts)
+	sub := &inboxSub{notifications: make(chan *Notification, 10), overflowed: make(chan struct{})}
+	ns.subs["bob"] = map[*inboxSub]bool{sub: true}
+
+	_, err = ts.Create(ctx, &TaskRequest{Title: "ship", Assignee: "ann", Description: "@bob can you review?"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ts.AddComment(ctx, &AddCommentRequest{TaskId: 1, Author: "ann", Body: "done"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ns.UnwatchTask(ctx, &WatchTaskRequest{TaskId: 1, User: "bob"}); err != nil {
+		t.Fatal(err)
+	}
+	_, err = ts.Update(ctx, &UpdateRequest{
+		Id:         1,
+		Task:       &Task{Title: "ship it"},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"title"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	summaries := func(user string) []string {
+		t.Helper()
+		resp, err := ns.ListNotifications(ctx, &ListNotificationsRequest{User: user})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, n := range resp.Notifications {
+			got = append(got, n.Summary)
+		}
+		return got
+	}
+	want := map[string][]string{
+		// Newest first. Ann wrote the comment, so isn't told of it.
+		"ann": {"Task 1 updated: title", "Task 1 created: ship"},
+		"bob": {"ann commented on task 1", "Task 1 created: ship"},
+	}
+	for user, w := range want {
+		if got := summaries(user); !equalStrings(got, w) {
+			t.Errorf("%s's inbox is %q, want %q", user, got, w)
+		}
+	}
+
+	var sent []string
+	for len(sub.notifications) > 0 {
+		sent = append(sent, (<-sub.notifications).Summary)
+	}
+	if w := []string{"Task 1 created: ship", "ann commented on task 1"}; !equalStrings(sent, w) {
+		t.Errorf("bob was sent %q, want %q", sent, w)
+	}
+
+	read, err := ns.MarkRead(ctx, &MarkReadRequest{User: "bob", All: true})
+	if err != nil || read.Unread != 0 {
+		t.Errorf("marking everything read left %v, %v", read, err)
+	}
+	ts = restart(t, ts)
+	ns = NewNotificationService(ts)
+	resp, err := ns.ListNotifications(ctx, &ListNotificationsRequest{User: "bob", UnreadOnly: true})
+	if err != nil || len(resp.Notifications) != 0 {
+		t.Errorf("bob's unread notifications after a restart are %v, %v", resp, err)
+	}
+	if got := summaries("ann"); !equalStrings(got, want["ann"]) {
+		t.Errorf("ann's inbox after a restart is %q", got)
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,76 @@
+package tasks
+
+import (
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// orderBy returns a less function for a ListRequest order_by value. Tasks
+// which compare equal keep their id order.
+func orderBy(field string) (func(a, b *Task) bool, error) {
+	desc := strings.HasPrefix(field, "-")
+	field = strings.TrimPrefix(field, "-")
+
+	var cmp func(a, b *Task) int
+	switch field {
+	case "id":
+		cmp = func(a, b *Task) int { return compareInts(a.Id, b.Id) }
+	case "title":
+		cmp = func(a, b *Task) int { return strings.Compare(a.Title, b.Title) }
+	case "status":
+		cmp = func(a, b *Task) int { return compareInts(int64(a.Status), int64(b.Status)) }
+	case "priority":
+		cmp = func(a, b *Task) int { return compareInts(int64(a.Priority), int64(b.Priority)) }
+	case "rank":
+		cmp = func(a, b *Task) int { return strings.Compare(a.Rank, b.Rank) }
+	case "created_at":
+		cmp = func(a, b *Task) int {
+			return compareInts(a.CreatedAt.AsTime().UnixNano(), b.CreatedAt.AsTime().UnixNano())
+		}
+	case "updated_at":
+		cmp = func(a, b *Task) int {
+			return compareInts(a.UpdatedAt.AsTime().UnixNano(), b.UpdatedAt.AsTime().UnixNano())
+		}
+	default:
+		name := strings.TrimPrefix(field, customFieldPrefix)
+		if name == field || name == "" {
+			return nil, status.Errorf(codes.InvalidArgument, "can't order by %q", field)
+		}
+		// Tasks without the field, or with a value of another type, always
+		// sort last.
+		return func(a, b *Task) bool {
+			av, aok := a.CustomFields[name]
+			bv, bok := b.CustomFields[name]
+			if !aok || !bok {
+				return aok && !bok
+			}
+			c, ok := compareValues(av, bv)
+			if !ok {
+				return false
+			}
+			if desc {
+				return c > 0
+			}
+			return c < 0
+		}, nil
+	}
+
+	return func(a, b *Task) bool {
+		if desc {
+			return cmp(a, b) > 0
+		}
+		return cmp(a, b) < 0
+	}, nil
+}
+
+func compareInts(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,270 @@
+package tasks
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/teamwork/log"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// The TaskService works on tasks in memory. Every change it makes goes
+// through changedLocked, which queues the task to be saved, and is saved in
+// one store transaction when the change is finished, along with an event
+// in the outbox for each task changed and any states the change touched.
+// Watchers only hear of it once it's saved. If a change fails part way
+// through, or can't be saved, the tasks and states are reloaded from the
+// store so none of it is kept.
+
+// sequencesKey is the meta key holding the last ids handed out.
+const sequencesKey = "sequences"
+
+// loadPage is how many tasks are read from the store at a time.
+const loadPage = 1000
+
+type sequences struct {
+	Task       int64 `json:"task"`
+	Comment    int64 `json:"comment"`
+	Checklist  int64 `json:"checklist"`
+	Attachment int64 `json:"attachment"`
+	Event      int64 `json:"event"`
+}
+
+// loadLocked replaces the tasks and states in memory with those in the
+// store and throws away unsaved changes. s.mu must be held.
+func (s *TaskService) loadLocked() error {
+	loaded := make(map[int64]*Task)
+	corrupt := make(map[int64]*Task)
+	var seq sequences
+	err := ReadTx(s.store, func(tx Tx) error {
+		b, err := tx.GetMeta(sequencesKey)
+		switch {
+		case errors.Is(err, ErrNotFound):
+		case err != nil:
+			return err
+		default:
+			if err := json.Unmarshal(b, &seq); err != nil {
+				return fmt.Errorf("reading %s: %v", sequencesKey, err)
+			}
+		}
+
+		for after := int64(0); ; {
+			page, err := tx.List(after, loadPage)
+			if err != nil {
+				return err
+			}
+			for _, t := range page {
+				if t.Sealed != nil {
+					log.Error(fmt.Errorf("task %d: %w", t.Id, ErrCorrupt), "Leaving out a task which can't be read")
+					corrupt[t.Id] = t
+				} else {
+					loaded[t.Id] = t
+				}
+				after = t.Id
+			}
+			if len(page) < loadPage {
+				break
+			}
+		}
+		for _, st := range s.states {
+			if err := st.load(tx); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// The sequences should already cover every id, but a store filled in
+	// some other way might not have them.
+	for id := range corrupt {
+		seq.Task = maxInt64(seq.Task, id)
+	}
+	for _, t := range loaded {
+		seq.Task = maxInt64(seq.Task, t.Id)
+		for _, c := range t.Comments {
+			seq.Comment = maxInt64(seq.Comment, c.Id)
+		}
+		for _, item := range t.Checklist {
+			seq.Checklist = maxInt64(seq.Checklist, item.Id)
+		}
+		for _, a := range t.Attachments {
+			seq.Attachment = maxInt64(seq.Attachment, a.Id)
+		}
+	}
+
+	s.tasks = loaded
+	s.corrupt = corrupt
+	s.nextID = seq.Task
+	s.nextCommentID = seq.Comment
+	s.nextChecklistID = seq.Checklist
+	s.nextAttachID = seq.Attachment
+	s.nextEventID = seq.Event
+	s.pending = make(map[int64]*Task)
+	s.pendingBlobs = make(map[string][]byte)
+	s.changes = nil
+	s.dirty = make(map[state]bool)
+
+	// Only the stats' history is saved, as their counts follow from the
+	// tasks.
+	s.grams = newTrigramIndex()
+	s.children = make(map[int64]map[int64]bool)
+	for _, t := range loaded {
+		s.stats.count(t, 1, "")
+		s.grams.add(t)
+		s.addChildLocked(t)
+	}
+	s.snoozesChangedLocked()
+	return nil
+}
+
+// Reload replaces the tasks and everything kept alongside them, such as the
+// stats and notifications, with what's in the store. It's for stores which
+// others can change, such as a replica which has just become the leader of
+// its cluster.
+func (s *TaskService) Reload() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.loadLocked()
+}
+
+// commitLocked saves the changes queued since the last commit and tells
+// the watchers about them. If they can't be saved, they're thrown away.
+// s.mu must be held.
+func (s *TaskService) commitLocked() error {
+	if !s.uncommittedLocked() {
+		return nil
+	}
+	if err := s.checkWIPLocked(); err != nil {
+		s.rollbackLocked()
+		return err
+	}
+	day := s.now().UTC().Format(dateLayout)
+	for _, c := range s.changes {
+		s.notifyLocked(c.old, c.new)
+		s.stats.apply(c.old, c.new, day)
+	}
+	if len(s.changes) > 0 {
+		s.stateChangedLocked(s.stats)
+	}
+	events := make([][]byte, len(s.changes))
+	for i, c := range s.changes {
+		ev := newTaskEvent(c.old, c.new, s.now())
+		ev.Seq = s.nextEventID + int64(i) + 1
+		b, err := proto.Marshal(ev)
+		if err != nil {
+			return err
+		}
+		events[i] = b
+	}
+	seq, err := json.Marshal(sequences{
+		Task:       s.nextID,
+		Comment:    s.nextCommentID,
+		Checklist:  s.nextChecklistID,
+		Attachment: s.nextAttachID,
+		Event:      s.nextEventID + int64(len(events)),
+	})
+	if err != nil {
+		return err
+	}
+
+	err = WriteTx(s.store, func(tx Tx) error {
+		for key, data := range s.pendingBlobs {
+			if err := tx.PutBlob(key, data); err != nil {
+				return err
+			}
+		}
+		for id, t := range s.pending {
+			if t == nil {
+				err = tx.Delete(id)
+			} else {
+				err = tx.Put(t)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		for i, b := range events {
+			if err := tx.PutOutbox(s.nextEventID+int64(i)+1, b); err != nil {
+				return err
+			}
+		}
+		for st := range s.dirty {
+			if err := st.save(tx); err != nil {
+				return err
+			}
+		}
+		return tx.PutMeta(sequencesKey, seq)
+	})
+	if err != nil {
+		log.Error(err, "Failed to save task changes")
+		s.rollbackLocked()
+		return status.Errorf(codes.Unavailable, "saving changes: %v", err)
+	}
+	s.pending = make(map[int64]*Task)
+	s.pendingBlobs = make(map[string][]byte)
+	s.dirty = make(map[state]bool)
+	s.nextEventID += int64(len(events))
+	if len(events) > 0 {
+		s.outboxChanged()
+	}
+	changes := s.changes
+	s.changes = nil
+	for _, c := range changes {
+		for _, fn := range s.watchers {
+			fn(c.old, c.new)
+		}
+	}
+	s.inbox.unsent = nil
+	return nil
+}
+
+// rollbackLocked throws away the changes queued since the last commit.
+// s.mu must be held.
+//
+// It does so by reloading everything from the store, which reads every
+// task and rebuilds the indexes, so it costs as much as a restart. That
+// only happens when something was changed before the failure: requests
+// which fail their checks up front, as most do, leave nothing to undo.
+// Changes that fail part way, such as an Execute with a bad operation, a
+// WIP limit being broken or the store failing, are rare enough that
+// keeping an undo log of every task and state touched isn't worth it.
+func (s *TaskService) rollbackLocked() {
+	if !s.uncommittedLocked() {
+		return
+	}
+	if err := s.loadLocked(); err != nil {
+		// The tasks in memory may now be ahead of the store. There's no
+		// better copy to go back to, so carry on with them.
+		log.Error(err, "Failed to reload tasks after a failed change")
+	}
+}
+
+// uncommittedLocked reports whether there are changes which haven't been
+// committed. s.mu must be held.
+func (s *TaskService) uncommittedLocked() bool {
+	return len(s.pending) > 0 || len(s.pendingBlobs) > 0 || len(s.dirty) > 0
+}
+
+// unlock finishes a change started by locking s.mu, saving it if *err is
+// nil and throwing it away otherwise. If saving fails, *err says why.
+func (s *TaskService) unlock(err *error) {
+	if *err != nil {
+		s.rollbackLocked()
+	} else {
+		*err = s.commitLocked()
+	}
+	s.mu.Unlock()
+}
+
+func maxInt64(a, b int64) int64 {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,260 @@
+package tasks
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// restart returns a new TaskService on the same store as ts, as after the
+// server restarts.
+func restart(t *testing.T, ts *TaskService) *TaskService {
+	t.Helper()
+	next, err := NewTaskService(ts.store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return next
+}
+
+func TestStateSurvivesRestart(t *testing.T) {
+	ctx := context.Background()
+	ts, err := NewTaskService(NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sev := &CustomFieldDefinition{Project: "p", Name: "sev", Type: CustomFieldDefinition_NUMBER}
+	if _, err := NewCustomFieldService(ts).DefineField(ctx, sev); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewCalendarService(ts).SetCalendar(ctx, &Calendar{Project: "p", TimeZone: "Europe/London"}); err != nil {
+		t.Fatal(err)
+	}
+	board, err := NewBoardService(ts).CreateBoard(ctx, &Board{Name: "b", Project: "p", Columns: []*Column{{Name: "todo"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	view, err := NewViewService(ts).SaveView(ctx, &View{Name: "v", Owner: "ann", Project: "p"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewTemplateService(ts).SaveTemplate(ctx, &SaveTemplateRequest{Name: "t", Root: &TemplateTask{Title: "{{x}}"}}); err != nil {
+		t.Fatal(err)
+	}
+	archive := &ArchiveSettings{Project: "p", After: durationpb.New(3600e9)}
+	if _, err := NewArchiveService(ts).SetArchiveSettings(ctx, archive); err != nil {
+		t.Fatal(err)
+	}
+	NewNotificationService(ts)
+	created, err := ts.Create(ctx, &TaskRequest{
+		Title:        "crash",
+		Project:      "p",
+		Assignee:     "ann",
+		CustomFields: map[string]*CustomValue{"sev": {Value: &CustomValue_NumberValue{NumberValue: 1}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	update := &UpdateRequest{
+		Id:         created.Id,
+		Task:       &Task{Title: "crash on save"},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"title"}},
+	}
+	if _, err := ts.Update(ctx, update); err != nil {
+		t.Fatal(err)
+	}
+
+	ts = restart(t, ts)
+
+	update.Task.CustomFields = map[string]*CustomValue{"sev": {Value: &CustomValue_NumberValue{NumberValue: 2}}}
+	update.UpdateMask.Paths = []string{"custom_fields.sev"}
+	if _, err := ts.Update(ctx, update); err != nil {
+		t.Fatalf("updating a custom field after a restart: %v", err)
+	}
+	cal, err := NewCalendarService(ts).GetCalendar(ctx, &GetCalendarRequest{Project: "p"})
+	if err != nil || cal.TimeZone != "Europe/London" {
+		t.Errorf("calendar after a restart is %v, %v", cal, err)
+	}
+	if _, err := NewBoardService(ts).GetBoard(ctx, &GetBoardRequest{Id: board.Id}); err != nil {
+		t.Errorf("board after a restart: %v", err)
+	}
+	views, err := NewViewService(ts).ListViews(ctx, &ListViewsRequest{User: "ann"})
+	if err != nil || len(views.Views) != 1 || views.Views[0].Id != view.Id {
+		t.Errorf("views after a restart are %v, %v", views, err)
+	}
+	next, err := NewViewService(ts).SaveView(ctx, &View{Name: "w", Owner: "ann"})
+	if err != nil || next.Id <= view.Id {
+		t.Errorf("view saved after a restart is %v, %v; ids must not be reused", next, err)
+	}
+	if _, err := NewTemplateService(ts).CreateFromTemplate(ctx, &CreateFromTemplateRequest{Name: "t", Variables: map[string]string{"x": "y"}}); err != nil {
+		t.Errorf("template after a restart: %v", err)
+	}
+	settings, err := NewArchiveService(ts).GetArchiveSettings(ctx, &GetArchiveSettingsRequest{Project: "p"})
+	if err != nil || settings.After.AsDuration() != archive.After.AsDuration() {
+		t.Errorf("archive settings after a restart are %v, %v", settings, err)
+	}
+	// Two notifications from before the restart and one from after it,
+	// which mustn't reuse their ids.
+	inbox, err := NewNotificationService(ts).ListNotifications(ctx, &ListNotificationsRequest{User: "ann"})
+	if err != nil || len(inbox.Notifications) != 3 || inbox.Notifications[0].Id != 3 {
+		t.Errorf("inbox after a restart is %v, %v", inbox, err)
+	}
+}
+
+// A change which fails takes any state it touched with it.
+func TestFailedChangeDropsState(t *testing.T) {
+	ctx := context.Background()
+	ts, err := NewTaskService(NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ts.Create(ctx, &TaskRequest{Title: "a", Project: "p"}); err != nil {
+		t.Fatal(err)
+	}
+	required := &CustomFieldDefinition{Project: "p", Name: "sev", Type: CustomFieldDefinition_NUMBER, Required: true}
+	if _, err := NewCustomFieldService(ts).DefineField(ctx, required); err == nil {
+		t.Fatal("defined a required field which a task doesn't have")
+	}
+	fields, err := NewCustomFieldService(ts).ListFields(ctx, &ListFieldsRequest{Project: "p"})
+	if err != nil || len(fields.Fields) != 0 {
+		t.Errorf("fields after a failed definition are %v, %v", fields, err)
+	}
+}
+
+func TestTimerSurvivesRestart(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+
+	ts, err := NewTaskService(NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts.now = clock
+	created, err := ts.Create(ctx, &TaskRequest{Title: "bill me"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	timers := NewTimeService(ts)
+	if _, err := timers.StartTimer(ctx, &StartTimerRequest{User: "ann", TaskId: created.Id}); err != nil {
+		t.Fatal(err)
+	}
+	logged := &LogWorkRequest{User: "bob", TaskId: created.Id, Duration: durationpb.New(time.Hour)}
+	if _, err := timers.LogWork(ctx, logged); err != nil {
+		t.Fatal(err)
+	}
+
+	ts = restart(t, ts)
+	ts.now = clock
+	now = now.Add(2 * time.Hour)
+	timers = NewTimeService(ts)
+	wl, err := timers.StopTimer(ctx, &StopTimerRequest{User: "ann"})
+	if err != nil {
+		t.Fatalf("stopping a timer started before a restart: %v", err)
+	}
+	if wl.Duration.AsDuration() != 2*time.Hour || wl.Id != 2 {
+		t.Errorf("timer logged %v", wl)
+	}
+
+	ts = restart(t, ts)
+	task, err := ts.Get(ctx, &GetRequest{Id: created.Id})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task.Logged.AsDuration() != 3*time.Hour {
+		t.Errorf("task has %s logged, want 3h", task.Logged.AsDuration())
+	}
+	report, err := NewTimeService(ts).TimeReport(ctx, &TimeReportRequest{
+		From: timestamppb.New(now.Add(-24 * time.Hour)),
+		To:   timestamppb.New(now.Add(24 * time.Hour)),
+	})
+	if err != nil || report.Total.AsDuration() != 3*time.Hour || len(report.Rows) != 2 {
+		t.Errorf("report after a restart is %v, %v", report, err)
+	}
+}
+
+// Deleting a task drops the timers running on it and its subtasks, so their
+// users can start new ones.
+func TestDeleteDropsTimers(t *testing.T) {
+	ctx := context.Background()
+	ts, err := NewTaskService(NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range []*TaskRequest{{Title: "parent"}, {Title: "child", ParentId: 1}, {Title: "other"}} {
+		if _, err := ts.Create(ctx, r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	timers := NewTimeService(ts)
+	for user, id := range map[string]int64{"ann": 2, "bob": 3} {
+		if _, err := timers.StartTimer(ctx, &StartTimerRequest{User: user, TaskId: id}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := ts.Delete(ctx, &DeleteRequest{Id: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	ts = restart(t, ts)
+	timers = NewTimeService(ts)
+	if _, err := timers.StopTimer(ctx, &StopTimerRequest{User: "ann"}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("stopping a timer on a deleted task returned %v", err)
+	}
+	if _, err := timers.StartTimer(ctx, &StartTimerRequest{User: "ann", TaskId: 3}); err != nil {
+		t.Errorf("starting a timer after the last one's task was deleted: %v", err)
+	}
+	if _, err := timers.StopTimer(ctx, &StopTimerRequest{User: "bob"}); err != nil {
+		t.Errorf("stopping a timer on a task which wasn't deleted: %v", err)
+	}
+}
+
+func TestStatsSurviveRestart(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+
+	ts, err := NewTaskService(NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts.now = clock
+	for _, title := range []string{"a", "b"} {
+		if _, err := ts.Create(ctx, &TaskRequest{Title: title, Project: "p"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	now = now.AddDate(0, 0, 1)
+	done := &UpdateRequest{
+		Id:         1,
+		Task:       &Task{Status: Status_DONE},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"status"}},
+	}
+	if _, err := ts.Update(ctx, done); err != nil {
+		t.Fatal(err)
+	}
+
+	ts = restart(t, ts)
+	ts.now = clock
+	stats, err := NewStatsService(ts).GetStats(ctx, &GetStatsRequest{
+		Project: "p",
+		From:    timestamppb.New(now.AddDate(0, 0, -1)),
+		To:      timestamppb.New(now),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.ByStatus["DONE"] != 1 || stats.ByStatus["TODO"] != 1 {
+		t.Errorf("counts after a restart are %v", stats.ByStatus)
+	}
+	if len(stats.Days) != 2 || stats.Days[0].Open != 2 || stats.Days[1].Open != 1 {
+		t.Errorf("history after a restart is %v", stats.Days)
+	}
+}
@@ -0,0 +1,253 @@
+package raftstore
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// LeaderKey is the trailer naming the leader's address when a node turns a
+// call away.
+const LeaderKey = "tasks-leader"
+
+// forwardedKey marks a call forwarded by another node. It isn't forwarded
+// again, so calls can't go round in circles while nodes disagree about
+// the leader.
+const forwardedKey = "tasks-forwarded"
+
+// raftMethods is the prefix of the Raft service's methods, which every
+// node serves itself.
+const raftMethods = "/task.Raft/"
+
+// ServerOptions returns the options for the node's gRPC server, which
+// route calls to the leader and accept large raft messages.
+func (n *Node) ServerOptions() []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.UnaryInterceptor(n.UnaryInterceptor),
+		grpc.StreamInterceptor(n.StreamInterceptor),
+		grpc.MaxRecvMsgSize(maxRaftMessage),
+	}
+}
+
+// UnaryInterceptor serves calls on the leader, and forwards them to it, or
+// returns Unavailable with its address in the LeaderKey trailer, on other
+// nodes.
+func (n *Node) UnaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if strings.HasPrefix(info.FullMethod, raftMethods) {
+		return handler(ctx, req)
+	}
+	addr, err := n.route(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if addr == "" {
+		return handler(ctx, req)
+	}
+	return n.forwarder.unary(ctx, addr, info.FullMethod, req)
+}
+
+// StreamInterceptor is UnaryInterceptor for streaming calls.
+func (n *Node) StreamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if strings.HasPrefix(info.FullMethod, raftMethods) {
+		return handler(srv, ss)
+	}
+	addr, err := n.route(ss.Context())
+	if err != nil {
+		return err
+	}
+	if addr == "" {
+		return handler(srv, ss)
+	}
+	return n.forwarder.stream(ss, addr, info.FullMethod)
+}
+
+// route decides where a call is served. It returns "" to serve it here, or
+// the address of the leader to forward it to.
+func (n *Node) route(ctx context.Context) (string, error) {
+	if n.Serving() {
+		if !n.cfg.LinearizableReads {
+			return "", nil
+		}
+		if _, ok := ctx.Deadline(); !ok {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, commitTimeout)
+			defer cancel()
+		}
+		if err := n.ReadIndex(ctx); err != nil {
+			return "", status.Errorf(codes.Unavailable, "checking this node is still the leader: %v", err)
+		}
+		return "", nil
+	}
+
+	id, addr := n.Leader()
+	md, _ := metadata.FromIncomingContext(ctx)
+	if id == 0 || id == n.cfg.ID || n.cfg.Redirect || len(md.Get(forwardedKey)) > 0 {
+		if addr != "" && id != n.cfg.ID {
+			grpc.SetTrailer(ctx, metadata.Pairs(LeaderKey, addr))
+		}
+		return "", status.Error(codes.Unavailable, n.notLeader().Error())
+	}
+	return addr, nil
+}
+
+// forwarder passes calls on to the leader, keeping a connection to each
+// node it has forwarded to.
+type forwarder struct {
+	mu    sync.Mutex
+	conns map[string]*grpc.ClientConn
+}
+
+func newForwarder(peers map[uint64]string) *forwarder {
+	return &forwarder{conns: make(map[string]*grpc.ClientConn, len(peers))}
+}
+
+func (f *forwarder) conn(addr string) (*grpc.ClientConn, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if conn, ok := f.conns[addr]; ok {
+		return conn, nil
+	}
+	conn, err := grpc.Dial(addr, grpc.WithInsecure(), grpc.WithDefaultCallOptions(
+		grpc.MaxCallSendMsgSize(maxRaftMessage),
+		grpc.MaxCallRecvMsgSize(maxRaftMessage),
+	))
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "connecting to the leader at %s: %v", addr, err)
+	}
+	f.conns[addr] = conn
+	return conn, nil
+}
+
+func (f *forwarder) close() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for addr, conn := range f.conns {
+		conn.Close()
+		delete(f.conns, addr)
+	}
+}
+
+// outgoing returns a context for calling the leader with the caller's
+// metadata.
+func outgoing(ctx context.Context) context.Context {
+	md, _ := metadata.FromIncomingContext(ctx)
+	md = md.Copy()
+	md.Set(forwardedKey, "1")
+	return metadata.NewOutgoingContext(ctx, md)
+}
+
+func (f *forwarder) unary(ctx context.Context, addr, method string, req interface{}) (interface{}, error) {
+	desc, err := findMethod(method)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := f.conn(addr)
+	if err != nil {
+		return nil, err
+	}
+	resp := newMessage(desc.Output())
+	var header, trailer metadata.MD
+	err = conn.Invoke(outgoing(ctx), method, req, resp, grpc.Header(&header), grpc.Trailer(&trailer))
+	if len(header) > 0 {
+		grpc.SetHeader(ctx, header)
+	}
+	if len(trailer) > 0 {
+		grpc.SetTrailer(ctx, trailer)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (f *forwarder) stream(ss grpc.ServerStream, addr, method string) error {
+	desc, err := findMethod(method)
+	if err != nil {
+		return err
+	}
+	conn, err := f.conn(addr)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithCancel(outgoing(ss.Context()))
+	defer cancel()
+	cs, err := conn.NewStream(ctx, &grpc.StreamDesc{
+		ServerStreams: desc.IsStreamingServer(),
+		ClientStreams: desc.IsStreamingClient(),
+	}, method)
+	if err != nil {
+		return err
+	}
+
+	// Requests are passed on until the caller has sent them all. If
+	// sending fails, the leader's reason comes from RecvMsg below.
+	go func() {
+		for {
+			req := newMessage(desc.Input())
+			if err := ss.RecvMsg(req); err == io.EOF {
+				cs.CloseSend()
+				return
+			} else if err != nil {
+				cancel()
+				return
+			}
+			if err := cs.SendMsg(req); err != nil {
+				return
+			}
+		}
+	}()
+
+	if header, err := cs.Header(); err == nil && len(header) > 0 {
+		if err := ss.SendHeader(header); err != nil {
+			return err
+		}
+	}
+	for {
+		resp := newMessage(desc.Output())
+		err := cs.RecvMsg(resp)
+		if err != nil {
+			ss.SetTrailer(cs.Trailer())
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		if err := ss.SendMsg(resp); err != nil {
+			return err
+		}
+	}
+}
+
+// findMethod looks up a method from its full gRPC name, such as
+// "/task.Tasks/Create".
+func findMethod(method string) (protoreflect.MethodDescriptor, error) {
+	parts := strings.Split(strings.TrimPrefix(method, "/"), "/")
+	if len(parts) == 2 {
+		d, err := protoregistry.GlobalFiles.FindDescriptorByName(protoreflect.FullName(parts[0]))
+		if sd, ok := d.(protoreflect.ServiceDescriptor); err == nil && ok {
+			if md := sd.Methods().ByName(protoreflect.Name(parts[1])); md != nil {
+				return md, nil
+			}
+		}
+	}
+	return nil, status.Errorf(codes.Unimplemented, "%s can't be forwarded to the leader", method)
+}
+
+func newMessage(d protoreflect.MessageDescriptor) proto.Message {
+	mt, err := protoregistry.GlobalTypes.FindMessageByName(d.FullName())
+	if err != nil {
+		// Every message of a registered service is registered too.
+		panic(fmt.Sprintf("raftstore: message %s isn't registered", d.FullName()))
+	}
+	return mt.New().Interface()
+}
@@ -0,0 +1,492 @@
+package raftstore
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/andyantrim/grpc-example/tasks"
+	"go.etcd.io/etcd/raft/v3"
+	"go.etcd.io/etcd/raft/v3/raftpb"
+	"google.golang.org/protobuf/proto"
+)
+
+// The raft log is kept in the local store's meta values, beside the tasks
+// it produced, so a node's log and what it has applied from it are saved
+// in the same transaction:
+//
+//	raft/hardstate    the node's term, vote and commit index
+//	raft/snapshot     the snapshot the log carries on from
+//	raft/entry/<n>    log entry n, from raft/first up
+//	raft/first        the index of the first entry not emptied
+//	raft/last         the index of the last entry
+//	raft/applied      the index of the last entry applied to the store
+//
+// Entries past raft/last were cut off when the log was overwritten, and
+// those up to the snapshot's index are only kept for followers catching
+// up, so both are ignored when loading the log. Every node starts from the same snapshot at index 1, which
+// holds only the cluster's members.
+//
+// Once a node has applied Config.SnapshotEntries entries since its last
+// snapshot, it takes another, and the entries before it are emptied. As
+// the store holds everything the entries did, a snapshot's data is only
+// needed to bring a follower which has fallen too far behind up to date.
+// It's the entries' ops folded into the fewest that leave a store the
+// same, so it's about the size of the tasks and everything kept with
+// them.
+const (
+	// raftPrefix starts every key the log is kept under. They're left out
+	// of the store's ListMeta.
+	raftPrefix   = "raft/"
+	hardStateKey = "raft/hardstate"
+	snapshotKey  = "raft/snapshot"
+	entryPrefix  = "raft/entry/"
+	firstKey     = "raft/first"
+	lastKey      = "raft/last"
+	appliedKey   = "raft/applied"
+)
+
+// bootstrapIndex is the index of the snapshot every node starts from.
+const bootstrapIndex = 1
+
+func entryKey(index uint64) string {
+	return entryPrefix + strconv.FormatUint(index, 10)
+}
+
+// loadLog reads the raft log saved in store into a MemoryStorage, returning
+// it with the index of the last entry applied.
+func loadLog(store tasks.Store, peers []uint64) (*raft.MemoryStorage, uint64, error) {
+	storage := raft.NewMemoryStorage()
+	applied := uint64(bootstrapIndex)
+	err := tasks.ReadTx(store, func(tx tasks.Tx) error {
+		snap := raftpb.Snapshot{Metadata: raftpb.SnapshotMetadata{
+			Index:     bootstrapIndex,
+			Term:      1,
+			ConfState: raftpb.ConfState{Voters: peers},
+		}}
+		if b, err := tx.GetMeta(snapshotKey); err == nil {
+			snap = raftpb.Snapshot{}
+			if err := snap.Unmarshal(b); err != nil {
+				return fmt.Errorf("reading %s: %v", snapshotKey, err)
+			}
+		} else if !errors.Is(err, tasks.ErrNotFound) {
+			return err
+		}
+		if err := storage.ApplySnapshot(snap); err != nil {
+			return err
+		}
+
+		var hs raftpb.HardState
+		if b, err := tx.GetMeta(hardStateKey); err == nil {
+			if err := hs.Unmarshal(b); err != nil {
+				return fmt.Errorf("reading %s: %v", hardStateKey, err)
+			}
+		} else if !errors.Is(err, tasks.ErrNotFound) {
+			return err
+		}
+		last, err := getIndex(tx, lastKey)
+		if err != nil {
+			return err
+		}
+		applied = maxIndex(applied, snap.Metadata.Index)
+		if n, err := getIndex(tx, appliedKey); err != nil {
+			return err
+		} else {
+			applied = maxIndex(applied, n)
+		}
+
+		var entries []raftpb.Entry
+		for i := snap.Metadata.Index + 1; i <= last; i++ {
+			b, err := tx.GetMeta(entryKey(i))
+			if errors.Is(err, tasks.ErrNotFound) || err == nil && len(b) == 0 {
+				return fmt.Errorf("raft log entry %d is missing", i)
+			} else if err != nil {
+				return err
+			}
+			var e raftpb.Entry
+			if err := e.Unmarshal(b); err != nil {
+				return fmt.Errorf("reading raft log entry %d: %v", i, err)
+			}
+			entries = append(entries, e)
+		}
+		if err := storage.Append(entries); err != nil {
+			return err
+		}
+		return storage.SetHardState(hs)
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return storage, applied, nil
+}
+
+// getIndex reads a log index saved under key, which is 0 if there isn't
+// one.
+func getIndex(tx tasks.Tx, key string) (uint64, error) {
+	b, err := tx.GetMeta(key)
+	if errors.Is(err, tasks.ErrNotFound) {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+	n, err := strconv.ParseUint(string(b), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("reading %s: %v", key, err)
+	}
+	return n, nil
+}
+
+func putIndex(tx tasks.Tx, key string, n uint64) error {
+	return tx.PutMeta(key, []byte(strconv.FormatUint(n, 10)))
+}
+
+// saveReady saves the snapshot, log entries and state in rd, and applies
+// its committed entries, in one transaction. storage is the log before rd.
+func saveReady(tx tasks.Tx, rd raft.Ready, storage *raft.MemoryStorage) error {
+	if !raft.IsEmptySnap(rd.Snapshot) {
+		if err := restoreSnapshot(tx, rd.Snapshot, storage); err != nil {
+			return err
+		}
+	}
+	if !raft.IsEmptyHardState(rd.HardState) {
+		b, err := rd.HardState.Marshal()
+		if err != nil {
+			return err
+		}
+		if err := tx.PutMeta(hardStateKey, b); err != nil {
+			return err
+		}
+	}
+	for _, e := range rd.Entries {
+		b, err := e.Marshal()
+		if err != nil {
+			return err
+		}
+		if err := tx.PutMeta(entryKey(e.Index), b); err != nil {
+			return err
+		}
+	}
+	if n := len(rd.Entries); n > 0 {
+		if err := putIndex(tx, lastKey, rd.Entries[n-1].Index); err != nil {
+			return err
+		}
+	}
+
+	for _, e := range rd.CommittedEntries {
+		if e.Type != raftpb.EntryNormal || len(e.Data) == 0 {
+			continue
+		}
+		_, ops, err := decodeBatch(e.Data)
+		if err != nil {
+			return fmt.Errorf("raft log entry %d: %v", e.Index, err)
+		}
+		if err := applyOps(tx, ops); err != nil {
+			return fmt.Errorf("applying raft log entry %d: %v", e.Index, err)
+		}
+	}
+	if n := len(rd.CommittedEntries); n > 0 {
+		return putIndex(tx, appliedKey, rd.CommittedEntries[n-1].Index)
+	}
+	return nil
+}
+
+// restoreSnapshot brings the store up to a snapshot from the leader,
+// replacing the whole log with it.
+func restoreSnapshot(tx tasks.Tx, snap raftpb.Snapshot, storage *raft.MemoryStorage) error {
+	index := snap.Metadata.Index
+	if len(snap.Data) > 0 {
+		_, ops, err := decodeBatch(snap.Data)
+		if err != nil {
+			return fmt.Errorf("raft snapshot at index %d: %v", index, err)
+		}
+		if err := applyOps(tx, ops); err != nil {
+			return fmt.Errorf("applying raft snapshot at index %d: %v", index, err)
+		}
+	}
+	last, _ := storage.LastIndex()
+	if err := clearEntries(tx, storage, last); err != nil {
+		return err
+	}
+	if err := putIndex(tx, firstKey, index+1); err != nil {
+		return err
+	}
+	if err := putSnapshot(tx, snap); err != nil {
+		return err
+	}
+	if err := putIndex(tx, lastKey, index); err != nil {
+		return err
+	}
+	return putIndex(tx, appliedKey, index)
+}
+
+// compactLog takes a snapshot at applied, the last entry applied, and
+// empties the entries before it but the last keep, which are left for
+// followers to catch up from.
+func compactLog(store tasks.Store, storage *raft.MemoryStorage, applied, keep uint64) error {
+	prev, err := storage.Snapshot()
+	if err != nil {
+		return err
+	}
+	var ops []op
+	if len(prev.Data) > 0 {
+		if _, ops, err = decodeBatch(prev.Data); err != nil {
+			return fmt.Errorf("raft snapshot at index %d: %v", prev.Metadata.Index, err)
+		}
+	}
+	entries, err := storage.Entries(prev.Metadata.Index+1, applied+1, math.MaxUint64)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if e.Type != raftpb.EntryNormal || len(e.Data) == 0 {
+			continue
+		}
+		_, batch, err := decodeBatch(e.Data)
+		if err != nil {
+			return fmt.Errorf("raft log entry %d: %v", e.Index, err)
+		}
+		ops = append(ops, batch...)
+	}
+
+	cs := prev.Metadata.ConfState
+	snap, err := storage.CreateSnapshot(applied, &cs, encodeBatch(0, foldOps(ops)))
+	if err != nil {
+		return err
+	}
+	first, _ := storage.FirstIndex()
+	compact := applied - minIndex(keep, applied-first)
+	err = tasks.WriteTx(store, func(tx tasks.Tx) error {
+		if err := putSnapshot(tx, snap); err != nil {
+			return err
+		}
+		if err := clearEntries(tx, storage, compact); err != nil {
+			return err
+		}
+		return putIndex(tx, firstKey, compact+1)
+	})
+	if err != nil {
+		return err
+	}
+	return storage.Compact(compact)
+}
+
+func putSnapshot(tx tasks.Tx, snap raftpb.Snapshot) error {
+	b, err := snap.Marshal()
+	if err != nil {
+		return err
+	}
+	return tx.PutMeta(snapshotKey, b)
+}
+
+// clearEntries empties the saved log entries up to last, as meta values
+// can't be deleted. Those kept before the snapshot at the last restart
+// are saved but not in storage.
+func clearEntries(tx tasks.Tx, storage *raft.MemoryStorage, last uint64) error {
+	first, err := getIndex(tx, firstKey)
+	if err != nil {
+		return err
+	}
+	if i, _ := storage.FirstIndex(); first == 0 || i < first {
+		first = i
+	}
+	for i := first; i <= last; i++ {
+		if err := tx.PutMeta(entryKey(i), []byte{}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func maxIndex(a, b uint64) uint64 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func minIndex(a, b uint64) uint64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// A batch is a committed write transaction, proposed as one log entry:
+//
+//	id   uvarint, chosen by the proposing node to match the entry to its
+//	     Commit
+//	ops  each an op kind followed by its task id, outbox seq or key, and
+//	     its value
+const (
+	opPutTask byte = 1 + iota
+	opDeleteTask
+	opPutBlob
+	opPutMeta
+	opPutOutbox
+	opTrimOutbox
+)
+
+// op is one change. Tasks and the outbox use id and the others key. Task
+// values are encoded tasks.Task messages.
+type op struct {
+	kind  byte
+	id    int64
+	key   string
+	value []byte
+}
+
+func encodeBatch(id uint64, ops []op) []byte {
+	b := appendUvarint(make([]byte, 0, 256), id)
+	for _, o := range ops {
+		b = append(b, o.kind)
+		switch o.kind {
+		case opPutTask, opDeleteTask, opPutOutbox, opTrimOutbox:
+			b = appendUvarint(b, uint64(o.id))
+		default:
+			b = appendBytes(b, []byte(o.key))
+		}
+		if hasValue(o.kind) {
+			b = appendBytes(b, o.value)
+		}
+	}
+	return b
+}
+
+func decodeBatch(b []byte) (uint64, []op, error) {
+	id, n := binary.Uvarint(b)
+	if n <= 0 {
+		return 0, nil, errors.New("bad batch id")
+	}
+	b = b[n:]
+	var ops []op
+	for len(b) > 0 {
+		o := op{kind: b[0]}
+		b = b[1:]
+		var err error
+		switch o.kind {
+		case opPutTask, opDeleteTask, opPutOutbox, opTrimOutbox:
+			id, n := binary.Uvarint(b)
+			if n <= 0 {
+				return 0, nil, errors.New("bad task id or seq")
+			}
+			o.id, b = int64(id), b[n:]
+		case opPutBlob, opPutMeta:
+			var key []byte
+			if key, b, err = readBytes(b); err != nil {
+				return 0, nil, err
+			}
+			o.key = string(key)
+		default:
+			return 0, nil, fmt.Errorf("unknown op %d", o.kind)
+		}
+		if hasValue(o.kind) {
+			if o.value, b, err = readBytes(b); err != nil {
+				return 0, nil, err
+			}
+		}
+		ops = append(ops, o)
+	}
+	return id, ops, nil
+}
+
+// foldOps reduces ops to the fewest which leave a store the same: the
+// last change to each task, blob and meta key, and the outbox entries left
+// after the last trim, which comes first.
+func foldOps(ops []op) []op {
+	type target struct {
+		kind byte
+		id   int64
+		key  string
+	}
+	var trim int64
+	last := make(map[target]op)
+	for _, o := range ops {
+		k := target{kind: o.kind, id: o.id, key: o.key}
+		switch o.kind {
+		case opDeleteTask:
+			k.kind = opPutTask
+		case opTrimOutbox:
+			trim = o.id
+			for k := range last {
+				if k.kind == opPutOutbox && k.id <= trim {
+					delete(last, k)
+				}
+			}
+			continue
+		}
+		last[k] = o
+	}
+
+	folded := make([]op, 0, len(last)+1)
+	for _, o := range last {
+		folded = append(folded, o)
+	}
+	sort.Slice(folded, func(i, j int) bool {
+		a, b := folded[i], folded[j]
+		if a.kind != b.kind {
+			return a.kind < b.kind
+		}
+		if a.id != b.id {
+			return a.id < b.id
+		}
+		return a.key < b.key
+	})
+	if trim > 0 {
+		folded = append([]op{{kind: opTrimOutbox, id: trim}}, folded...)
+	}
+	return folded
+}
+
+func hasValue(kind byte) bool {
+	return kind != opDeleteTask && kind != opTrimOutbox
+}
+
+func applyOps(tx tasks.Tx, ops []op) error {
+	for _, o := range ops {
+		var err error
+		switch o.kind {
+		case opPutTask:
+			t := &tasks.Task{}
+			if err := proto.Unmarshal(o.value, t); err != nil {
+				return fmt.Errorf("task %d: %v", o.id, err)
+			}
+			err = tx.Put(t)
+		case opDeleteTask:
+			err = tx.Delete(o.id)
+		case opPutBlob:
+			err = tx.PutBlob(o.key, o.value)
+		case opPutMeta:
+			err = tx.PutMeta(o.key, o.value)
+		case opPutOutbox:
+			err = tx.PutOutbox(o.id, o.value)
+		case opTrimOutbox:
+			err = tx.TrimOutbox(o.id)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func appendBytes(b, data []byte) []byte {
+	b = appendUvarint(b, uint64(len(data)))
+	return append(b, data...)
+}
+
+func readBytes(b []byte) ([]byte, []byte, error) {
+	n, size := binary.Uvarint(b)
+	if size <= 0 || uint64(len(b)-size) < n {
+		return nil, nil, errors.New("value runs past the end of the batch")
+	}
+	b = b[size:]
+	return b[:n:n], b[n:], nil
+}
+
+func appendUvarint(b []byte, v uint64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	return append(b, buf[:binary.PutUvarint(buf[:], v)]...)
+}
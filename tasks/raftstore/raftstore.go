@@ -0,0 +1,623 @@
+// Package raftstore replicates a tasks.Store across a cluster of servers
+// with Raft, so the cluster carries on while most of its servers are up.
+//
+// Each server runs a Node, which is the store its TaskService uses. A Node
+// keeps its copy of the tasks, and its raft log, in a local store of any
+// other kind. Writes are only accepted by the leader: committing a write
+// transaction proposes it to the cluster, and returns once a majority has
+// it and the leader has applied it. Every node applies committed
+// transactions to its local store in the same order.
+//
+// A TaskService works from the tasks it holds in memory, which on the
+// other nodes fall behind, so the whole API is served by the leader. The
+// interceptors from Node.UnaryInterceptor and Node.StreamInterceptor make
+// the other nodes forward calls to it, or turn them away with its address.
+//
+// Membership is fixed by the peers each node is started with. The log is
+// compacted into a snapshot every Config.SnapshotEntries entries, which is
+// sent to followers too far behind to catch up from the entries left.
+package raftstore
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/andyantrim/grpc-example/tasks"
+	"github.com/teamwork/log"
+	"go.etcd.io/etcd/raft/v3"
+	"go.etcd.io/etcd/raft/v3/raftpb"
+)
+
+// ErrNotLeader is returned when writing to a node which isn't the leader,
+// or hasn't yet caught up since becoming it.
+var ErrNotLeader = errors.New("raftstore: this node isn't the leader")
+
+// ErrStopped is returned once a node has been closed, or has stopped
+// because it couldn't save its log.
+var ErrStopped = errors.New("raftstore: node has stopped")
+
+const (
+	// electionTicks and heartbeatTicks are in ticks of Config.TickInterval.
+	electionTicks  = 10
+	heartbeatTicks = 1
+
+	// maxSizePerMsg and maxInflightMsgs limit how much of the log is sent
+	// to a follower before it replies.
+	maxSizePerMsg   = 1 << 20
+	maxInflightMsgs = 256
+
+	// commitTimeout is how long a commit waits for a majority when the
+	// caller has no deadline of its own.
+	commitTimeout = 5 * time.Second
+
+	// defaultSnapshotEntries is Config.SnapshotEntries when it isn't set.
+	defaultSnapshotEntries = 10000
+)
+
+// Config describes a node and its cluster.
+type Config struct {
+	// ID is this node's id, which is one of Peers.
+	ID uint64
+	// Peers are the addresses of the cluster's gRPC servers by node id,
+	// including this one. They must be the same on every node.
+	Peers map[uint64]string
+	// Store is where this node keeps its copy of the tasks and its log.
+	// The node owns it, and closes it when it's closed.
+	Store tasks.Store
+	// Transport sends messages to the other nodes.
+	Transport Transport
+	// TickInterval is the raft clock. A leader sends heartbeats every tick
+	// and an election is called after ten ticks without one. It defaults
+	// to 100ms.
+	TickInterval time.Duration
+	// SnapshotEntries is how many entries are applied between snapshots of
+	// the log. Taking one empties the entries before it but for the last
+	// half of them, which followers a little behind catch up from. It
+	// defaults to 10000.
+	SnapshotEntries int
+
+	// Redirect makes the interceptors return the leader's address rather
+	// than forwarding calls to it.
+	Redirect bool
+	// LinearizableReads makes the leader check it's still the leader before
+	// every call, so a leader cut off from the others doesn't serve stale
+	// tasks. It costs a round of heartbeats per call.
+	LinearizableReads bool
+}
+
+// Node is one server of a cluster. It's a tasks.Store.
+type Node struct {
+	cfg     Config
+	store   tasks.Store
+	storage *raft.MemoryStorage
+	raft    raft.Node
+
+	// writeMu is held by the open writable transaction.
+	writeMu sync.Mutex
+
+	mu      sync.Mutex
+	err     error
+	leader  uint64
+	leading bool
+	// applied is the last entry applied to the store, and appliedCh is
+	// closed and replaced whenever it moves on.
+	applied   uint64
+	appliedCh chan struct{}
+	// readyIndex is the entry a new leader must have applied before
+	// onLead brings its TaskService up to date. Writes are only accepted
+	// once synced.
+	readyIndex uint64
+	syncing    bool
+	synced     bool
+	onLead     func() error
+	// servingCh is closed and replaced whenever Serving may have changed.
+	servingCh chan struct{}
+
+	nextID    uint64
+	proposals map[uint64]chan error
+	reads     map[uint64]chan uint64
+
+	forwarder *forwarder
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewNode starts a node, carrying on from the log in cfg.Store.
+func NewNode(cfg Config) (*Node, error) {
+	if _, ok := cfg.Peers[cfg.ID]; !ok {
+		return nil, fmt.Errorf("node %d isn't one of the cluster's peers", cfg.ID)
+	}
+	if cfg.TickInterval <= 0 {
+		cfg.TickInterval = 100 * time.Millisecond
+	}
+	if cfg.SnapshotEntries <= 0 {
+		cfg.SnapshotEntries = defaultSnapshotEntries
+	}
+	var peers []uint64
+	for id := range cfg.Peers {
+		peers = append(peers, id)
+	}
+	sort.Slice(peers, func(i, j int) bool { return peers[i] < peers[j] })
+
+	storage, applied, err := loadLog(cfg.Store, peers)
+	if err != nil {
+		return nil, fmt.Errorf("loading raft log: %v", err)
+	}
+	var seed [8]byte
+	if _, err := rand.Read(seed[:]); err != nil {
+		return nil, err
+	}
+
+	n := &Node{
+		cfg:       cfg,
+		store:     cfg.Store,
+		storage:   storage,
+		applied:   applied,
+		appliedCh: make(chan struct{}),
+		servingCh: make(chan struct{}),
+		nextID:    binary.LittleEndian.Uint64(seed[:]),
+		proposals: make(map[uint64]chan error),
+		reads:     make(map[uint64]chan uint64),
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	n.forwarder = newForwarder(cfg.Peers)
+	n.raft = raft.RestartNode(&raft.Config{
+		ID:              cfg.ID,
+		ElectionTick:    electionTicks,
+		HeartbeatTick:   heartbeatTicks,
+		Storage:         storage,
+		Applied:         applied,
+		MaxSizePerMsg:   maxSizePerMsg,
+		MaxInflightMsgs: maxInflightMsgs,
+		CheckQuorum:     true,
+		PreVote:         true,
+		Logger:          raftLogger{},
+	})
+	go n.run()
+	return n, nil
+}
+
+// OnLead sets a function to call each time this node becomes the leader,
+// once it has applied everything committed before, and before it accepts
+// writes. It's where the TaskService reloads its tasks.
+func (n *Node) OnLead(fn func() error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.onLead = fn
+	n.synced = false
+	n.servingChangedLocked()
+	n.maybeSyncLocked()
+}
+
+// Step passes a message from another node to this one.
+func (n *Node) Step(ctx context.Context, m raftpb.Message) error {
+	return n.raft.Step(ctx, m)
+}
+
+// Leader returns the id and address of the node this one believes is the
+// leader, or 0 if it doesn't know of one.
+func (n *Node) Leader() (uint64, string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.leader, n.cfg.Peers[n.leader]
+}
+
+// Serving reports whether this node is the leader and is ready to accept
+// writes.
+func (n *Node) Serving() bool {
+	serving, _ := n.serving()
+	return serving
+}
+
+// WhileServing runs fn each time this node starts serving, cancelling the
+// context passed to it once the node stops, until ctx is cancelled. It's
+// for background jobs which change the tasks, which only the leader should
+// run. A job which returns before it's cancelled isn't run again until the
+// node next starts serving.
+func (n *Node) WhileServing(ctx context.Context, fn func(ctx context.Context)) {
+	for {
+		serving, changed := n.serving()
+		if serving {
+			jobCtx, cancel := context.WithCancel(ctx)
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				fn(jobCtx)
+			}()
+		running:
+			for {
+				select {
+				case <-changed:
+					if serving, changed = n.serving(); serving {
+						continue
+					}
+				case <-ctx.Done():
+				case <-done:
+				}
+				break running
+			}
+			cancel()
+			<-done
+		}
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// serving returns Serving along with a channel which is closed when it
+// may have changed.
+func (n *Node) serving() (bool, <-chan struct{}) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.err == nil && n.leading && n.synced, n.servingCh
+}
+
+// servingChangedLocked wakes everything waiting for Serving to change.
+// n.mu must be held.
+func (n *Node) servingChangedLocked() {
+	close(n.servingCh)
+	n.servingCh = make(chan struct{})
+}
+
+func (n *Node) run() {
+	defer close(n.done)
+	ticker := time.NewTicker(n.cfg.TickInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			n.raft.Tick()
+		case rd := <-n.raft.Ready():
+			if err := n.handle(rd); err != nil {
+				// The log can't be trusted to be saved any more, so this
+				// node stops taking part.
+				log.Error(err, "Failed to save the raft log, stopping the node")
+				n.fail(err)
+				n.raft.Stop()
+				return
+			}
+			n.raft.Advance()
+		case <-n.stop:
+			n.fail(ErrStopped)
+			n.raft.Stop()
+			return
+		}
+	}
+}
+
+func (n *Node) handle(rd raft.Ready) error {
+	err := tasks.WriteTx(n.store, func(tx tasks.Tx) error { return saveReady(tx, rd, n.storage) })
+	if err != nil {
+		return err
+	}
+	if !raft.IsEmptySnap(rd.Snapshot) {
+		log.Infof("Raft node %d caught up from a snapshot at index %d", n.cfg.ID, rd.Snapshot.Metadata.Index)
+		if err := n.storage.ApplySnapshot(rd.Snapshot); err != nil {
+			return err
+		}
+	}
+	if err := n.storage.Append(rd.Entries); err != nil {
+		return err
+	}
+	if !raft.IsEmptyHardState(rd.HardState) {
+		if err := n.storage.SetHardState(rd.HardState); err != nil {
+			return err
+		}
+	}
+	n.cfg.Transport.Send(rd.Messages)
+	for _, m := range rd.Messages {
+		if m.Type == raftpb.MsgSnap {
+			// Transports don't say whether a message arrived. Reporting
+			// the snapshot as sent has the leader probe the follower, and
+			// send it again if it's still behind.
+			n.raft.ReportSnapshot(m.To, raft.SnapshotFinish)
+		}
+	}
+	if err := n.maybeCompact(rd); err != nil {
+		return err
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if rd.SoftState != nil {
+		leading := rd.SoftState.RaftState == raft.StateLeader
+		if rd.SoftState.Lead != n.leader {
+			log.Infof("Raft node %d now follows leader %d", n.cfg.ID, rd.SoftState.Lead)
+		}
+		n.leader = rd.SoftState.Lead
+		if leading && !n.leading {
+			last, _ := n.storage.LastIndex()
+			n.readyIndex = last
+			n.synced = false
+		}
+		n.leading = leading
+		if !leading {
+			n.synced = false
+			n.failProposalsLocked(ErrNotLeader)
+		}
+		n.servingChangedLocked()
+	}
+	for _, rs := range rd.ReadStates {
+		id := binary.LittleEndian.Uint64(rs.RequestCtx)
+		if ch, ok := n.reads[id]; ok {
+			ch <- rs.Index
+			delete(n.reads, id)
+		}
+	}
+	for _, e := range rd.CommittedEntries {
+		if e.Type != raftpb.EntryNormal || len(e.Data) == 0 {
+			continue
+		}
+		// The entry was checked when it was applied.
+		id, _, _ := decodeBatch(e.Data)
+		if ch, ok := n.proposals[id]; ok {
+			ch <- nil
+			delete(n.proposals, id)
+		}
+	}
+	applied := n.applied
+	if !raft.IsEmptySnap(rd.Snapshot) {
+		applied = rd.Snapshot.Metadata.Index
+	}
+	if k := len(rd.CommittedEntries); k > 0 {
+		applied = rd.CommittedEntries[k-1].Index
+	}
+	if applied != n.applied {
+		n.applied = applied
+		close(n.appliedCh)
+		n.appliedCh = make(chan struct{})
+	}
+	n.maybeSyncLocked()
+	return nil
+}
+
+// maybeCompact takes a snapshot of the log once enough entries have been
+// applied since the last one. It's only called from run.
+func (n *Node) maybeCompact(rd raft.Ready) error {
+	k := len(rd.CommittedEntries)
+	if k == 0 {
+		return nil
+	}
+	applied := rd.CommittedEntries[k-1].Index
+	snap, err := n.storage.Snapshot()
+	if err != nil {
+		return err
+	}
+	if applied-snap.Metadata.Index < uint64(n.cfg.SnapshotEntries) {
+		return nil
+	}
+	if err := compactLog(n.store, n.storage, applied, uint64(n.cfg.SnapshotEntries/2)); err != nil {
+		return fmt.Errorf("taking a snapshot at index %d: %v", applied, err)
+	}
+	log.Infof("Raft node %d took a snapshot at index %d", n.cfg.ID, applied)
+	return nil
+}
+
+// maybeSyncLocked calls onLead if this node leads but hasn't yet caught
+// up. n.mu must be held.
+func (n *Node) maybeSyncLocked() {
+	if !n.leading || n.synced || n.syncing || n.onLead == nil || n.applied < n.readyIndex {
+		return
+	}
+	n.syncing = true
+	fn, index := n.onLead, n.readyIndex
+	go func() {
+		err := fn()
+		n.mu.Lock()
+		defer n.mu.Unlock()
+		n.syncing = false
+		switch {
+		case err != nil:
+			log.Error(err, "Failed to catch up after becoming the leader")
+		case n.leading && n.readyIndex == index:
+			log.Infof("Raft node %d is the leader and accepting writes", n.cfg.ID)
+			n.synced = true
+			n.servingChangedLocked()
+			return
+		}
+		n.maybeSyncLocked()
+	}()
+}
+
+// resyncLocked makes the node catch up again before accepting more
+// writes, as a proposal which failed may still be committed later. n.mu
+// must be held.
+func (n *Node) resyncLocked() {
+	if !n.leading {
+		return
+	}
+	last, _ := n.storage.LastIndex()
+	n.readyIndex = last
+	n.synced = false
+	n.servingChangedLocked()
+	n.maybeSyncLocked()
+}
+
+func (n *Node) failProposalsLocked(err error) {
+	for id, ch := range n.proposals {
+		ch <- err
+		delete(n.proposals, id)
+	}
+}
+
+func (n *Node) fail(err error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.err == nil {
+		n.err = err
+	}
+	n.leading, n.synced = false, false
+	n.servingChangedLocked()
+	n.failProposalsLocked(err)
+}
+
+// propose proposes a batch of ops and waits until it has been applied to
+// this node's store.
+func (n *Node) propose(ctx context.Context, ops []op) error {
+	n.mu.Lock()
+	if n.err != nil {
+		n.mu.Unlock()
+		return n.err
+	}
+	if !n.leading || !n.synced {
+		n.mu.Unlock()
+		return ErrNotLeader
+	}
+	n.nextID++
+	id := n.nextID
+	ch := make(chan error, 1)
+	n.proposals[id] = ch
+	n.mu.Unlock()
+
+	err := n.raft.Propose(ctx, encodeBatch(id, ops))
+	if err == nil {
+		select {
+		case err = <-ch:
+		case <-ctx.Done():
+			err = ctx.Err()
+		}
+	}
+	if err != nil {
+		n.mu.Lock()
+		if _, ok := n.proposals[id]; ok {
+			delete(n.proposals, id)
+		} else if len(ch) > 0 && <-ch == nil {
+			// It was applied after all.
+			err = nil
+		}
+		if err != nil {
+			n.resyncLocked()
+		}
+		n.mu.Unlock()
+	}
+	return err
+}
+
+// ReadIndex waits until this node has applied everything committed when
+// it was called, after checking with a majority that it's still the
+// leader. Reads from the store afterwards are linearizable.
+func (n *Node) ReadIndex(ctx context.Context) error {
+	n.mu.Lock()
+	if n.err != nil {
+		n.mu.Unlock()
+		return n.err
+	}
+	n.nextID++
+	id := n.nextID
+	ch := make(chan uint64, 1)
+	n.reads[id] = ch
+	n.mu.Unlock()
+	defer func() {
+		n.mu.Lock()
+		delete(n.reads, id)
+		n.mu.Unlock()
+	}()
+
+	var rctx [8]byte
+	binary.LittleEndian.PutUint64(rctx[:], id)
+	if err := n.raft.ReadIndex(ctx, rctx[:]); err != nil {
+		return err
+	}
+	var index uint64
+	select {
+	case index = <-ch:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	for {
+		n.mu.Lock()
+		applied, appliedCh := n.applied, n.appliedCh
+		n.mu.Unlock()
+		if applied >= index {
+			return nil
+		}
+		select {
+		case <-appliedCh:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Begin starts a transaction on this node's copy of the tasks. Writable
+// transactions can only be begun on the leader, and their Commit proposes
+// them to the cluster.
+func (n *Node) Begin(writable bool) (tasks.Tx, error) {
+	if !writable {
+		tx, err := n.store.Begin(false)
+		if err != nil {
+			return nil, err
+		}
+		return readTx{tx}, nil
+	}
+	if !n.Serving() {
+		return nil, n.notLeader()
+	}
+	n.writeMu.Lock()
+	return &tx{n: n}, nil
+}
+
+// notLeader returns why this node can't accept a write.
+func (n *Node) notLeader() error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	switch {
+	case n.err != nil:
+		return n.err
+	case n.leader == 0:
+		return fmt.Errorf("%w, and no leader has been elected", ErrNotLeader)
+	case n.leader == n.cfg.ID:
+		return fmt.Errorf("%w yet, as it's still catching up", ErrNotLeader)
+	default:
+		return fmt.Errorf("%w, node %d at %s is", ErrNotLeader, n.leader, n.cfg.Peers[n.leader])
+	}
+}
+
+// Close stops the node and closes its store. The rest of the cluster
+// carries on without it.
+func (n *Node) Close() error {
+	select {
+	case <-n.stop:
+	default:
+		close(n.stop)
+	}
+	<-n.done
+	n.forwarder.close()
+	return n.store.Close()
+}
+
+// raftLogger passes the raft library's warnings and errors on to the log.
+// Fatal and Panic don't return, so both panic.
+type raftLogger struct{}
+
+func (raftLogger) Debug(v ...interface{})                   {}
+func (raftLogger) Debugf(format string, v ...interface{})   {}
+func (raftLogger) Info(v ...interface{})                    {}
+func (raftLogger) Infof(format string, v ...interface{})    {}
+func (raftLogger) Warning(v ...interface{})                 { log.Warn(v...) }
+func (raftLogger) Warningf(format string, v ...interface{}) { log.Warnf(format, v...) }
+func (raftLogger) Error(v ...interface{})                   { log.Error(errors.New(fmt.Sprint(v...)), "Raft error") }
+func (raftLogger) Errorf(format string, v ...interface{}) {
+	log.Error(fmt.Errorf(format, v...), "Raft error")
+}
+func (raftLogger) Fatal(v ...interface{}) { panic(fmt.Sprint(v...)) }
+func (raftLogger) Fatalf(format string, v ...interface{}) {
+	panic(fmt.Sprintf(format, v...))
+}
+func (raftLogger) Panic(v ...interface{}) { panic(fmt.Sprint(v...)) }
+func (raftLogger) Panicf(format string, v ...interface{}) {
+	panic(fmt.Sprintf(format, v...))
+}
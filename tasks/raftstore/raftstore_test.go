@@ -0,0 +1,254 @@
+package raftstore
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/andyantrim/grpc-example/tasks"
+	"github.com/andyantrim/grpc-example/tasks/storetest"
+)
+
+// testTick keeps elections in the tests short.
+const testTick = 10 * time.Millisecond
+
+// waitFor polls cond until it holds, failing the test after a few seconds.
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(testTick)
+	}
+}
+
+func TestStore(t *testing.T) {
+	storetest.Run(t, storetest.Backend{
+		Open: func(t *testing.T) tasks.Store {
+			nw := NewNetwork()
+			n, err := NewNode(Config{
+				ID:           1,
+				Peers:        map[uint64]string{1: "node1"},
+				Store:        tasks.NewMemoryStore(),
+				Transport:    nw.Transport(1),
+				TickInterval: testTick,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			nw.Add(n)
+			n.OnLead(func() error { return nil })
+			waitFor(t, "the node to lead", n.Serving)
+			return n
+		},
+	})
+}
+
+// cluster is three nodes, each with a TaskService, on one Network.
+type cluster struct {
+	t        *testing.T
+	nw       *Network
+	stores   map[uint64]tasks.Store
+	nodes    map[uint64]*Node
+	services map[uint64]*tasks.TaskService
+	snapshot int
+}
+
+var clusterPeers = map[uint64]string{1: "node1", 2: "node2", 3: "node3"}
+
+// keepOpen stops a node closing its store, so it can be started again on
+// the same one.
+type keepOpen struct{ tasks.Store }
+
+func (keepOpen) Close() error { return nil }
+
+func newCluster(t *testing.T, snapshotEntries int) *cluster {
+	c := &cluster{
+		t:        t,
+		nw:       NewNetwork(),
+		stores:   make(map[uint64]tasks.Store),
+		nodes:    make(map[uint64]*Node),
+		services: make(map[uint64]*tasks.TaskService),
+		snapshot: snapshotEntries,
+	}
+	for id := range clusterPeers {
+		c.stores[id] = keepOpen{tasks.NewMemoryStore()}
+		c.start(id)
+	}
+	t.Cleanup(func() {
+		for _, n := range c.nodes {
+			n.Close()
+		}
+	})
+	return c
+}
+
+// start starts the node with the given id on its store.
+func (c *cluster) start(id uint64) {
+	c.t.Helper()
+	n, err := NewNode(Config{
+		ID:              id,
+		Peers:           clusterPeers,
+		Store:           c.stores[id],
+		Transport:       c.nw.Transport(id),
+		TickInterval:    testTick,
+		SnapshotEntries: c.snapshot,
+	})
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	ts, err := tasks.NewTaskService(n)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	c.nw.Add(n)
+	n.OnLead(ts.Reload)
+	c.nodes[id], c.services[id] = n, ts
+}
+
+// stop closes the node with the given id.
+func (c *cluster) stop(id uint64) {
+	c.t.Helper()
+	if err := c.nodes[id].Close(); err != nil {
+		c.t.Fatal(err)
+	}
+	delete(c.nodes, id)
+	delete(c.services, id)
+}
+
+// leader waits for one of the given nodes, or any if none are given, to
+// serve, and returns its id.
+func (c *cluster) leader(ids ...uint64) uint64 {
+	c.t.Helper()
+	if len(ids) == 0 {
+		for id := range c.nodes {
+			ids = append(ids, id)
+		}
+	}
+	var leader uint64
+	waitFor(c.t, "a leader", func() bool {
+		for _, id := range ids {
+			if n, ok := c.nodes[id]; ok && n.Serving() {
+				leader = id
+				return true
+			}
+		}
+		return false
+	})
+	return leader
+}
+
+// create adds n tasks through the given node's TaskService.
+func (c *cluster) create(id uint64, n int) {
+	c.t.Helper()
+	for i := 0; i < n; i++ {
+		_, err := c.services[id].Create(context.Background(), &tasks.TaskRequest{Title: fmt.Sprintf("task %d", i), Project: "p"})
+		if err != nil {
+			c.t.Fatal(err)
+		}
+	}
+}
+
+// stored returns how many tasks the given node's store holds.
+func (c *cluster) stored(id uint64) int {
+	var list []*tasks.Task
+	err := tasks.ReadTx(c.stores[id], func(tx tasks.Tx) error {
+		var err error
+		list, err = tx.List(0, 0)
+		return err
+	})
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	return len(list)
+}
+
+// open returns how many tasks the given node's stats count as open.
+func (c *cluster) open(id uint64) int64 {
+	c.t.Helper()
+	stats, err := tasks.NewStatsService(c.services[id]).GetStats(context.Background(), &tasks.GetStatsRequest{Project: "p"})
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	return stats.Days[len(stats.Days)-1].Open
+}
+
+func TestFailover(t *testing.T) {
+	c := newCluster(t, 0)
+	first := c.leader()
+	c.create(first, 3)
+
+	c.stop(first)
+	next := c.leader()
+	if got := c.open(next); got != 3 {
+		t.Errorf("new leader counts %d open tasks, want 3", got)
+	}
+	c.create(next, 1)
+	if got := c.open(next); got != 4 {
+		t.Errorf("new leader counts %d open tasks after creating one, want 4", got)
+	}
+
+	c.start(first)
+	waitFor(t, "the old leader to catch up", func() bool { return c.stored(first) == 4 })
+}
+
+func TestPartition(t *testing.T) {
+	c := newCluster(t, 0)
+	old := c.leader()
+	c.create(old, 2)
+
+	c.nw.Partition(old)
+	var rest []uint64
+	for id := range clusterPeers {
+		if id != old {
+			rest = append(rest, id)
+		}
+	}
+	next := c.leader(rest...)
+	c.create(next, 3)
+
+	// The old leader can't reach a majority, so it can't write.
+	ctx, cancel := context.WithTimeout(context.Background(), 20*testTick)
+	defer cancel()
+	if _, err := c.services[old].Create(ctx, &tasks.TaskRequest{Title: "lost", Project: "p"}); err == nil {
+		t.Error("the old leader created a task while cut off")
+	}
+
+	c.nw.Heal()
+	waitFor(t, "the old leader to catch up", func() bool { return c.stored(old) == 5 })
+	if c.nodes[old].Serving() && old != c.leader() {
+		t.Error("two nodes are serving")
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	c := newCluster(t, 4)
+	leader := c.leader()
+	var behind uint64
+	for id := range clusterPeers {
+		if id != leader {
+			behind = id
+			break
+		}
+	}
+
+	c.nw.Partition(behind)
+	c.create(leader, 20)
+	first, _ := c.nodes[leader].storage.FirstIndex()
+	if first <= bootstrapIndex+1 {
+		t.Fatalf("the leader's log wasn't compacted, it starts at %d", first)
+	}
+
+	// The follower is too far behind to catch up from the entries left.
+	c.nw.Heal()
+	waitFor(t, "the follower to catch up", func() bool { return c.stored(behind) == 20 })
+
+	// Every node carries on from its snapshot after a restart.
+	c.stop(behind)
+	c.start(behind)
+	c.create(c.leader(), 1)
+	waitFor(t, "the restarted follower to catch up", func() bool { return c.stored(behind) == 21 })
+}
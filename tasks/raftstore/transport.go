@@ -0,0 +1,247 @@
+package raftstore
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/andyantrim/grpc-example/tasks"
+	"github.com/teamwork/log"
+	"go.etcd.io/etcd/raft/v3/raftpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/backoff"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Transport sends raft messages to other nodes, which pass them to their
+// Node.Step. Messages may be lost or reordered, and raft sends them again
+// as needed, so Send mustn't block on a slow or missing node.
+type Transport interface {
+	Send(msgs []raftpb.Message)
+}
+
+// queueSize is how many messages wait for a node before more are dropped.
+const queueSize = 4096
+
+// Network connects nodes in the same process, for trying out a cluster.
+// It can cut nodes off from each other to simulate a partition.
+type Network struct {
+	mu     sync.Mutex
+	queues map[uint64]chan raftpb.Message
+	// group is each node's side of a partition. Nodes only reach others
+	// in the same group.
+	group  map[uint64]int
+	groups int
+}
+
+// NewNetwork returns a network with no nodes.
+func NewNetwork() *Network {
+	return &Network{
+		queues: make(map[uint64]chan raftpb.Message),
+		group:  make(map[uint64]int),
+	}
+}
+
+// Transport returns the transport for the node with the given id to
+// send with.
+func (nw *Network) Transport(id uint64) Transport {
+	return networkTransport{nw: nw, from: id}
+}
+
+// Add delivers messages for n's id to n, until n is closed.
+func (nw *Network) Add(n *Node) {
+	q := make(chan raftpb.Message, queueSize)
+	nw.mu.Lock()
+	nw.queues[n.cfg.ID] = q
+	nw.mu.Unlock()
+
+	go func() {
+		for {
+			select {
+			case m := <-q:
+				n.Step(context.Background(), m)
+			case <-n.done:
+				nw.mu.Lock()
+				if nw.queues[n.cfg.ID] == q {
+					delete(nw.queues, n.cfg.ID)
+				}
+				nw.mu.Unlock()
+				return
+			}
+		}
+	}()
+}
+
+// Partition cuts the given nodes off from the rest. Messages between them
+// still get through, as do those between the rest.
+func (nw *Network) Partition(ids ...uint64) {
+	nw.mu.Lock()
+	defer nw.mu.Unlock()
+	nw.groups++
+	for _, id := range ids {
+		nw.group[id] = nw.groups
+	}
+}
+
+// Heal undoes every partition.
+func (nw *Network) Heal() {
+	nw.mu.Lock()
+	defer nw.mu.Unlock()
+	nw.group = make(map[uint64]int)
+}
+
+type networkTransport struct {
+	nw   *Network
+	from uint64
+}
+
+func (t networkTransport) Send(msgs []raftpb.Message) {
+	t.nw.mu.Lock()
+	defer t.nw.mu.Unlock()
+	for _, m := range msgs {
+		q, ok := t.nw.queues[m.To]
+		if !ok || t.nw.group[m.To] != t.nw.group[t.from] {
+			continue
+		}
+		select {
+		case q <- m:
+		default:
+		}
+	}
+}
+
+// maxRaftMessage is the largest batch of messages sent to a node over
+// gRPC, which is well above an entry with the largest attachment.
+const maxRaftMessage = 64 << 20
+
+// sendTimeout is how long a batch of messages has to reach a node.
+const sendTimeout = 5 * time.Second
+
+// reconnectBackoff is gRPC's default, but waiting at most a second between
+// attempts to reach a node.
+var reconnectBackoff = backoff.Config{
+	BaseDelay:  backoff.DefaultConfig.BaseDelay,
+	Multiplier: backoff.DefaultConfig.Multiplier,
+	Jitter:     backoff.DefaultConfig.Jitter,
+	MaxDelay:   time.Second,
+}
+
+// GRPCTransport sends messages to the Raft services of the other nodes.
+type GRPCTransport struct {
+	mu     sync.Mutex
+	peers  map[uint64]string
+	queues map[uint64]chan raftpb.Message
+	conns  []*grpc.ClientConn
+	closed bool
+}
+
+// NewGRPCTransport returns a transport to the gRPC servers of the given
+// peers, which it connects to as it first sends to each.
+func NewGRPCTransport(peers map[uint64]string) *GRPCTransport {
+	return &GRPCTransport{peers: peers, queues: make(map[uint64]chan raftpb.Message)}
+}
+
+func (t *GRPCTransport) Send(msgs []raftpb.Message) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.closed {
+		return
+	}
+	for _, m := range msgs {
+		q, ok := t.queues[m.To]
+		if !ok {
+			addr, ok := t.peers[m.To]
+			if !ok {
+				continue
+			}
+			conn, err := grpc.Dial(addr,
+				grpc.WithInsecure(),
+				grpc.WithDefaultCallOptions(grpc.MaxCallSendMsgSize(maxRaftMessage)),
+				// A node which was down should rejoin soon after it's back.
+				grpc.WithConnectParams(grpc.ConnectParams{Backoff: reconnectBackoff}),
+			)
+			if err != nil {
+				log.Error(err, "Failed to connect to raft node at "+addr)
+				continue
+			}
+			q = make(chan raftpb.Message, queueSize)
+			t.queues[m.To] = q
+			t.conns = append(t.conns, conn)
+			go t.sendLoop(tasks.NewRaftClient(conn), q)
+		}
+		select {
+		case q <- m:
+		default:
+		}
+	}
+}
+
+// sendLoop sends what's queued for one node, a batch at a time, until the
+// queue is closed.
+func (t *GRPCTransport) sendLoop(client tasks.RaftClient, q chan raftpb.Message) {
+	for m := range q {
+		var batch tasks.RaftMessages
+		size := 0
+		for {
+			b, err := m.Marshal()
+			if err == nil {
+				batch.Messages = append(batch.Messages, b)
+				size += len(b)
+			}
+			if size >= maxSizePerMsg || len(q) == 0 {
+				break
+			}
+			var ok bool
+			if m, ok = <-q; !ok {
+				break
+			}
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+		// A node which is down misses the messages, and raft sends what it
+		// needs again once it's back.
+		client.Send(ctx, &batch)
+		cancel()
+	}
+}
+
+// Close stops sending and closes the connections.
+func (t *GRPCTransport) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.closed {
+		return nil
+	}
+	t.closed = true
+	for _, q := range t.queues {
+		close(q)
+	}
+	for _, conn := range t.conns {
+		conn.Close()
+	}
+	return nil
+}
+
+// RaftService receives messages sent by other nodes' GRPCTransport.
+type RaftService struct {
+	tasks.UnimplementedRaftServer
+
+	node *Node
+}
+
+func NewRaftService(node *Node) *RaftService {
+	return &RaftService{node: node}
+}
+
+func (s *RaftService) Send(ctx context.Context, r *tasks.RaftMessages) (*tasks.RaftAck, error) {
+	for _, b := range r.Messages {
+		var m raftpb.Message
+		if err := m.Unmarshal(b); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "decoding raft message: %v", err)
+		}
+		if err := s.node.Step(ctx, m); err != nil {
+			return nil, status.Errorf(codes.Unavailable, "%v", err)
+		}
+	}
+	return &tasks.RaftAck{}, nil
+}
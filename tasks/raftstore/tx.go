@@ -0,0 +1,324 @@
+package raftstore
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/andyantrim/grpc-example/tasks"
+	"google.golang.org/protobuf/proto"
+)
+
+// tx is a writable transaction. It collects its changes, reading through
+// to the node's store for everything else, and proposes them when it
+// commits. Only the leader writes, and only one transaction at a time, so
+// the store doesn't change underneath it. A nil task marks a deletion, and
+// outbox entries up to trim are removed before those added.
+type tx struct {
+	n    *Node
+	done bool
+
+	ops    []op
+	tasks  map[int64]*tasks.Task
+	blobs  map[string][]byte
+	meta   map[string][]byte
+	outbox map[int64][]byte
+	trim   int64
+}
+
+func (tx *tx) Get(id int64) (*tasks.Task, error) {
+	if tx.done {
+		return nil, tasks.ErrTxDone
+	}
+	if t, ok := tx.tasks[id]; ok {
+		if t == nil {
+			return nil, tasks.ErrNotFound
+		}
+		return proto.Clone(t).(*tasks.Task), nil
+	}
+	var t *tasks.Task
+	err := tasks.ReadTx(tx.n.store, func(inner tasks.Tx) error {
+		var err error
+		t, err = inner.Get(id)
+		return err
+	})
+	return t, err
+}
+
+func (tx *tx) List(after int64, limit int) ([]*tasks.Task, error) {
+	if tx.done {
+		return nil, tasks.ErrTxDone
+	}
+	// Ask for enough extra to make up for tasks this transaction deleted.
+	var deleted int
+	for _, t := range tx.tasks {
+		if t == nil {
+			deleted++
+		}
+	}
+	fetch := limit
+	if limit > 0 {
+		fetch += deleted
+	}
+	var stored []*tasks.Task
+	err := tasks.ReadTx(tx.n.store, func(inner tasks.Tx) error {
+		var err error
+		stored, err = inner.List(after, fetch)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// If the store had more, its tasks past the last one read are unknown,
+	// so this transaction's own tasks past it have to wait for a later
+	// page.
+	upTo := int64(-1)
+	if fetch > 0 && len(stored) == fetch {
+		upTo = stored[len(stored)-1].Id
+	}
+	var list []*tasks.Task
+	for _, t := range stored {
+		if _, changed := tx.tasks[t.Id]; !changed {
+			list = append(list, t)
+		}
+	}
+	for id, t := range tx.tasks {
+		if id > after && t != nil && (upTo < 0 || id <= upTo) {
+			list = append(list, proto.Clone(t).(*tasks.Task))
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Id < list[j].Id })
+	if limit > 0 && len(list) > limit {
+		list = list[:limit]
+	}
+	return list, nil
+}
+
+func (tx *tx) Put(t *tasks.Task) error {
+	if tx.done {
+		return tasks.ErrTxDone
+	}
+	b, err := proto.Marshal(t)
+	if err != nil {
+		return err
+	}
+	tx.ops = append(tx.ops, op{kind: opPutTask, id: t.Id, value: b})
+	tx.setTask(t.Id, proto.Clone(t).(*tasks.Task))
+	return nil
+}
+
+func (tx *tx) Delete(id int64) error {
+	if tx.done {
+		return tasks.ErrTxDone
+	}
+	tx.ops = append(tx.ops, op{kind: opDeleteTask, id: id})
+	tx.setTask(id, nil)
+	return nil
+}
+
+func (tx *tx) setTask(id int64, t *tasks.Task) {
+	if tx.tasks == nil {
+		tx.tasks = make(map[int64]*tasks.Task)
+	}
+	tx.tasks[id] = t
+}
+
+func (tx *tx) GetBlob(key string) ([]byte, error) {
+	return tx.getBytes(tx.blobs, key, tasks.Tx.GetBlob)
+}
+
+func (tx *tx) PutBlob(key string, data []byte) error {
+	if tx.done {
+		return tasks.ErrTxDone
+	}
+	data = append([]byte{}, data...)
+	tx.ops = append(tx.ops, op{kind: opPutBlob, key: key, value: data})
+	if tx.blobs == nil {
+		tx.blobs = make(map[string][]byte)
+	}
+	tx.blobs[key] = data
+	return nil
+}
+
+func (tx *tx) GetMeta(key string) ([]byte, error) {
+	return tx.getBytes(tx.meta, key, tasks.Tx.GetMeta)
+}
+
+func (tx *tx) PutMeta(key string, value []byte) error {
+	if tx.done {
+		return tasks.ErrTxDone
+	}
+	value = append([]byte{}, value...)
+	tx.ops = append(tx.ops, op{kind: opPutMeta, key: key, value: value})
+	if tx.meta == nil {
+		tx.meta = make(map[string][]byte)
+	}
+	tx.meta[key] = value
+	return nil
+}
+
+func (tx *tx) ListMeta() ([]string, error) {
+	if tx.done {
+		return nil, tasks.ErrTxDone
+	}
+	var keys []string
+	err := tasks.ReadTx(tx.n.store, func(inner tasks.Tx) error {
+		var err error
+		keys, err = listMeta(inner)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	for key := range tx.meta {
+		i := sort.SearchStrings(keys, key)
+		if i == len(keys) || keys[i] != key {
+			keys = append(keys[:i], append([]string{key}, keys[i:]...)...)
+		}
+	}
+	return keys, nil
+}
+
+// listMeta lists the meta keys in inner, bar the log's.
+func listMeta(inner tasks.Tx) ([]string, error) {
+	all, err := inner.ListMeta()
+	if err != nil {
+		return nil, err
+	}
+	keys := all[:0]
+	for _, key := range all {
+		if !strings.HasPrefix(key, raftPrefix) {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+// readTx is a read-only transaction on the node's store, which hides the
+// log's keys.
+type readTx struct {
+	tasks.Tx
+}
+
+func (tx readTx) ListMeta() ([]string, error) {
+	return listMeta(tx.Tx)
+}
+
+func (tx *tx) PutOutbox(seq int64, data []byte) error {
+	if tx.done {
+		return tasks.ErrTxDone
+	}
+	data = append([]byte{}, data...)
+	tx.ops = append(tx.ops, op{kind: opPutOutbox, id: seq, value: data})
+	if tx.outbox == nil {
+		tx.outbox = make(map[int64][]byte)
+	}
+	tx.outbox[seq] = data
+	return nil
+}
+
+func (tx *tx) ListOutbox(after int64, limit int) ([]tasks.OutboxEntry, error) {
+	if tx.done {
+		return nil, tasks.ErrTxDone
+	}
+	if after < tx.trim {
+		after = tx.trim
+	}
+	// As in List, ask for enough extra to make up for entries this
+	// transaction replaced.
+	fetch := limit
+	if limit > 0 {
+		fetch += len(tx.outbox)
+	}
+	var stored []tasks.OutboxEntry
+	err := tasks.ReadTx(tx.n.store, func(inner tasks.Tx) error {
+		var err error
+		stored, err = inner.ListOutbox(after, fetch)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	upTo := int64(-1)
+	if fetch > 0 && len(stored) == fetch {
+		upTo = stored[len(stored)-1].Seq
+	}
+	var list []tasks.OutboxEntry
+	for _, e := range stored {
+		if _, added := tx.outbox[e.Seq]; !added {
+			list = append(list, e)
+		}
+	}
+	for seq, data := range tx.outbox {
+		if seq > after && (upTo < 0 || seq <= upTo) {
+			list = append(list, tasks.OutboxEntry{Seq: seq, Data: append([]byte{}, data...)})
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Seq < list[j].Seq })
+	if limit > 0 && len(list) > limit {
+		list = list[:limit]
+	}
+	return list, nil
+}
+
+func (tx *tx) TrimOutbox(upTo int64) error {
+	if tx.done {
+		return tasks.ErrTxDone
+	}
+	tx.ops = append(tx.ops, op{kind: opTrimOutbox, id: upTo})
+	if upTo > tx.trim {
+		tx.trim = upTo
+	}
+	for seq := range tx.outbox {
+		if seq <= upTo {
+			delete(tx.outbox, seq)
+		}
+	}
+	return nil
+}
+
+func (tx *tx) getBytes(pending map[string][]byte, key string, get func(tasks.Tx, string) ([]byte, error)) ([]byte, error) {
+	if tx.done {
+		return nil, tasks.ErrTxDone
+	}
+	if b, ok := pending[key]; ok {
+		return append([]byte{}, b...), nil
+	}
+	var b []byte
+	err := tasks.ReadTx(tx.n.store, func(inner tasks.Tx) error {
+		var err error
+		b, err = get(inner, key)
+		return err
+	})
+	return b, err
+}
+
+// Commit proposes the transaction and waits until this node has applied
+// it. If it fails, the transaction may still be committed later, so the
+// node catches up again before accepting more writes.
+func (tx *tx) Commit() error {
+	if tx.done {
+		return tasks.ErrTxDone
+	}
+	defer tx.finish()
+	if len(tx.ops) == 0 {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), commitTimeout)
+	defer cancel()
+	return tx.n.propose(ctx, tx.ops)
+}
+
+func (tx *tx) Rollback() error {
+	if !tx.done {
+		tx.finish()
+	}
+	return nil
+}
+
+func (tx *tx) finish() {
+	tx.done = true
+	tx.n.writeMu.Unlock()
+}
@@ -0,0 +1,87 @@
+package tasks
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Ranks are base 36 strings compared lexically. A rank can always be found
+// between two others, so moving a card only rewrites that card's rank. The
+// ranks grow longer as cards are squeezed into the same spot, though, so
+// once one would be longer than maxRankLength its column is ranked afresh.
+
+// maxRankLength is the longest rank a move leaves before the column's
+// ranks are spread out again.
+const maxRankLength = 24
+
+const rankDigits = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+// initialRank ranks a new task by id so new tasks sort in creation order.
+// The trailing digit keeps the rank from ending in a zero, which would leave
+// no room directly before it.
+func initialRank(id int64) string {
+	r := strconv.FormatInt(id, len(rankDigits))
+	return strings.Repeat("0", 10-len(r)) + r + "i"
+}
+
+// spreadRanks returns n ranks in order, spread evenly so there's plenty of
+// room between them.
+func spreadRanks(n int) []string {
+	const width = 10
+	space := int64(1)
+	for i := 0; i < width; i++ {
+		space *= int64(len(rankDigits))
+	}
+	step := space / int64(n+1)
+	ranks := make([]string, n)
+	for i := range ranks {
+		r := strconv.FormatInt(int64(i+1)*step, len(rankDigits))
+		ranks[i] = strings.Repeat("0", width-len(r)) + r + "i"
+	}
+	return ranks
+}
+
+// rankBetween returns a rank that sorts after a and before b. An empty a
+// means the start of the column and an empty b the end.
+func rankBetween(a, b string) string {
+	if b != "" {
+		// Keep the common prefix and find a midpoint in what follows.
+		n := 0
+		for n < len(b) && rankDigit(a, n) == strings.IndexByte(rankDigits, b[n]) {
+			n++
+		}
+		if n > 0 {
+			rest := ""
+			if n < len(a) {
+				rest = a[n:]
+			}
+			return b[:n] + rankBetween(rest, b[n:])
+		}
+	}
+
+	lo := rankDigit(a, 0)
+	hi := len(rankDigits)
+	if b != "" {
+		hi = strings.IndexByte(rankDigits, b[0])
+	}
+	if hi-lo > 1 {
+		return string(rankDigits[(lo+hi)/2])
+	}
+	if len(b) > 1 {
+		return b[:1]
+	}
+	rest := ""
+	if len(a) > 1 {
+		rest = a[1:]
+	}
+	return string(rankDigits[lo]) + rankBetween(rest, "")
+}
+
+// rankDigit returns the value of the ith digit of r, treating missing digits
+// as zero.
+func rankDigit(r string, i int) int {
+	if i >= len(r) {
+		return 0
+	}
+	return strings.IndexByte(rankDigits, r[i])
+}
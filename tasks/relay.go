@@ -0,0 +1,249 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/teamwork/log"
+	"google.golang.org/protobuf/proto"
+)
+
+// relayBatch is how many outbox entries a Relay reads at a time.
+const relayBatch = 100
+
+// A sink which fails is retried after minRetry, doubling each time it
+// fails again up to maxRetry.
+const (
+	minRetry = time.Second
+	maxRetry = 5 * time.Minute
+)
+
+// offsetKey is the meta key prefix for each sink's offset, the seq of the
+// last event delivered to it.
+const offsetKey = "outbox/offset/"
+
+// Sink is somewhere a Relay delivers events.
+type Sink interface {
+	// Name identifies the sink's offset in the store, so it must stay the
+	// same across restarts.
+	Name() string
+	// Deliver sends events, which are in seq order. If it fails, they're
+	// all delivered again later, so a sink may see an event more than once.
+	Deliver(ctx context.Context, events []*TaskEvent) error
+}
+
+// Relay delivers the events in a TaskService's outbox to sinks, at least
+// once each. It keeps each sink's offset in the store, so after a restart
+// it carries on where it left off, and removes events from the outbox once
+// every sink has had them.
+//
+// A sink registered for the first time starts from the oldest event still
+// in the outbox.
+type Relay struct {
+	tasks *TaskService
+	sinks []*relaySink
+	// lastErr is the last error logged, so one which keeps happening, such
+	// as on a follower in a cluster, is only logged once.
+	lastErr string
+}
+
+type relaySink struct {
+	Sink
+	// retry is how long to wait after the next failure, and retryAt when
+	// to try again after the last one.
+	retry   time.Duration
+	retryAt time.Time
+}
+
+func NewRelay(tasks *TaskService) *Relay {
+	return &Relay{tasks: tasks}
+}
+
+// Register adds a sink. It must be called before Run.
+func (r *Relay) Register(sink Sink) {
+	r.sinks = append(r.sinks, &relaySink{Sink: sink, retry: minRetry})
+
+	r.tasks.mu.Lock()
+	r.tasks.sinks = append(r.tasks.sinks, sink.Name())
+	r.tasks.mu.Unlock()
+}
+
+// Run delivers events as they're saved until ctx is cancelled, also
+// checking the outbox every interval.
+func (r *Relay) Run(ctx context.Context, interval time.Duration) {
+	timer := time.NewTimer(interval)
+	defer timer.Stop()
+
+	for {
+		err := r.RunOnce(ctx)
+		switch {
+		case err == nil:
+			r.lastErr = ""
+		case ctx.Err() != nil:
+		case err.Error() != r.lastErr:
+			r.lastErr = err.Error()
+			log.Error(err, "Failed to relay events")
+		}
+
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timer.Reset(r.wait(interval))
+		select {
+		case <-ctx.Done():
+			return
+		case <-r.tasks.outbox:
+		case <-timer.C:
+		}
+	}
+}
+
+// wait returns how long until the next sink is due a retry, or interval
+// if that's sooner.
+func (r *Relay) wait(interval time.Duration) time.Duration {
+	now := time.Now()
+	for _, sk := range r.sinks {
+		if d := sk.retryAt.Sub(now); d > 0 && d < interval {
+			interval = d
+		}
+	}
+	return interval
+}
+
+// RunOnce delivers the events in the outbox until every sink has had them
+// or is waiting to retry.
+func (r *Relay) RunOnce(ctx context.Context) error {
+	for {
+		more, err := r.relay(ctx)
+		if err != nil || !more {
+			return err
+		}
+	}
+}
+
+// relay delivers one batch of events, reporting whether there may be more.
+func (r *Relay) relay(ctx context.Context) (bool, error) {
+	if len(r.sinks) == 0 {
+		return false, nil
+	}
+	now := time.Now()
+	offsets := make(map[*relaySink]int64, len(r.sinks))
+	var entries []OutboxEntry
+	// The outbox is read in a writable transaction, which only the leader
+	// of a cluster can begin, so followers don't deliver events too.
+	err := WriteTx(r.tasks.store, func(tx Tx) error {
+		from := int64(-1)
+		for _, sk := range r.sinks {
+			off, err := getOffset(tx, sk.Name())
+			if err != nil {
+				return err
+			}
+			offsets[sk] = off
+			if now.Before(sk.retryAt) {
+				continue
+			}
+			if from < 0 || off < from {
+				from = off
+			}
+		}
+		if from < 0 {
+			return nil
+		}
+		var err error
+		entries, err = tx.ListOutbox(from, relayBatch)
+		return err
+	})
+	if err != nil || len(entries) == 0 {
+		return false, err
+	}
+
+	events := make([]*TaskEvent, len(entries))
+	for i, e := range entries {
+		ev := &TaskEvent{}
+		if err := proto.Unmarshal(e.Data, ev); err != nil {
+			return false, fmt.Errorf("decoding outbox entry %d: %v", e.Seq, err)
+		}
+		events[i] = ev
+	}
+
+	changed := make(map[*relaySink]bool)
+	for _, sk := range r.sinks {
+		if now.Before(sk.retryAt) {
+			continue
+		}
+		var pending []*TaskEvent
+		for _, ev := range events {
+			if ev.Seq > offsets[sk] {
+				pending = append(pending, ev)
+			}
+		}
+		if len(pending) == 0 {
+			continue
+		}
+		if err := sk.Deliver(ctx, pending); err != nil {
+			log.Error(err, fmt.Sprintf("Failed to deliver %d events to %s, retrying in %s", len(pending), sk.Name(), sk.retry))
+			sk.retryAt = time.Now().Add(sk.retry)
+			if sk.retry *= 2; sk.retry > maxRetry {
+				sk.retry = maxRetry
+			}
+			continue
+		}
+		sk.retry, sk.retryAt = minRetry, time.Time{}
+		offsets[sk] = pending[len(pending)-1].Seq
+		changed[sk] = true
+	}
+	if len(changed) == 0 {
+		return false, nil
+	}
+
+	trim := int64(-1)
+	for _, off := range offsets {
+		if trim < 0 || off < trim {
+			trim = off
+		}
+	}
+	err = WriteTx(r.tasks.store, func(tx Tx) error {
+		for sk := range changed {
+			if err := tx.PutMeta(offsetKey+sk.Name(), []byte(strconv.FormatInt(offsets[sk], 10))); err != nil {
+				return err
+			}
+		}
+		if trim > 0 {
+			return tx.TrimOutbox(trim)
+		}
+		return nil
+	})
+	if err != nil {
+		return false, fmt.Errorf("saving delivery offsets: %v", err)
+	}
+	return len(entries) == relayBatch, nil
+}
+
+func getOffset(tx Tx, name string) (int64, error) {
+	b, err := tx.GetMeta(offsetKey + name)
+	if errors.Is(err, ErrNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	off, err := strconv.ParseInt(string(b), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("reading offset of %s: %v", name, err)
+	}
+	return off, nil
+}
+
+// outboxChanged wakes the Relay, if one is running. s.mu must be held.
+func (s *TaskService) outboxChanged() {
+	select {
+	case s.outbox <- struct{}{}:
+	default:
+	}
+}
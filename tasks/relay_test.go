@@ -0,0 +1,118 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// recordingSink keeps the seqs of the events it's given, failing while
+// fail is set.
+type recordingSink struct {
+	name string
+	fail bool
+	seqs []int64
+}
+
+func (s *recordingSink) Name() string { return s.name }
+
+func (s *recordingSink) Deliver(_ context.Context, events []*TaskEvent) error {
+	if s.fail {
+		return errors.New("sink is down")
+	}
+	for _, ev := range events {
+		s.seqs = append(s.seqs, ev.Seq)
+	}
+	return nil
+}
+
+// Every event reaches every sink at least once, a failed delivery is
+// repeated, and the offsets saved in the store stop a restart replaying
+// what was already delivered.
+func TestRelayAtLeastOnce(t *testing.T) {
+	ctx := context.Background()
+	ts, err := NewTaskService(NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	good := &recordingSink{name: "good"}
+	flaky := &recordingSink{name: "flaky", fail: true}
+	relay := NewRelay(ts)
+	relay.Register(good)
+	relay.Register(flaky)
+
+	for _, title := range []string{"one", "two", "three"} {
+		if _, err := ts.Create(ctx, &TaskRequest{Title: title}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// A rolled back change leaves nothing in the outbox.
+	_, err = ts.Execute(ctx, &ExecuteRequest{Ops: []*ExecuteOp{
+		{Op: &ExecuteOp_Create{Create: &TaskRequest{Title: "rolled back"}}},
+		{Op: &ExecuteOp_Delete{Delete: &DeleteRequest{Id: 10}}},
+	}})
+	if err == nil {
+		t.Fatal("deleting a missing task succeeded")
+	}
+	outbox := func() int {
+		t.Helper()
+		var n int
+		err := ReadTx(ts.store, func(tx Tx) error {
+			entries, err := tx.ListOutbox(0, 0)
+			n = len(entries)
+			return err
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return n
+	}
+	if n := outbox(); n != 3 {
+		t.Fatalf("outbox holds %d events, want 3", n)
+	}
+
+	if err := relay.RunOnce(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if want := []int64{1, 2, 3}; !reflect.DeepEqual(good.seqs, want) || len(flaky.seqs) != 0 {
+		t.Errorf("first run delivered %v and %v, want %v and nothing", good.seqs, flaky.seqs, want)
+	}
+	if n := outbox(); n != 3 {
+		t.Errorf("outbox holds %d events while a sink still needs them, want 3", n)
+	}
+	if relay.wait(time.Hour) > minRetry {
+		t.Errorf("failed sink is retried after %s, want within %s", relay.wait(time.Hour), minRetry)
+	}
+
+	// Retry without waiting.
+	flaky.fail = false
+	relay.sinks[1].retryAt = time.Time{}
+	if err := relay.RunOnce(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if want := []int64{1, 2, 3}; !reflect.DeepEqual(flaky.seqs, want) || len(good.seqs) != 3 {
+		t.Errorf("after the retry the sinks have %v and %v, want [1 2 3] and %v", good.seqs, flaky.seqs, want)
+	}
+	if n := outbox(); n != 0 {
+		t.Errorf("outbox holds %d events once every sink has them", n)
+	}
+
+	ts = restart(t, ts)
+	good, flaky = &recordingSink{name: "good"}, &recordingSink{name: "flaky"}
+	relay = NewRelay(ts)
+	relay.Register(good)
+	relay.Register(flaky)
+	if _, err := ts.Create(ctx, &TaskRequest{Title: "four"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := relay.RunOnce(ctx); err != nil {
+		t.Fatal(err)
+	}
+	for _, sk := range []*recordingSink{good, flaky} {
+		if want := []int64{4}; !reflect.DeepEqual(sk.seqs, want) {
+			t.Errorf("after a restart %s was sent %v, want %v", sk.name, sk.seqs, want)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package tasks
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"unicode"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// DefaultSimilarity is the score above which tasks are reported as
+	// likely duplicates.
+	DefaultSimilarity = 0.5
+
+	defaultSimilarLimit = 10
+)
+
+func (s *TaskService) FindSimilar(c context.Context, r *FindSimilarRequest) (*FindSimilarResponse, error) {
+	if strings.TrimSpace(r.Title) == "" {
+		return nil, status.Error(codes.InvalidArgument, "title is required")
+	}
+	if r.Threshold < 0 || r.Threshold > 1 {
+		return nil, status.Error(codes.InvalidArgument, "threshold must be between 0 and 1")
+	}
+	threshold := r.Threshold
+	if threshold == 0 {
+		threshold = DefaultSimilarity
+	}
+	limit := int(r.Limit)
+	if limit <= 0 {
+		limit = defaultSimilarLimit
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	similar := s.similarLocked(r.Project, r.Title, r.Description, threshold, limit)
+	return &FindSimilarResponse{Tasks: similar}, nil
+}
+
+// similarLocked returns the open tasks in project whose title and
+// description score at least threshold against the given ones, best first.
+// An empty project matches every project. s.mu must be held.
+func (s *TaskService) similarLocked(project, title, description string, threshold float64, limit int) []*SimilarTask {
+	titleGrams, descGrams := trigrams(title), trigrams(description)
+
+	var similar []*SimilarTask
+	for id := range s.grams.candidates(titleGrams, descGrams) {
+		t := s.tasks[id]
+		if !isOpen(t.Status) || t.DuplicateOf != 0 || t.ArchivedAt != nil {
+			continue
+		}
+		if project != "" && t.Project != project {
+			continue
+		}
+		// Titles say the most about what a task is, so descriptions only
+		// count for a little when both tasks have one.
+		g := s.grams.tasks[id]
+		score := jaccard(titleGrams, g.title)
+		if len(descGrams) > 0 && t.Description != "" {
+			score = 0.7*score + 0.3*jaccard(descGrams, g.description)
+		}
+		if score >= threshold {
+			similar = append(similar, &SimilarTask{Id: t.Id, Title: t.Title, Score: score})
+		}
+	}
+	sort.Slice(similar, func(i, j int) bool {
+		if similar[i].Score != similar[j].Score {
+			return similar[i].Score > similar[j].Score
+		}
+		return similar[i].Id < similar[j].Id
+	})
+	if len(similar) > limit {
+		similar = similar[:limit]
+	}
+	return similar
+}
+
+// trigramIndex holds the trigrams of every task's title and description,
+// so finding similar tasks only scores those sharing at least one with the
+// title or description searched for. Any other task scores 0.
+type trigramIndex struct {
+	titles       map[string]map[int64]bool
+	descriptions map[string]map[int64]bool
+	tasks        map[int64]taskGrams
+}
+
+type taskGrams struct {
+	title, description map[string]bool
+}
+
+func newTrigramIndex() *trigramIndex {
+	return &trigramIndex{
+		titles:       make(map[string]map[int64]bool),
+		descriptions: make(map[string]map[int64]bool),
+		tasks:        make(map[int64]taskGrams),
+	}
+}
+
+// add indexes t, replacing what was indexed for it before.
+func (x *trigramIndex) add(t *Task) {
+	x.remove(t.Id)
+	g := taskGrams{title: trigrams(t.Title), description: trigrams(t.Description)}
+	x.tasks[t.Id] = g
+	addGrams(x.titles, g.title, t.Id)
+	addGrams(x.descriptions, g.description, t.Id)
+}
+
+func (x *trigramIndex) remove(id int64) {
+	g, ok := x.tasks[id]
+	if !ok {
+		return
+	}
+	removeGrams(x.titles, g.title, id)
+	removeGrams(x.descriptions, g.description, id)
+	delete(x.tasks, id)
+}
+
+// candidates returns the tasks sharing a trigram with title or description.
+func (x *trigramIndex) candidates(title, description map[string]bool) map[int64]bool {
+	ids := make(map[int64]bool)
+	for g := range title {
+		for id := range x.titles[g] {
+			ids[id] = true
+		}
+	}
+	for g := range description {
+		for id := range x.descriptions[g] {
+			ids[id] = true
+		}
+	}
+	return ids
+}
+
+func addGrams(index map[string]map[int64]bool, grams map[string]bool, id int64) {
+	for g := range grams {
+		ids, ok := index[g]
+		if !ok {
+			ids = make(map[int64]bool)
+			index[g] = ids
+		}
+		ids[id] = true
+	}
+}
+
+func removeGrams(index map[string]map[int64]bool, grams map[string]bool, id int64) {
+	for g := range grams {
+		delete(index[g], id)
+		if len(index[g]) == 0 {
+			delete(index, g)
+		}
+	}
+}
+
+// trigrams returns the set of three letter sequences in text, ignoring case
+// and punctuation. Each word is padded so short words still count.
+func trigrams(text string) map[string]bool {
+	grams := make(map[string]bool)
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		runes := []rune("  " + word + " ")
+		for i := 0; i+3 <= len(runes); i++ {
+			grams[string(runes[i:i+3])] = true
+		}
+	}
+	return grams
+}
+
+// jaccard is the size of the intersection of a and b over their union.
+func jaccard(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	shared := 0
+	for g := range a {
+		if b[g] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
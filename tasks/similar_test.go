@@ -0,0 +1,65 @@
+package tasks
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+func TestTrigramIndex(t *testing.T) {
+	ctx := context.Background()
+	ts, err := NewTaskService(NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range []*TaskRequest{
+		{Title: "Login page crashes on submit", Description: "stack trace attached"},
+		{Title: "Login crashes", Project: "p"},
+		{Title: "Write the release notes", Description: "crashes fixed this week"},
+		{Title: "Unrelated"},
+	} {
+		if _, err := ts.Create(ctx, r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rename := &UpdateRequest{
+		Id:         4,
+		Task:       &Task{Title: "Login page crash on submit"},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"title"}},
+	}
+	if _, err := ts.Update(ctx, rename); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ts.Delete(ctx, &DeleteRequest{Id: 2}); err != nil {
+		t.Fatal(err)
+	}
+
+	check := func(when string) {
+		t.Helper()
+		want := newTrigramIndex()
+		for _, task := range ts.tasks {
+			want.add(task)
+		}
+		if !reflect.DeepEqual(ts.grams, want) {
+			t.Errorf("index %s doesn't match the tasks", when)
+		}
+		similar, err := ts.FindSimilar(ctx, &FindSimilarRequest{Title: "login page crashes", Description: "crashes", Threshold: 0.1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var ids []int64
+		for _, st := range similar.Tasks {
+			ids = append(ids, st.Id)
+		}
+		if !reflect.DeepEqual(ids, []int64{4, 1, 3}) {
+			t.Errorf("similar tasks %s are %v", when, similar.Tasks)
+		}
+	}
+	check("after changes")
+	if err := ts.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	check("after reloading")
+}
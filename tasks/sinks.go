@@ -0,0 +1,118 @@
+package tasks
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// webhookTimeout is how long a webhook has to accept a batch of events.
+const webhookTimeout = 10 * time.Second
+
+// OpenSink returns the sink described by config, which is either
+// "webhook:" followed by a URL or "file:" followed by a path.
+func OpenSink(config string) (Sink, error) {
+	i := strings.IndexByte(config, ':')
+	if i < 0 || i == len(config)-1 {
+		return nil, fmt.Errorf("event sink %q should be webhook:URL or file:PATH", config)
+	}
+	switch kind, addr := config[:i], config[i+1:]; kind {
+	case "webhook":
+		if !strings.HasPrefix(addr, "http://") && !strings.HasPrefix(addr, "https://") {
+			return nil, fmt.Errorf("webhook %q isn't an http or https URL", addr)
+		}
+		return &WebhookSink{URL: addr}, nil
+	case "file":
+		return &FileSink{Path: addr}, nil
+	default:
+		return nil, fmt.Errorf("unknown event sink %q, expected webhook or file", kind)
+	}
+}
+
+// WebhookSink POSTs each batch of events to a URL as a JSON object with an
+// "events" array. Any response but a 2xx is a failure, and the batch is
+// sent again.
+type WebhookSink struct {
+	URL string
+}
+
+func (w *WebhookSink) Name() string {
+	return "webhook:" + w.URL
+}
+
+func (w *WebhookSink) Deliver(ctx context.Context, events []*TaskEvent) error {
+	body := []byte(`{"events":[`)
+	for i, ev := range events {
+		b, err := protojson.Marshal(ev)
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			body = append(body, ',')
+		}
+		body = append(body, b...)
+	}
+	body = append(body, "]}"...)
+
+	ctx, cancel := context.WithTimeout(ctx, webhookTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return errors.New("webhook responded " + resp.Status)
+	}
+	return nil
+}
+
+// FileSink appends events to a file as JSON, one per line, syncing it
+// before the batch counts as delivered.
+type FileSink struct {
+	Path string
+}
+
+func (f *FileSink) Name() string {
+	return "file:" + f.Path
+}
+
+func (f *FileSink) Deliver(ctx context.Context, events []*TaskEvent) error {
+	var buf []byte
+	for _, ev := range events {
+		b, err := protojson.Marshal(ev)
+		if err != nil {
+			return err
+		}
+		buf = append(append(buf, b...), '\n')
+	}
+
+	file, err := os.OpenFile(f.Path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(buf); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,153 @@
+package tasks
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/teamwork/log"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Snooze hides a task from List until the given time, when it is woken up
+// again and an UNSNOOZED event is sent to watchers.
+func (s *TaskService) Snooze(c context.Context, r *SnoozeRequest) (_ *Task, err error) {
+	if r.Until == nil {
+		return nil, status.Error(codes.InvalidArgument, "until is required")
+	}
+
+	s.mu.Lock()
+	defer s.unlock(&err)
+
+	t, ok := s.tasks[r.Id]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "task %d not found", r.Id)
+	}
+	until := r.Until.AsTime()
+	if !until.After(s.now()) {
+		return nil, status.Error(codes.InvalidArgument, "until must be in the future")
+	}
+
+	old := proto.Clone(t).(*Task)
+	t.SnoozedUntil = timestamppb.New(until)
+	s.changedLocked(old, t)
+	s.snoozesChangedLocked()
+
+	log.Infof("Snoozed task %d until %s", t.Id, until)
+	return proto.Clone(t).(*Task), nil
+}
+
+func (s *TaskService) Unsnooze(c context.Context, r *UnsnoozeRequest) (_ *Task, err error) {
+	s.mu.Lock()
+	defer s.unlock(&err)
+
+	t, ok := s.tasks[r.Id]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "task %d not found", r.Id)
+	}
+	if t.SnoozedUntil == nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "task %d is not snoozed", r.Id)
+	}
+	s.unsnoozeLocked(t)
+
+	return proto.Clone(t).(*Task), nil
+}
+
+// maxWakeWait is the longest RunWaker waits between looking for tasks to
+// wake.
+const maxWakeWait = time.Hour
+
+// RunWaker wakes snoozed tasks as their snoozes end, until ctx is
+// cancelled. In a cluster only the leader should run it. If the tasks can't
+// be saved it tries again after minRetry, doubling the wait each time it
+// fails again up to maxRetry.
+func (s *TaskService) RunWaker(ctx context.Context) {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	retry := time.Duration(0)
+	for {
+		// A failed change reloads the tasks, which signals s.snoozed, so
+		// it's only listened to when not waiting to retry.
+		snoozed := s.snoozed
+		if retry > 0 {
+			snoozed = nil
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-snoozed:
+		case <-timer.C:
+		}
+
+		next, err := s.wakeDue()
+		wait := maxWakeWait
+		switch {
+		case err != nil:
+			if retry *= 2; retry < minRetry {
+				retry = minRetry
+			} else if retry > maxRetry {
+				retry = maxRetry
+			}
+			log.Error(err, fmt.Sprintf("Failed to wake snoozed tasks, retrying in %s", retry))
+			wait = retry
+		case !next.IsZero():
+			retry = 0
+			if d := time.Until(next); d < wait {
+				wait = d
+			}
+		default:
+			retry = 0
+		}
+
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timer.Reset(wait)
+	}
+}
+
+// wakeDue wakes every task whose snooze is over, returning when the next
+// snooze ends, or the zero time if no other task is snoozed.
+func (s *TaskService) wakeDue() (next time.Time, err error) {
+	s.mu.Lock()
+	defer s.unlock(&err)
+
+	now := s.now()
+	for _, t := range s.tasks {
+		if t.SnoozedUntil == nil {
+			continue
+		}
+		until := t.SnoozedUntil.AsTime()
+		if !until.After(now) {
+			s.unsnoozeLocked(t)
+		} else if next.IsZero() || until.Before(next) {
+			next = until
+		}
+	}
+	return next, nil
+}
+
+// snoozesChangedLocked wakes RunWaker, if it's running, to look at the
+// snoozes again. s.mu must be held.
+func (s *TaskService) snoozesChangedLocked() {
+	select {
+	case s.snoozed <- struct{}{}:
+	default:
+	}
+}
+
+// unsnoozeLocked clears the snooze on t. s.mu must be held.
+func (s *TaskService) unsnoozeLocked(t *Task) {
+	old := proto.Clone(t).(*Task)
+	t.SnoozedUntil = nil
+	s.changedLocked(old, t)
+
+	log.Infof("Task %d is no longer snoozed", t.Id)
+}
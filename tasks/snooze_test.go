@@ -0,0 +1,85 @@
+package tasks
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// A snoozed task is hidden until its snooze ends, then woken with an
+// UNSNOOZED event.
+func TestSnoozeWakes(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	store := NewMemoryStore()
+	ts, err := NewTaskService(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts.now = func() time.Time { return now }
+	for _, title := range []string{"soon", "later"} {
+		if _, err := ts.Create(ctx, &TaskRequest{Title: title}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for id, d := range map[int64]time.Duration{1: time.Hour, 2: 3 * time.Hour} {
+		if _, err := ts.Snooze(ctx, &SnoozeRequest{Id: id, Until: timestamppb.New(now.Add(d))}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	listed := func() []int64 {
+		t.Helper()
+		list, err := ts.List(ctx, &ListRequest{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var ids []int64
+		for _, task := range list.Tasks {
+			ids = append(ids, task.Id)
+		}
+		return ids
+	}
+	if ids := listed(); len(ids) != 0 {
+		t.Fatalf("snoozed tasks %v are listed", ids)
+	}
+
+	next, err := ts.wakeDue()
+	if err != nil || !next.Equal(now.Add(time.Hour)) {
+		t.Errorf("next wake-up is %v, %v; want in an hour", next, err)
+	}
+	now = now.Add(time.Hour)
+	next, err = ts.wakeDue()
+	if err != nil || !next.Equal(now.Add(2*time.Hour)) {
+		t.Errorf("next wake-up is %v, %v; want in two hours", next, err)
+	}
+	if ids := listed(); len(ids) != 1 || ids[0] != 1 {
+		t.Errorf("listed %v after the first snooze ended, want [1]", ids)
+	}
+
+	var last *TaskEvent
+	err = ReadTx(store, func(tx Tx) error {
+		entries, err := tx.ListOutbox(0, 0)
+		if err != nil {
+			return err
+		}
+		last = &TaskEvent{}
+		return proto.Unmarshal(entries[len(entries)-1].Data, last)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last.Type != TaskEvent_UNSNOOZED || last.Task.GetId() != 1 {
+		t.Errorf("last event is %v, want task 1 UNSNOOZED", last)
+	}
+
+	now = now.Add(2 * time.Hour)
+	if next, err := ts.wakeDue(); err != nil || !next.IsZero() {
+		t.Errorf("next wake-up with nothing snoozed is %v, %v", next, err)
+	}
+	if ids := listed(); len(ids) != 2 {
+		t.Errorf("listed %v once every snooze ended", ids)
+	}
+}
@@ -0,0 +1,405 @@
+package sqlstore
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The migrations directory holds the schema as numbered steps, each a pair
+// of files such as 0003_add_rank.up.sql and 0003_add_rank.down.sql.
+// Numbers start at 1 and mustn't skip any. Statements are split on
+// semicolons, so scripts mustn't use them in strings.
+//
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// Migration is one step of the schema.
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Checksum identifies the migration's up script, so a database can tell
+// whether what was applied to it has since been edited.
+func (m Migration) Checksum() string {
+	sum := sha256.Sum256([]byte(m.Up))
+	return hex.EncodeToString(sum[:])
+}
+
+var migrations = loadMigrations(migrationFiles)
+
+var migrationName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// loadMigrations reads the embedded migrations, panicking if they're
+// misnamed, unpaired or out of sequence, since that's a bug in the build.
+func loadMigrations(fsys fs.FS) []Migration {
+	files, err := fs.ReadDir(fsys, "migrations")
+	if err != nil {
+		panic(err)
+	}
+	byVersion := map[int]*Migration{}
+	for _, f := range files {
+		match := migrationName.FindStringSubmatch(f.Name())
+		if match == nil {
+			panic("sqlstore: badly named migration " + f.Name())
+		}
+		version, _ := strconv.Atoi(match[1])
+		script, err := fs.ReadFile(fsys, path.Join("migrations", f.Name()))
+		if err != nil {
+			panic(err)
+		}
+		m := byVersion[version]
+		if m == nil {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		}
+		if m.Name != match[2] {
+			panic(fmt.Sprintf("sqlstore: migration %d is named both %s and %s", version, m.Name, match[2]))
+		}
+		if match[3] == "up" {
+			m.Up = string(script)
+		} else {
+			m.Down = string(script)
+		}
+	}
+
+	list := make([]Migration, 0, len(byVersion))
+	for v := 1; v <= len(byVersion); v++ {
+		m := byVersion[v]
+		if m == nil {
+			panic(fmt.Sprintf("sqlstore: migration %d is missing", v))
+		}
+		if m.Up == "" || m.Down == "" {
+			panic(fmt.Sprintf("sqlstore: migration %d needs both an up and a down script", v))
+		}
+		list = append(list, *m)
+	}
+	return list
+}
+
+// Migrations lists the migrations built in, oldest first.
+func Migrations() []Migration {
+	return append([]Migration{}, migrations...)
+}
+
+// Latest is the schema version this build expects.
+func Latest() int {
+	return len(migrations)
+}
+
+// bootstrap creates the tables tracking migrations, which sit outside the
+// migrations themselves.
+const bootstrap = `
+CREATE TABLE IF NOT EXISTS schema_migrations (
+	version INTEGER PRIMARY KEY,
+	name TEXT NOT NULL,
+	checksum TEXT NOT NULL,
+	applied_at INTEGER NOT NULL
+);
+CREATE TABLE IF NOT EXISTS schema_lock (
+	id INTEGER PRIMARY KEY CHECK (id = 1),
+	holder TEXT NOT NULL,
+	acquired_at INTEGER NOT NULL
+)`
+
+// ErrLocked is returned when another process is migrating the database.
+var ErrLocked = errors.New("sqlstore: migrations are locked")
+
+// lock takes the migration lock, a single row which only one process can
+// insert. A process which dies while migrating leaves it behind, and
+// Unlock clears it.
+func lock(db *sql.DB) (release func() error, err error) {
+	if err := execScript(db, bootstrap); err != nil {
+		return nil, fmt.Errorf("creating migration tables: %v", err)
+	}
+	host, _ := os.Hostname()
+	holder := fmt.Sprintf("%s pid %d", host, os.Getpid())
+	_, err = db.Exec(`INSERT INTO schema_lock (id, holder, acquired_at) VALUES (1, ?, ?)`, holder, time.Now().Unix())
+	if err != nil {
+		var by string
+		var at int64
+		if db.QueryRow(`SELECT holder, acquired_at FROM schema_lock WHERE id = 1`).Scan(&by, &at) == nil {
+			return nil, fmt.Errorf("%w by %s since %s", ErrLocked, by, time.Unix(at, 0).UTC().Format(time.RFC3339))
+		}
+		return nil, fmt.Errorf("taking the migration lock: %v", err)
+	}
+	return func() error {
+		_, err := db.Exec(`DELETE FROM schema_lock WHERE id = 1 AND holder = ?`, holder)
+		return err
+	}, nil
+}
+
+// Unlock clears the migration lock whoever holds it. It's for recovering
+// after a migration was killed part way, and mustn't be used while one is
+// running.
+func Unlock(db *sql.DB) error {
+	if err := execScript(db, bootstrap); err != nil {
+		return err
+	}
+	_, err := db.Exec(`DELETE FROM schema_lock WHERE id = 1`)
+	return err
+}
+
+// MigrateTo applies or reverts migrations until the database is at
+// version, holding the migration lock throughout. Each migration runs in
+// its own transaction, so a failure leaves the database at the last
+// version which succeeded.
+func MigrateTo(db *sql.DB, version int) (err error) {
+	if version < 0 || version > Latest() {
+		return fmt.Errorf("no schema version %d, this build has 0 to %d", version, Latest())
+	}
+	release, err := lock(db)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if rerr := release(); err == nil && rerr != nil {
+			err = fmt.Errorf("releasing the migration lock: %v", rerr)
+		}
+	}()
+
+	status, err := Status(db)
+	if err != nil {
+		return err
+	}
+	current := 0
+	for _, st := range status {
+		if st.Unknown {
+			return fmt.Errorf("database has migration %d (%s) which this build doesn't know, so it can't be migrated by this build", st.Version, st.Name)
+		}
+		if st.Changed {
+			return fmt.Errorf("migration %d (%s) has changed since it was applied", st.Version, st.Name)
+		}
+		if !st.AppliedAt.IsZero() {
+			if st.Version != current+1 {
+				return fmt.Errorf("database has migration %d applied without %d", st.Version, current+1)
+			}
+			current = st.Version
+		}
+	}
+
+	for ; current < version; current++ {
+		m := migrations[current]
+		err := apply(db, m.Up, `INSERT INTO schema_migrations (version, name, checksum, applied_at) VALUES (?, ?, ?, ?)`,
+			m.Version, m.Name, m.Checksum(), time.Now().Unix())
+		if err != nil {
+			return fmt.Errorf("applying migration %d (%s): %v", m.Version, m.Name, err)
+		}
+	}
+	for ; current > version; current-- {
+		m := migrations[current-1]
+		if err := apply(db, m.Down, `DELETE FROM schema_migrations WHERE version = ?`, m.Version); err != nil {
+			return fmt.Errorf("reverting migration %d (%s): %v", m.Version, m.Name, err)
+		}
+	}
+	return nil
+}
+
+// apply runs script and then record in one transaction.
+func apply(db *sql.DB, script, record string, args ...interface{}) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for _, stmt := range statements(script) {
+		if _, err := tx.Exec(stmt); err != nil {
+			return fmt.Errorf("%v, running %q", err, stmt)
+		}
+	}
+	if _, err := tx.Exec(record, args...); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func execScript(db *sql.DB, script string) error {
+	for _, stmt := range statements(script) {
+		if _, err := db.Exec(stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// statements splits a script on semicolons, dropping empty statements.
+func statements(script string) []string {
+	var list []string
+	for _, stmt := range strings.Split(script, ";") {
+		if stmt = strings.TrimSpace(stmt); stmt != "" {
+			list = append(list, stmt)
+		}
+	}
+	return list
+}
+
+// MigrationStatus is how one migration stands in a database.
+type MigrationStatus struct {
+	Version int
+	Name    string
+	// AppliedAt is zero while the migration is pending.
+	AppliedAt time.Time
+	// Changed is set when the migration has been edited since it was
+	// applied, and Unknown when it was applied by a build which had it
+	// but this one doesn't.
+	Changed bool
+	Unknown bool
+}
+
+// Status lists every migration either built in or applied to db, in
+// version order.
+func Status(db *sql.DB) ([]MigrationStatus, error) {
+	if err := execScript(db, bootstrap); err != nil {
+		return nil, fmt.Errorf("creating migration tables: %v", err)
+	}
+	return status(db)
+}
+
+// status is Status for databases which may not have been bootstrapped,
+// and which mustn't be written to. It treats a missing schema_migrations
+// table as nothing applied.
+func status(db *sql.DB) ([]MigrationStatus, error) {
+	byVersion := map[int]*MigrationStatus{}
+	for _, m := range migrations {
+		byVersion[m.Version] = &MigrationStatus{Version: m.Version, Name: m.Name}
+	}
+
+	if exists, err := tableExists(db, "schema_migrations"); err != nil {
+		return nil, err
+	} else if exists {
+		rows, err := db.Query(`SELECT version, name, checksum, applied_at FROM schema_migrations ORDER BY version`)
+		if err != nil {
+			return nil, fmt.Errorf("reading schema_migrations: %v", err)
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var version int
+			var name, checksum string
+			var at int64
+			if err := rows.Scan(&version, &name, &checksum, &at); err != nil {
+				return nil, fmt.Errorf("reading schema_migrations: %v", err)
+			}
+			st := byVersion[version]
+			if st == nil || st.Name != name {
+				st = &MigrationStatus{Version: version, Name: name, Unknown: true}
+				byVersion[version] = st
+			} else if migrations[version-1].Checksum() != checksum {
+				st.Changed = true
+			}
+			st.AppliedAt = time.Unix(at, 0).UTC()
+		}
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("reading schema_migrations: %v", err)
+		}
+	}
+
+	list := make([]MigrationStatus, 0, len(byVersion))
+	for _, st := range byVersion {
+		list = append(list, *st)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Version < list[j].Version })
+	return list, nil
+}
+
+// tableExists reports whether db has the table, by selecting nothing from
+// it. Any failure counts as the table missing, as drivers differ in how
+// they say so.
+func tableExists(db *sql.DB, table string) (bool, error) {
+	rows, err := db.Query(`SELECT 1 FROM ` + table + ` WHERE 1 = 0`)
+	if err != nil {
+		return false, nil
+	}
+	return true, rows.Close()
+}
+
+// columns is the schema the store's queries rely on, as left by the
+// latest migration. Check looks for each column so a database changed by
+// hand is caught at startup rather than on the first write.
+var columns = map[string][]string{
+	"tasks":  {"id", "data", "status", "assignee", "project", "due_at"},
+	"blobs":  {"name", "data"},
+	"meta":   {"name", "value"},
+	"outbox": {"seq", "data"},
+}
+
+// DriftError is returned by Check when a database's schema isn't the one
+// this build expects.
+type DriftError struct {
+	// Version is the database's schema version, counting only
+	// migrations applied in sequence.
+	Version  int
+	Problems []string
+}
+
+func (e *DriftError) Error() string {
+	return fmt.Sprintf("database schema doesn't match this build (database at version %d, build expects %d):\n\t%s",
+		e.Version, Latest(), strings.Join(e.Problems, "\n\t"))
+}
+
+// Check compares db's schema with the one this build expects without
+// changing anything, returning a *DriftError listing every difference.
+func Check(db *sql.DB) error {
+	status, err := status(db)
+	if err != nil {
+		return err
+	}
+	drift := &DriftError{}
+	var pending []string
+	for _, st := range status {
+		switch {
+		case st.Unknown:
+			drift.Problems = append(drift.Problems, fmt.Sprintf(
+				"migration %d (%s) was applied by another build; upgrade this server or revert it with the build which applied it", st.Version, st.Name))
+		case st.Changed:
+			drift.Problems = append(drift.Problems, fmt.Sprintf(
+				"migration %d (%s) has been edited since it was applied", st.Version, st.Name))
+		case st.AppliedAt.IsZero():
+			pending = append(pending, fmt.Sprintf("%d (%s)", st.Version, st.Name))
+		}
+		if !st.AppliedAt.IsZero() && st.Version == drift.Version+1 {
+			drift.Version = st.Version
+		}
+	}
+	if len(pending) > 0 {
+		drift.Problems = append(drift.Problems, fmt.Sprintf(
+			"migrations %s haven't been applied; run `grpc migrate up`", strings.Join(pending, ", ")))
+	}
+
+	// Only look at the tables once the migrations match, since until
+	// then they're expected to differ.
+	if len(drift.Problems) == 0 {
+		tables := make([]string, 0, len(columns))
+		for table := range columns {
+			tables = append(tables, table)
+		}
+		sort.Strings(tables)
+		for _, table := range tables {
+			rows, err := db.Query(`SELECT ` + strings.Join(columns[table], ", ") + ` FROM ` + table + ` WHERE 1 = 0`)
+			if err != nil {
+				drift.Problems = append(drift.Problems, fmt.Sprintf(
+					"table %s doesn't have the columns %s: %v", table, strings.Join(columns[table], ", "), err))
+				continue
+			}
+			rows.Close()
+		}
+	}
+
+	if len(drift.Problems) > 0 {
+		return drift
+	}
+	return nil
+}
@@ -0,0 +1,3 @@
+DROP TABLE meta;
+DROP TABLE blobs;
+DROP TABLE tasks;
@@ -0,0 +1,18 @@
+CREATE TABLE tasks (
+	id INTEGER PRIMARY KEY,
+	data BLOB NOT NULL,
+	status INTEGER NOT NULL,
+	assignee TEXT NOT NULL DEFAULT '',
+	project TEXT NOT NULL DEFAULT '',
+	due_at INTEGER
+);
+
+CREATE TABLE blobs (
+	name TEXT PRIMARY KEY,
+	data BLOB NOT NULL
+);
+
+CREATE TABLE meta (
+	name TEXT PRIMARY KEY,
+	value BLOB NOT NULL
+);
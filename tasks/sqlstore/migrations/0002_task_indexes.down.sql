@@ -0,0 +1,3 @@
+DROP INDEX tasks_by_due;
+DROP INDEX tasks_by_assignee;
+DROP INDEX tasks_by_status;
@@ -0,0 +1,3 @@
+CREATE INDEX tasks_by_status ON tasks (status, id);
+CREATE INDEX tasks_by_assignee ON tasks (assignee, id);
+CREATE INDEX tasks_by_due ON tasks (due_at, id);
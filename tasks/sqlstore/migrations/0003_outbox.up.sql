@@ -0,0 +1,4 @@
+CREATE TABLE outbox (
+	seq INTEGER PRIMARY KEY,
+	data BLOB NOT NULL
+);
@@ -0,0 +1,338 @@
+// Package sqlstore keeps tasks in a relational database through
+// database/sql. Tasks are stored as protobuf, alongside indexed columns
+// for their status, assignee and due date. The store itself only reads
+// tasks all at once, as the TaskService holds them in memory, but the
+// columns make the database easy to query directly. The SQL is written
+// for SQLite, which with a pure-Go driver such as modernc.org/sqlite needs
+// nothing but a file; use WAL mode so reads don't wait on writes.
+//
+// The schema is built by the migrations embedded in the package, which
+// are applied with MigrateTo or `grpc migrate`. Opening a store never
+// changes the schema: it checks it instead, and refuses a database whose
+// schema doesn't match this build.
+//
+// Importing the package registers it with tasks.OpenStore as "sql",
+// taking a database/sql driver name and data source, as in
+// "sql:sqlite:/var/lib/tasks.db". The driver itself must be imported by
+// the program.
+package sqlstore
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/andyantrim/grpc-example/tasks"
+	"google.golang.org/protobuf/proto"
+)
+
+func init() {
+	tasks.RegisterStore("sql", func(addr string) (tasks.Store, error) {
+		return OpenConfig(addr)
+	})
+}
+
+// OpenDB opens the database described by addr, a driver name and data
+// source separated by a colon, as in "sqlite:/var/lib/tasks.db".
+func OpenDB(addr string) (*sql.DB, error) {
+	i := strings.IndexByte(addr, ':')
+	if i <= 0 {
+		return nil, errors.New("sql store needs a driver and data source, as in sql:sqlite:/var/lib/tasks.db")
+	}
+	driver, dsn := addr[:i], addr[i+1:]
+	if !hasDriver(driver) {
+		return nil, fmt.Errorf("no %q database driver is built in, only [%s]; the server links SQLite as \"sqlite\"",
+			driver, strings.Join(sql.Drivers(), " "))
+	}
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("connecting to %s database: %v", driver, err)
+	}
+	return db, nil
+}
+
+func hasDriver(name string) bool {
+	for _, d := range sql.Drivers() {
+		if d == name {
+			return true
+		}
+	}
+	return false
+}
+
+// OpenConfig opens the store described by addr, as OpenDB does.
+func OpenConfig(addr string) (*Store, error) {
+	db, err := OpenDB(addr)
+	if err != nil {
+		return nil, err
+	}
+	s, err := Open(db)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+type Store struct {
+	db *sql.DB
+	// writer keeps to one writable transaction at a time, as tasks.Store
+	// promises. SQLite would otherwise fail the second with "database is
+	// locked" rather than wait.
+	writer sync.Mutex
+}
+
+// Open uses db as a store, first checking its schema is the one this
+// build expects. The store takes ownership of db, closing it on Close.
+func Open(db *sql.DB) (*Store, error) {
+	if err := Check(db); err != nil {
+		return nil, err
+	}
+	return &Store{db: db}, nil
+}
+
+func (s *Store) Begin(writable bool) (tasks.Tx, error) {
+	if writable {
+		s.writer.Lock()
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		if writable {
+			s.writer.Unlock()
+		}
+		return nil, err
+	}
+	return &Tx{tx: tx, store: s, writable: writable}, nil
+}
+
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Tx is a database transaction.
+type Tx struct {
+	tx       *sql.Tx
+	store    *Store
+	writable bool
+	done     bool
+}
+
+func (t *Tx) Get(id int64) (*tasks.Task, error) {
+	if t.done {
+		return nil, tasks.ErrTxDone
+	}
+	var b []byte
+	err := t.tx.QueryRow(`SELECT data FROM tasks WHERE id = ?`, id).Scan(&b)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, tasks.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	task := &tasks.Task{}
+	if err := proto.Unmarshal(b, task); err != nil {
+		return nil, fmt.Errorf("decoding task %d: %v", id, err)
+	}
+	return task, nil
+}
+
+func (t *Tx) List(after int64, limit int) ([]*tasks.Task, error) {
+	if t.done {
+		return nil, tasks.ErrTxDone
+	}
+	rows, err := t.query(`SELECT id, data FROM tasks WHERE id > ? ORDER BY id`, limit, after)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []*tasks.Task
+	for rows.Next() {
+		var id int64
+		var b []byte
+		if err := rows.Scan(&id, &b); err != nil {
+			return nil, err
+		}
+		task := &tasks.Task{}
+		if err := proto.Unmarshal(b, task); err != nil {
+			return nil, fmt.Errorf("decoding task %d: %v", id, err)
+		}
+		list = append(list, task)
+	}
+	return list, rows.Err()
+}
+
+func (t *Tx) Put(task *tasks.Task) error {
+	if err := t.check(); err != nil {
+		return err
+	}
+	b, err := proto.Marshal(task)
+	if err != nil {
+		return err
+	}
+	var due interface{}
+	if task.DueAt != nil {
+		due = task.DueAt.AsTime().UnixNano()
+	}
+	_, err = t.tx.Exec(`
+		INSERT INTO tasks (id, data, status, assignee, project, due_at) VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET
+			data = excluded.data, status = excluded.status, assignee = excluded.assignee,
+			project = excluded.project, due_at = excluded.due_at`,
+		task.Id, b, int32(task.Status), task.Assignee, task.Project, due)
+	return err
+}
+
+func (t *Tx) Delete(id int64) error {
+	if err := t.check(); err != nil {
+		return err
+	}
+	_, err := t.tx.Exec(`DELETE FROM tasks WHERE id = ?`, id)
+	return err
+}
+
+func (t *Tx) GetBlob(key string) ([]byte, error) {
+	return t.getBytes(`SELECT data FROM blobs WHERE name = ?`, key)
+}
+
+func (t *Tx) PutBlob(key string, data []byte) error {
+	return t.putBytes(`INSERT INTO blobs (name, data) VALUES (?, ?) ON CONFLICT (name) DO UPDATE SET data = excluded.data`, key, data)
+}
+
+func (t *Tx) GetMeta(key string) ([]byte, error) {
+	return t.getBytes(`SELECT value FROM meta WHERE name = ?`, key)
+}
+
+func (t *Tx) PutMeta(key string, value []byte) error {
+	return t.putBytes(`INSERT INTO meta (name, value) VALUES (?, ?) ON CONFLICT (name) DO UPDATE SET value = excluded.value`, key, value)
+}
+
+func (t *Tx) ListMeta() ([]string, error) {
+	if t.done {
+		return nil, tasks.ErrTxDone
+	}
+	rows, err := t.query(`SELECT name FROM meta ORDER BY name`, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var keys []string
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+func (t *Tx) PutOutbox(seq int64, data []byte) error {
+	if err := t.check(); err != nil {
+		return err
+	}
+	if data == nil {
+		data = []byte{}
+	}
+	_, err := t.tx.Exec(`INSERT INTO outbox (seq, data) VALUES (?, ?) ON CONFLICT (seq) DO UPDATE SET data = excluded.data`, seq, data)
+	return err
+}
+
+func (t *Tx) ListOutbox(after int64, limit int) ([]tasks.OutboxEntry, error) {
+	if t.done {
+		return nil, tasks.ErrTxDone
+	}
+	rows, err := t.query(`SELECT seq, data FROM outbox WHERE seq > ? ORDER BY seq`, limit, after)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []tasks.OutboxEntry
+	for rows.Next() {
+		var e tasks.OutboxEntry
+		if err := rows.Scan(&e.Seq, &e.Data); err != nil {
+			return nil, err
+		}
+		list = append(list, e)
+	}
+	return list, rows.Err()
+}
+
+func (t *Tx) TrimOutbox(upTo int64) error {
+	if err := t.check(); err != nil {
+		return err
+	}
+	_, err := t.tx.Exec(`DELETE FROM outbox WHERE seq <= ?`, upTo)
+	return err
+}
+
+func (t *Tx) getBytes(query, key string) ([]byte, error) {
+	if t.done {
+		return nil, tasks.ErrTxDone
+	}
+	var b []byte
+	err := t.tx.QueryRow(query, key).Scan(&b)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, tasks.ErrNotFound
+	}
+	return b, err
+}
+
+func (t *Tx) putBytes(query, key string, value []byte) error {
+	if err := t.check(); err != nil {
+		return err
+	}
+	// Drivers bind a nil slice as NULL, which the columns don't allow.
+	if value == nil {
+		value = []byte{}
+	}
+	_, err := t.tx.Exec(query, key, value)
+	return err
+}
+
+func (t *Tx) Commit() error {
+	if t.done {
+		return tasks.ErrTxDone
+	}
+	t.done = true
+	if !t.writable {
+		return t.tx.Rollback()
+	}
+	defer t.store.writer.Unlock()
+	return t.tx.Commit()
+}
+
+func (t *Tx) Rollback() error {
+	if t.done {
+		return nil
+	}
+	t.done = true
+	if t.writable {
+		defer t.store.writer.Unlock()
+	}
+	return t.tx.Rollback()
+}
+
+func (t *Tx) check() error {
+	if t.done {
+		return tasks.ErrTxDone
+	}
+	if !t.writable {
+		return tasks.ErrReadOnly
+	}
+	return nil
+}
+
+// query runs query with a LIMIT added when limit is above 0.
+func (t *Tx) query(query string, limit int, args ...interface{}) (*sql.Rows, error) {
+	if limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, limit)
+	}
+	return t.tx.Query(query, args...)
+}
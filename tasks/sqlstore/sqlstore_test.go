@@ -0,0 +1,49 @@
+package sqlstore
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/andyantrim/grpc-example/tasks"
+	"github.com/andyantrim/grpc-example/tasks/storetest"
+	_ "modernc.org/sqlite"
+)
+
+// open migrates the SQLite database at path to the latest schema and opens
+// it as a store.
+func open(t *testing.T, path string) *Store {
+	t.Helper()
+	addr := "sqlite:file:" + path + "?_pragma=journal_mode(WAL)"
+	db, err := OpenDB(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := MigrateTo(db, Latest()); err != nil {
+		t.Fatal(err)
+	}
+	s, err := Open(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestStore(t *testing.T) {
+	paths := make(map[tasks.Store]string)
+	storetest.Run(t, storetest.Backend{
+		Open: func(t *testing.T) tasks.Store {
+			path := filepath.Join(t.TempDir(), "tasks.db")
+			s := open(t, path)
+			paths[s] = path
+			return s
+		},
+		Reopen: func(t *testing.T, s tasks.Store) tasks.Store {
+			if err := s.Close(); err != nil {
+				t.Fatal(err)
+			}
+			reopened := open(t, paths[s])
+			paths[reopened] = paths[s]
+			return reopened
+		},
+	})
+}
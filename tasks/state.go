@@ -0,0 +1,118 @@
+package tasks
+
+import (
+	"errors"
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// Besides the tasks, the server keeps data such as custom field
+// definitions, boards and notifications. Each part is a state, held in
+// memory under the task lock like the tasks are and saved in the store's
+// meta under keys of its own. A change to a state is saved in the same
+// transaction as the task changes made with it, and like them is thrown
+// away by reloading if the change fails.
+
+// A state is a part of the server's data saved alongside the tasks. Its
+// methods are called with the task lock held.
+type state interface {
+	// load replaces the state with what tx holds, which may be nothing.
+	load(tx Tx) error
+	// save writes what has changed since the state was loaded or last
+	// saved.
+	save(tx Tx) error
+}
+
+// initStates gives s a new, empty instance of every state.
+func (s *TaskService) initStates() {
+	s.fields = &fieldState{}
+	s.calendars = &calendarState{}
+	s.boards = &boardState{}
+	s.views = &viewState{}
+	s.templates = &templateState{}
+	s.inbox = &inboxState{}
+	s.archive = &archiveState{}
+	s.time = &timeState{}
+	s.stats = &statsState{}
+	s.states = []state{s.fields, s.calendars, s.boards, s.views, s.templates, s.inbox, s.archive, s.time, s.stats}
+}
+
+// readStates returns the meta values every state is saved under, as read
+// from tx.
+func readStates(tx Tx) (map[string][]byte, error) {
+	var fresh TaskService
+	fresh.initStates()
+	rtx := recordTx{Tx: tx, meta: make(map[string][]byte)}
+	for _, st := range fresh.states {
+		if err := st.load(rtx); err != nil {
+			return nil, err
+		}
+	}
+	return rtx.meta, nil
+}
+
+// stateChangedLocked queues st to be saved with the current change. s.mu
+// must be held.
+func (s *TaskService) stateChangedLocked(st state) {
+	s.dirty[st] = true
+}
+
+// getState reads the message saved under key into m, leaving m empty if
+// there isn't one.
+func getState(tx Tx, key string, m proto.Message) error {
+	b, err := tx.GetMeta(key)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := proto.Unmarshal(b, m); err != nil {
+		return fmt.Errorf("reading %s: %v", key, err)
+	}
+	return nil
+}
+
+func putState(tx Tx, key string, m proto.Message) error {
+	b, err := proto.Marshal(m)
+	if err != nil {
+		return err
+	}
+	return tx.PutMeta(key, b)
+}
+
+// recordTx reads through to a Tx, keeping a copy of every meta value
+// read.
+type recordTx struct {
+	Tx
+	meta map[string][]byte
+}
+
+func (tx recordTx) GetMeta(key string) ([]byte, error) {
+	b, err := tx.Tx.GetMeta(key)
+	if err == nil {
+		tx.meta[key] = b
+	}
+	return b, err
+}
+
+// metaTx holds meta values in memory, to load and save states outside a
+// store. Only its meta methods can be used.
+type metaTx struct {
+	Tx
+	meta map[string][]byte
+}
+
+func (tx metaTx) GetMeta(key string) ([]byte, error) {
+	b, ok := tx.meta[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return b, nil
+}
+
+func (tx metaTx) PutMeta(key string, value []byte) error {
+	tx.meta[key] = value
+	return nil
+}
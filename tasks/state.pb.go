@@ -0,0 +1,957 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.27.1
+// 	protoc        (unknown)
+// source: tasks/state.proto
+
+package tasks
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type StoredFields struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Fields []*CustomFieldDefinition `protobuf:"bytes,1,rep,name=fields,proto3" json:"fields,omitempty"`
+}
+
+func (x *StoredFields) Reset() {
+	*x = StoredFields{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_tasks_state_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StoredFields) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StoredFields) ProtoMessage() {}
+
+func (x *StoredFields) ProtoReflect() protoreflect.Message {
+	mi := &file_tasks_state_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StoredFields.ProtoReflect.Descriptor instead.
+func (*StoredFields) Descriptor() ([]byte, []int) {
+	return file_tasks_state_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *StoredFields) GetFields() []*CustomFieldDefinition {
+	if x != nil {
+		return x.Fields
+	}
+	return nil
+}
+
+type StoredCalendars struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Calendars []*Calendar `protobuf:"bytes,1,rep,name=calendars,proto3" json:"calendars,omitempty"`
+}
+
+func (x *StoredCalendars) Reset() {
+	*x = StoredCalendars{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_tasks_state_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StoredCalendars) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StoredCalendars) ProtoMessage() {}
+
+func (x *StoredCalendars) ProtoReflect() protoreflect.Message {
+	mi := &file_tasks_state_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StoredCalendars.ProtoReflect.Descriptor instead.
+func (*StoredCalendars) Descriptor() ([]byte, []int) {
+	return file_tasks_state_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *StoredCalendars) GetCalendars() []*Calendar {
+	if x != nil {
+		return x.Calendars
+	}
+	return nil
+}
+
+type StoredBoards struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Boards []*Board `protobuf:"bytes,1,rep,name=boards,proto3" json:"boards,omitempty"`
+	LastId int64    `protobuf:"varint,2,opt,name=last_id,json=lastId,proto3" json:"last_id,omitempty"`
+}
+
+func (x *StoredBoards) Reset() {
+	*x = StoredBoards{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_tasks_state_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StoredBoards) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StoredBoards) ProtoMessage() {}
+
+func (x *StoredBoards) ProtoReflect() protoreflect.Message {
+	mi := &file_tasks_state_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StoredBoards.ProtoReflect.Descriptor instead.
+func (*StoredBoards) Descriptor() ([]byte, []int) {
+	return file_tasks_state_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *StoredBoards) GetBoards() []*Board {
+	if x != nil {
+		return x.Boards
+	}
+	return nil
+}
+
+func (x *StoredBoards) GetLastId() int64 {
+	if x != nil {
+		return x.LastId
+	}
+	return 0
+}
+
+type StoredViews struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Views  []*View `protobuf:"bytes,1,rep,name=views,proto3" json:"views,omitempty"`
+	LastId int64   `protobuf:"varint,2,opt,name=last_id,json=lastId,proto3" json:"last_id,omitempty"`
+}
+
+func (x *StoredViews) Reset() {
+	*x = StoredViews{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_tasks_state_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StoredViews) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StoredViews) ProtoMessage() {}
+
+func (x *StoredViews) ProtoReflect() protoreflect.Message {
+	mi := &file_tasks_state_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StoredViews.ProtoReflect.Descriptor instead.
+func (*StoredViews) Descriptor() ([]byte, []int) {
+	return file_tasks_state_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *StoredViews) GetViews() []*View {
+	if x != nil {
+		return x.Views
+	}
+	return nil
+}
+
+func (x *StoredViews) GetLastId() int64 {
+	if x != nil {
+		return x.LastId
+	}
+	return 0
+}
+
+type StoredTemplates struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Templates []*Template `protobuf:"bytes,1,rep,name=templates,proto3" json:"templates,omitempty"`
+}
+
+func (x *StoredTemplates) Reset() {
+	*x = StoredTemplates{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_tasks_state_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StoredTemplates) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StoredTemplates) ProtoMessage() {}
+
+func (x *StoredTemplates) ProtoReflect() protoreflect.Message {
+	mi := &file_tasks_state_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StoredTemplates.ProtoReflect.Descriptor instead.
+func (*StoredTemplates) Descriptor() ([]byte, []int) {
+	return file_tasks_state_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *StoredTemplates) GetTemplates() []*Template {
+	if x != nil {
+		return x.Templates
+	}
+	return nil
+}
+
+type StoredArchiveSettings struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Settings []*ArchiveSettings `protobuf:"bytes,1,rep,name=settings,proto3" json:"settings,omitempty"`
+}
+
+func (x *StoredArchiveSettings) Reset() {
+	*x = StoredArchiveSettings{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_tasks_state_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StoredArchiveSettings) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StoredArchiveSettings) ProtoMessage() {}
+
+func (x *StoredArchiveSettings) ProtoReflect() protoreflect.Message {
+	mi := &file_tasks_state_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StoredArchiveSettings.ProtoReflect.Descriptor instead.
+func (*StoredArchiveSettings) Descriptor() ([]byte, []int) {
+	return file_tasks_state_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *StoredArchiveSettings) GetSettings() []*ArchiveSettings {
+	if x != nil {
+		return x.Settings
+	}
+	return nil
+}
+
+// StoredTime is the running timers, with the work logs saved separately
+// for each UTC day they started on.
+type StoredTime struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Timers    []*Timer `protobuf:"bytes,1,rep,name=timers,proto3" json:"timers,omitempty"`
+	LastLogId int64    `protobuf:"varint,2,opt,name=last_log_id,json=lastLogId,proto3" json:"last_log_id,omitempty"`
+	// log_days are the days with work logs, as YYYY-MM-DD.
+	LogDays []string `protobuf:"bytes,3,rep,name=log_days,json=logDays,proto3" json:"log_days,omitempty"`
+}
+
+func (x *StoredTime) Reset() {
+	*x = StoredTime{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_tasks_state_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StoredTime) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StoredTime) ProtoMessage() {}
+
+func (x *StoredTime) ProtoReflect() protoreflect.Message {
+	mi := &file_tasks_state_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StoredTime.ProtoReflect.Descriptor instead.
+func (*StoredTime) Descriptor() ([]byte, []int) {
+	return file_tasks_state_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *StoredTime) GetTimers() []*Timer {
+	if x != nil {
+		return x.Timers
+	}
+	return nil
+}
+
+func (x *StoredTime) GetLastLogId() int64 {
+	if x != nil {
+		return x.LastLogId
+	}
+	return 0
+}
+
+func (x *StoredTime) GetLogDays() []string {
+	if x != nil {
+		return x.LogDays
+	}
+	return nil
+}
+
+type StoredWorkLogs struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Logs []*WorkLog `protobuf:"bytes,1,rep,name=logs,proto3" json:"logs,omitempty"`
+}
+
+func (x *StoredWorkLogs) Reset() {
+	*x = StoredWorkLogs{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_tasks_state_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StoredWorkLogs) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StoredWorkLogs) ProtoMessage() {}
+
+func (x *StoredWorkLogs) ProtoReflect() protoreflect.Message {
+	mi := &file_tasks_state_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StoredWorkLogs.ProtoReflect.Descriptor instead.
+func (*StoredWorkLogs) Descriptor() ([]byte, []int) {
+	return file_tasks_state_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *StoredWorkLogs) GetLogs() []*WorkLog {
+	if x != nil {
+		return x.Logs
+	}
+	return nil
+}
+
+// StoredStatsDay is the status counts at the end of a day, for each
+// project whose counts changed that day. "" holds the totals for all.
+type StoredStatsDay struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Projects map[string]*StatusCounts `protobuf:"bytes,1,rep,name=projects,proto3" json:"projects,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+}
+
+func (x *StoredStatsDay) Reset() {
+	*x = StoredStatsDay{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_tasks_state_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StoredStatsDay) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StoredStatsDay) ProtoMessage() {}
+
+func (x *StoredStatsDay) ProtoReflect() protoreflect.Message {
+	mi := &file_tasks_state_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StoredStatsDay.ProtoReflect.Descriptor instead.
+func (*StoredStatsDay) Descriptor() ([]byte, []int) {
+	return file_tasks_state_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *StoredStatsDay) GetProjects() map[string]*StatusCounts {
+	if x != nil {
+		return x.Projects
+	}
+	return nil
+}
+
+type StatusCounts struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ByStatus map[string]int64 `protobuf:"bytes,1,rep,name=by_status,json=byStatus,proto3" json:"by_status,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3"`
+}
+
+func (x *StatusCounts) Reset() {
+	*x = StatusCounts{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_tasks_state_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StatusCounts) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StatusCounts) ProtoMessage() {}
+
+func (x *StatusCounts) ProtoReflect() protoreflect.Message {
+	mi := &file_tasks_state_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StatusCounts.ProtoReflect.Descriptor instead.
+func (*StatusCounts) Descriptor() ([]byte, []int) {
+	return file_tasks_state_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *StatusCounts) GetByStatus() map[string]int64 {
+	if x != nil {
+		return x.ByStatus
+	}
+	return nil
+}
+
+// StoredInbox is one user's notifications, oldest first.
+type StoredInbox struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Notifications []*Notification `protobuf:"bytes,1,rep,name=notifications,proto3" json:"notifications,omitempty"`
+	// last_id is the last notification id handed out to anyone when the
+	// inbox was saved.
+	LastId int64 `protobuf:"varint,2,opt,name=last_id,json=lastId,proto3" json:"last_id,omitempty"`
+}
+
+func (x *StoredInbox) Reset() {
+	*x = StoredInbox{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_tasks_state_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StoredInbox) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StoredInbox) ProtoMessage() {}
+
+func (x *StoredInbox) ProtoReflect() protoreflect.Message {
+	mi := &file_tasks_state_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StoredInbox.ProtoReflect.Descriptor instead.
+func (*StoredInbox) Descriptor() ([]byte, []int) {
+	return file_tasks_state_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *StoredInbox) GetNotifications() []*Notification {
+	if x != nil {
+		return x.Notifications
+	}
+	return nil
+}
+
+func (x *StoredInbox) GetLastId() int64 {
+	if x != nil {
+		return x.LastId
+	}
+	return 0
+}
+
+// StoredNames lists the names a part is split across, such as the users
+// with an inbox.
+type StoredNames struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Names []string `protobuf:"bytes,1,rep,name=names,proto3" json:"names,omitempty"`
+}
+
+func (x *StoredNames) Reset() {
+	*x = StoredNames{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_tasks_state_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StoredNames) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StoredNames) ProtoMessage() {}
+
+func (x *StoredNames) ProtoReflect() protoreflect.Message {
+	mi := &file_tasks_state_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StoredNames.ProtoReflect.Descriptor instead.
+func (*StoredNames) Descriptor() ([]byte, []int) {
+	return file_tasks_state_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *StoredNames) GetNames() []string {
+	if x != nil {
+		return x.Names
+	}
+	return nil
+}
+
+var File_tasks_state_proto protoreflect.FileDescriptor
+
+var file_tasks_state_proto_rawDesc = []byte{
+	0x0a, 0x11, 0x74, 0x61, 0x73, 0x6b, 0x73, 0x2f, 0x73, 0x74, 0x61, 0x74, 0x65, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x12, 0x04, 0x74, 0x61, 0x73, 0x6b, 0x1a, 0x13, 0x74, 0x61, 0x73, 0x6b, 0x73,
+	0x2f, 0x61, 0x72, 0x63, 0x68, 0x69, 0x76, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x11,
+	0x74, 0x61, 0x73, 0x6b, 0x73, 0x2f, 0x62, 0x6f, 0x61, 0x72, 0x64, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x1a, 0x14, 0x74, 0x61, 0x73, 0x6b, 0x73, 0x2f, 0x63, 0x61, 0x6c, 0x65, 0x6e, 0x64, 0x61,
+	0x72, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x17, 0x74, 0x61, 0x73, 0x6b, 0x73, 0x2f, 0x63,
+	0x75, 0x73, 0x74, 0x6f, 0x6d, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x1a, 0x18, 0x74, 0x61, 0x73, 0x6b, 0x73, 0x2f, 0x6e, 0x6f, 0x74, 0x69, 0x66, 0x69, 0x63, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x14, 0x74, 0x61, 0x73, 0x6b,
+	0x73, 0x2f, 0x74, 0x65, 0x6d, 0x70, 0x6c, 0x61, 0x74, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x1a, 0x11, 0x74, 0x61, 0x73, 0x6b, 0x73, 0x2f, 0x74, 0x69, 0x6d, 0x65, 0x72, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x1a, 0x10, 0x74, 0x61, 0x73, 0x6b, 0x73, 0x2f, 0x76, 0x69, 0x65, 0x77, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x43, 0x0a, 0x0c, 0x53, 0x74, 0x6f, 0x72, 0x65, 0x64, 0x46,
+	0x69, 0x65, 0x6c, 0x64, 0x73, 0x12, 0x33, 0x0a, 0x06, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x73, 0x18,
+	0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x74, 0x61, 0x73, 0x6b, 0x2e, 0x43, 0x75, 0x73,
+	0x74, 0x6f, 0x6d, 0x46, 0x69, 0x65, 0x6c, 0x64, 0x44, 0x65, 0x66, 0x69, 0x6e, 0x69, 0x74, 0x69,
+	0x6f, 0x6e, 0x52, 0x06, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x73, 0x22, 0x3f, 0x0a, 0x0f, 0x53, 0x74,
+	0x6f, 0x72, 0x65, 0x64, 0x43, 0x61, 0x6c, 0x65, 0x6e, 0x64, 0x61, 0x72, 0x73, 0x12, 0x2c, 0x0a,
+	0x09, 0x63, 0x61, 0x6c, 0x65, 0x6e, 0x64, 0x61, 0x72, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x0e, 0x2e, 0x74, 0x61, 0x73, 0x6b, 0x2e, 0x43, 0x61, 0x6c, 0x65, 0x6e, 0x64, 0x61, 0x72,
+	0x52, 0x09, 0x63, 0x61, 0x6c, 0x65, 0x6e, 0x64, 0x61, 0x72, 0x73, 0x22, 0x4c, 0x0a, 0x0c, 0x53,
+	0x74, 0x6f, 0x72, 0x65, 0x64, 0x42, 0x6f, 0x61, 0x72, 0x64, 0x73, 0x12, 0x23, 0x0a, 0x06, 0x62,
+	0x6f, 0x61, 0x72, 0x64, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x74, 0x61,
+	0x73, 0x6b, 0x2e, 0x42, 0x6f, 0x61, 0x72, 0x64, 0x52, 0x06, 0x62, 0x6f, 0x61, 0x72, 0x64, 0x73,
+	0x12, 0x17, 0x0a, 0x07, 0x6c, 0x61, 0x73, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x06, 0x6c, 0x61, 0x73, 0x74, 0x49, 0x64, 0x22, 0x48, 0x0a, 0x0b, 0x53, 0x74, 0x6f,
+	0x72, 0x65, 0x64, 0x56, 0x69, 0x65, 0x77, 0x73, 0x12, 0x20, 0x0a, 0x05, 0x76, 0x69, 0x65, 0x77,
+	0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0a, 0x2e, 0x74, 0x61, 0x73, 0x6b, 0x2e, 0x56,
+	0x69, 0x65, 0x77, 0x52, 0x05, 0x76, 0x69, 0x65, 0x77, 0x73, 0x12, 0x17, 0x0a, 0x07, 0x6c, 0x61,
+	0x73, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x06, 0x6c, 0x61, 0x73,
+	0x74, 0x49, 0x64, 0x22, 0x3f, 0x0a, 0x0f, 0x53, 0x74, 0x6f, 0x72, 0x65, 0x64, 0x54, 0x65, 0x6d,
+	0x70, 0x6c, 0x61, 0x74, 0x65, 0x73, 0x12, 0x2c, 0x0a, 0x09, 0x74, 0x65, 0x6d, 0x70, 0x6c, 0x61,
+	0x74, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x74, 0x61, 0x73, 0x6b,
+	0x2e, 0x54, 0x65, 0x6d, 0x70, 0x6c, 0x61, 0x74, 0x65, 0x52, 0x09, 0x74, 0x65, 0x6d, 0x70, 0x6c,
+	0x61, 0x74, 0x65, 0x73, 0x22, 0x4a, 0x0a, 0x15, 0x53, 0x74, 0x6f, 0x72, 0x65, 0x64, 0x41, 0x72,
+	0x63, 0x68, 0x69, 0x76, 0x65, 0x53, 0x65, 0x74, 0x74, 0x69, 0x6e, 0x67, 0x73, 0x12, 0x31, 0x0a,
+	0x08, 0x73, 0x65, 0x74, 0x74, 0x69, 0x6e, 0x67, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x15, 0x2e, 0x74, 0x61, 0x73, 0x6b, 0x2e, 0x41, 0x72, 0x63, 0x68, 0x69, 0x76, 0x65, 0x53, 0x65,
+	0x74, 0x74, 0x69, 0x6e, 0x67, 0x73, 0x52, 0x08, 0x73, 0x65, 0x74, 0x74, 0x69, 0x6e, 0x67, 0x73,
+	0x22, 0x6c, 0x0a, 0x0a, 0x53, 0x74, 0x6f, 0x72, 0x65, 0x64, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x23,
+	0x0a, 0x06, 0x74, 0x69, 0x6d, 0x65, 0x72, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0b,
+	0x2e, 0x74, 0x61, 0x73, 0x6b, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x72, 0x52, 0x06, 0x74, 0x69, 0x6d,
+	0x65, 0x72, 0x73, 0x12, 0x1e, 0x0a, 0x0b, 0x6c, 0x61, 0x73, 0x74, 0x5f, 0x6c, 0x6f, 0x67, 0x5f,
+	0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x6c, 0x61, 0x73, 0x74, 0x4c, 0x6f,
+	0x67, 0x49, 0x64, 0x12, 0x19, 0x0a, 0x08, 0x6c, 0x6f, 0x67, 0x5f, 0x64, 0x61, 0x79, 0x73, 0x18,
+	0x03, 0x20, 0x03, 0x28, 0x09, 0x52, 0x07, 0x6c, 0x6f, 0x67, 0x44, 0x61, 0x79, 0x73, 0x22, 0x33,
+	0x0a, 0x0e, 0x53, 0x74, 0x6f, 0x72, 0x65, 0x64, 0x57, 0x6f, 0x72, 0x6b, 0x4c, 0x6f, 0x67, 0x73,
+	0x12, 0x21, 0x0a, 0x04, 0x6c, 0x6f, 0x67, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0d,
+	0x2e, 0x74, 0x61, 0x73, 0x6b, 0x2e, 0x57, 0x6f, 0x72, 0x6b, 0x4c, 0x6f, 0x67, 0x52, 0x04, 0x6c,
+	0x6f, 0x67, 0x73, 0x22, 0xa1, 0x01, 0x0a, 0x0e, 0x53, 0x74, 0x6f, 0x72, 0x65, 0x64, 0x53, 0x74,
+	0x61, 0x74, 0x73, 0x44, 0x61, 0x79, 0x12, 0x3e, 0x0a, 0x08, 0x70, 0x72, 0x6f, 0x6a, 0x65, 0x63,
+	0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x22, 0x2e, 0x74, 0x61, 0x73, 0x6b, 0x2e,
+	0x53, 0x74, 0x6f, 0x72, 0x65, 0x64, 0x53, 0x74, 0x61, 0x74, 0x73, 0x44, 0x61, 0x79, 0x2e, 0x50,
+	0x72, 0x6f, 0x6a, 0x65, 0x63, 0x74, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x08, 0x70, 0x72,
+	0x6f, 0x6a, 0x65, 0x63, 0x74, 0x73, 0x1a, 0x4f, 0x0a, 0x0d, 0x50, 0x72, 0x6f, 0x6a, 0x65, 0x63,
+	0x74, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x28, 0x0a, 0x05, 0x76, 0x61, 0x6c,
+	0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x74, 0x61, 0x73, 0x6b, 0x2e,
+	0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x73, 0x52, 0x05, 0x76, 0x61,
+	0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x8a, 0x01, 0x0a, 0x0c, 0x53, 0x74, 0x61, 0x74,
+	0x75, 0x73, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x73, 0x12, 0x3d, 0x0a, 0x09, 0x62, 0x79, 0x5f, 0x73,
+	0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x20, 0x2e, 0x74, 0x61,
+	0x73, 0x6b, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x73, 0x2e,
+	0x42, 0x79, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x08, 0x62,
+	0x79, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x1a, 0x3b, 0x0a, 0x0d, 0x42, 0x79, 0x53, 0x74, 0x61,
+	0x74, 0x75, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61,
+	0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65,
+	0x3a, 0x02, 0x38, 0x01, 0x22, 0x60, 0x0a, 0x0b, 0x53, 0x74, 0x6f, 0x72, 0x65, 0x64, 0x49, 0x6e,
+	0x62, 0x6f, 0x78, 0x12, 0x38, 0x0a, 0x0d, 0x6e, 0x6f, 0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x74, 0x61, 0x73,
+	0x6b, 0x2e, 0x4e, 0x6f, 0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0d,
+	0x6e, 0x6f, 0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x17, 0x0a,
+	0x07, 0x6c, 0x61, 0x73, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x06,
+	0x6c, 0x61, 0x73, 0x74, 0x49, 0x64, 0x22, 0x23, 0x0a, 0x0b, 0x53, 0x74, 0x6f, 0x72, 0x65, 0x64,
+	0x4e, 0x61, 0x6d, 0x65, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x18, 0x01,
+	0x20, 0x03, 0x28, 0x09, 0x52, 0x05, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x42, 0x2a, 0x5a, 0x28, 0x67,
+	0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x61, 0x6e, 0x64, 0x79, 0x61, 0x6e,
+	0x74, 0x72, 0x69, 0x6d, 0x2f, 0x67, 0x72, 0x70, 0x63, 0x5f, 0x65, 0x78, 0x61, 0x6d, 0x70, 0x6c,
+	0x65, 0x2f, 0x74, 0x61, 0x73, 0x6b, 0x73, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_tasks_state_proto_rawDescOnce sync.Once
+	file_tasks_state_proto_rawDescData = file_tasks_state_proto_rawDesc
+)
+
+func file_tasks_state_proto_rawDescGZIP() []byte {
+	file_tasks_state_proto_rawDescOnce.Do(func() {
+		file_tasks_state_proto_rawDescData = protoimpl.X.CompressGZIP(file_tasks_state_proto_rawDescData)
+	})
+	return file_tasks_state_proto_rawDescData
+}
+
+var file_tasks_state_proto_msgTypes = make([]protoimpl.MessageInfo, 14)
+var file_tasks_state_proto_goTypes = []interface{}{
+	(*StoredFields)(nil),          // 0: task.StoredFields
+	(*StoredCalendars)(nil),       // 1: task.StoredCalendars
+	(*StoredBoards)(nil),          // 2: task.StoredBoards
+	(*StoredViews)(nil),           // 3: task.StoredViews
+	(*StoredTemplates)(nil),       // 4: task.StoredTemplates
+	(*StoredArchiveSettings)(nil), // 5: task.StoredArchiveSettings
+	(*StoredTime)(nil),            // 6: task.StoredTime
+	(*StoredWorkLogs)(nil),        // 7: task.StoredWorkLogs
+	(*StoredStatsDay)(nil),        // 8: task.StoredStatsDay
+	(*StatusCounts)(nil),          // 9: task.StatusCounts
+	(*StoredInbox)(nil),           // 10: task.StoredInbox
+	(*StoredNames)(nil),           // 11: task.StoredNames
+	nil,                           // 12: task.StoredStatsDay.ProjectsEntry
+	nil,                           // 13: task.StatusCounts.ByStatusEntry
+	(*CustomFieldDefinition)(nil), // 14: task.CustomFieldDefinition
+	(*Calendar)(nil),              // 15: task.Calendar
+	(*Board)(nil),                 // 16: task.Board
+	(*View)(nil),                  // 17: task.View
+	(*Template)(nil),              // 18: task.Template
+	(*ArchiveSettings)(nil),       // 19: task.ArchiveSettings
+	(*Timer)(nil),                 // 20: task.Timer
+	(*WorkLog)(nil),               // 21: task.WorkLog
+	(*Notification)(nil),          // 22: task.Notification
+}
+var file_tasks_state_proto_depIdxs = []int32{
+	14, // 0: task.StoredFields.fields:type_name -> task.CustomFieldDefinition
+	15, // 1: task.StoredCalendars.calendars:type_name -> task.Calendar
+	16, // 2: task.StoredBoards.boards:type_name -> task.Board
+	17, // 3: task.StoredViews.views:type_name -> task.View
+	18, // 4: task.StoredTemplates.templates:type_name -> task.Template
+	19, // 5: task.StoredArchiveSettings.settings:type_name -> task.ArchiveSettings
+	20, // 6: task.StoredTime.timers:type_name -> task.Timer
+	21, // 7: task.StoredWorkLogs.logs:type_name -> task.WorkLog
+	12, // 8: task.StoredStatsDay.projects:type_name -> task.StoredStatsDay.ProjectsEntry
+	13, // 9: task.StatusCounts.by_status:type_name -> task.StatusCounts.ByStatusEntry
+	22, // 10: task.StoredInbox.notifications:type_name -> task.Notification
+	9,  // 11: task.StoredStatsDay.ProjectsEntry.value:type_name -> task.StatusCounts
+	12, // [12:12] is the sub-list for method output_type
+	12, // [12:12] is the sub-list for method input_type
+	12, // [12:12] is the sub-list for extension type_name
+	12, // [12:12] is the sub-list for extension extendee
+	0,  // [0:12] is the sub-list for field type_name
+}
+
+func init() { file_tasks_state_proto_init() }
+func file_tasks_state_proto_init() {
+	if File_tasks_state_proto != nil {
+		return
+	}
+	file_tasks_archive_proto_init()
+	file_tasks_board_proto_init()
+	file_tasks_calendar_proto_init()
+	file_tasks_customfield_proto_init()
+	file_tasks_notification_proto_init()
+	file_tasks_template_proto_init()
+	file_tasks_timer_proto_init()
+	file_tasks_view_proto_init()
+	if !protoimpl.UnsafeEnabled {
+		file_tasks_state_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StoredFields); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_tasks_state_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StoredCalendars); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_tasks_state_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StoredBoards); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_tasks_state_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StoredViews); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_tasks_state_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StoredTemplates); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_tasks_state_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StoredArchiveSettings); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_tasks_state_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StoredTime); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_tasks_state_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StoredWorkLogs); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_tasks_state_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StoredStatsDay); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_tasks_state_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StatusCounts); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_tasks_state_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StoredInbox); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_tasks_state_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StoredNames); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_tasks_state_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   14,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_tasks_state_proto_goTypes,
+		DependencyIndexes: file_tasks_state_proto_depIdxs,
+		MessageInfos:      file_tasks_state_proto_msgTypes,
+	}.Build()
+	File_tasks_state_proto = out.File
+	file_tasks_state_proto_rawDesc = nil
+	file_tasks_state_proto_goTypes = nil
+	file_tasks_state_proto_depIdxs = nil
+}
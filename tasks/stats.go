@@ -0,0 +1,240 @@
+package tasks
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// maxStatsDays limits how many days of history GetStats returns at once.
+const maxStatsDays = 5 * 366
+
+// projectStats are the running totals for one project.
+type projectStats struct {
+	status   map[string]int64
+	priority map[string]int64
+	label    map[string]int64
+	assignee map[string]int64
+	// days holds the status counts at the end of each day they changed.
+	days map[string]map[string]int64
+}
+
+// statsKey is the meta key listing the days with history. Each day's
+// counts are saved under statsKey, a slash and the day.
+const statsKey = "stats"
+
+// statsState holds the running totals by project, with "" holding the
+// totals for all. Only the history is saved, as the counts follow from the
+// tasks, and only for the days in changed.
+type statsState struct {
+	projects map[string]*projectStats
+	changed  map[string]bool
+	newDays  bool
+}
+
+func (ss *statsState) load(tx Tx) error {
+	days := &StoredNames{}
+	if err := getState(tx, statsKey, days); err != nil {
+		return err
+	}
+	ss.projects = make(map[string]*projectStats)
+	for _, day := range days.Names {
+		stored := &StoredStatsDay{}
+		if err := getState(tx, statsKey+"/"+day, stored); err != nil {
+			return err
+		}
+		for project, counts := range stored.Projects {
+			ss.project(project).days[day] = counts.ByStatus
+		}
+	}
+	ss.changed = make(map[string]bool)
+	ss.newDays = false
+	return nil
+}
+
+func (ss *statsState) save(tx Tx) error {
+	for day := range ss.changed {
+		stored := &StoredStatsDay{Projects: make(map[string]*StatusCounts)}
+		for project, ps := range ss.projects {
+			if counts, ok := ps.days[day]; ok {
+				stored.Projects[project] = &StatusCounts{ByStatus: counts}
+			}
+		}
+		if err := putState(tx, statsKey+"/"+day, stored); err != nil {
+			return err
+		}
+	}
+	if ss.newDays {
+		all := make(map[string]bool)
+		for _, ps := range ss.projects {
+			for day := range ps.days {
+				all[day] = true
+			}
+		}
+		days := &StoredNames{}
+		for day := range all {
+			days.Names = append(days.Names, day)
+		}
+		sort.Strings(days.Names)
+		if err := putState(tx, statsKey, days); err != nil {
+			return err
+		}
+	}
+	ss.changed = make(map[string]bool)
+	ss.newDays = false
+	return nil
+}
+
+func (ss *statsState) project(name string) *projectStats {
+	ps, ok := ss.projects[name]
+	if !ok {
+		ps = newProjectStats()
+		ss.projects[name] = ps
+	}
+	return ps
+}
+
+// apply updates the counts for a change to a task made on day.
+func (ss *statsState) apply(old, new *Task, day string) {
+	if old != nil {
+		ss.count(old, -1, day)
+	}
+	if new != nil {
+		ss.count(new, 1, day)
+	}
+}
+
+// count adds n to every count t is part of, recording the status counts
+// as they stand at the end of day unless day is empty.
+func (ss *statsState) count(t *Task, n int64, day string) {
+	for _, project := range []string{"", t.Project} {
+		ps := ss.project(project)
+		addCount(ps.status, t.Status.String(), n)
+		addCount(ps.priority, t.Priority.String(), n)
+		addCount(ps.assignee, t.Assignee, n)
+		for _, l := range t.Labels {
+			addCount(ps.label, l, n)
+		}
+
+		if day != "" {
+			if _, ok := ps.days[day]; !ok {
+				ss.newDays = true
+			}
+			snapshot := make(map[string]int64, len(ps.status))
+			copyCounts(snapshot, ps.status)
+			ps.days[day] = snapshot
+			ss.changed[day] = true
+		}
+
+		if project == "" && t.Project == "" {
+			break
+		}
+	}
+}
+
+// StatsService reads the counts the TaskService keeps up to date as tasks
+// change, so GetStats never has to scan the tasks.
+type StatsService struct {
+	UnimplementedStatsServer
+
+	tasks *TaskService
+}
+
+func NewStatsService(tasks *TaskService) *StatsService {
+	return &StatsService{tasks: tasks}
+}
+
+func (s *StatsService) GetStats(c context.Context, r *GetStatsRequest) (*StatsResponse, error) {
+	ts := s.tasks
+	ts.mu.RLock()
+	defer ts.mu.RUnlock()
+
+	to := ts.now()
+	if r.To != nil {
+		to = r.To.AsTime()
+	}
+	from := to.AddDate(0, 0, -29)
+	if r.From != nil {
+		from = r.From.AsTime()
+	}
+	from, to = startOfDay(from), startOfDay(to)
+	if to.Before(from) {
+		return nil, status.Error(codes.InvalidArgument, "to must not be before from")
+	}
+	if to.Sub(from) > maxStatsDays*24*time.Hour {
+		return nil, status.Errorf(codes.InvalidArgument, "stats are limited to %d days", maxStatsDays)
+	}
+
+	resp := &StatsResponse{
+		ByStatus:   make(map[string]int64),
+		ByPriority: make(map[string]int64),
+		ByLabel:    make(map[string]int64),
+		ByAssignee: make(map[string]int64),
+	}
+	ps, ok := ts.stats.projects[r.Project]
+	if !ok {
+		ps = newProjectStats()
+	}
+	copyCounts(resp.ByStatus, ps.status)
+	copyCounts(resp.ByPriority, ps.priority)
+	copyCounts(resp.ByLabel, ps.label)
+	copyCounts(resp.ByAssignee, ps.assignee)
+
+	// Days without changes carry the counts over from the day before.
+	changed := make([]string, 0, len(ps.days))
+	for day := range ps.days {
+		changed = append(changed, day)
+	}
+	sort.Strings(changed)
+
+	var current map[string]int64
+	i := 0
+	for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+		day := d.Format("2006-01-02")
+		for i < len(changed) && changed[i] <= day {
+			current = ps.days[changed[i]]
+			i++
+		}
+		ds := &DayStats{Day: day, ByStatus: make(map[string]int64)}
+		copyCounts(ds.ByStatus, current)
+		for st, n := range current {
+			if isOpen(Status(Status_value[st])) {
+				ds.Open += n
+			}
+		}
+		resp.Days = append(resp.Days, ds)
+	}
+	return resp, nil
+}
+
+func newProjectStats() *projectStats {
+	return &projectStats{
+		status:   make(map[string]int64),
+		priority: make(map[string]int64),
+		label:    make(map[string]int64),
+		assignee: make(map[string]int64),
+		days:     make(map[string]map[string]int64),
+	}
+}
+
+// addCount adds n to m[k], dropping the key once it reaches zero.
+func addCount(m map[string]int64, k string, n int64) {
+	m[k] += n
+	if m[k] == 0 {
+		delete(m, k)
+	}
+}
+
+func copyCounts(dst, src map[string]int64) {
+	for k, n := range src {
+		dst[k] = n
+	}
+}
+
+func startOfDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,156 @@
+package tasks
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var (
+	// ErrNotFound is returned by a Tx when nothing is stored under a key.
+	ErrNotFound = errors.New("tasks: not found")
+	// ErrReadOnly is returned when writing in a read-only transaction.
+	ErrReadOnly = errors.New("tasks: transaction is read-only")
+	// ErrTxDone is returned when using a transaction after it has been
+	// committed or rolled back.
+	ErrTxDone = errors.New("tasks: transaction has already been committed or rolled back")
+	// ErrCorrupt is returned when stored data fails an integrity check,
+	// such as encrypted content which has been tampered with.
+	ErrCorrupt = errors.New("tasks: stored data is corrupt")
+)
+
+// Store is where a TaskService keeps its tasks, attachment content and
+// small server-wide values. The TaskService also holds every task in
+// memory, so a Store is read in full when the service starts and then
+// written to as tasks change.
+//
+// Stores must keep their own copies of what they're given and hand out
+// fresh copies, so callers can change messages freely. The storetest
+// package checks a Store behaves as the TaskService expects.
+type Store interface {
+	// Begin starts a transaction. Only one writable transaction runs at a
+	// time, and a read-only transaction sees the store as it was when it
+	// began. A goroutine mustn't begin a transaction while it has another
+	// open.
+	Begin(writable bool) (Tx, error)
+	Close() error
+}
+
+// Tx is a Store transaction. Its writes are only seen by others once it
+// has been committed, and are all thrown away if it's rolled back.
+type Tx interface {
+	Get(id int64) (*Task, error)
+	// List returns up to limit tasks with ids above after, in id order. A
+	// limit of 0 or less returns them all. Tasks whose content fails an
+	// integrity check are listed with it still in Sealed, rather than
+	// failing the list, while Get returns an error wrapping ErrCorrupt.
+	List(after int64, limit int) ([]*Task, error)
+	Put(t *Task) error
+	// Delete removes a task. Deleting a task which isn't stored does
+	// nothing.
+	Delete(id int64) error
+
+	GetBlob(key string) ([]byte, error)
+	PutBlob(key string, data []byte) error
+	GetMeta(key string) ([]byte, error)
+	PutMeta(key string, value []byte) error
+	// ListMeta returns the key of every meta value, in order.
+	ListMeta() ([]string, error)
+
+	// PutOutbox adds an entry to the outbox under seq, which the caller
+	// chooses and which is above every seq already used.
+	PutOutbox(seq int64, data []byte) error
+	// ListOutbox returns up to limit outbox entries with seqs above after,
+	// in seq order. A limit of 0 or less returns them all.
+	ListOutbox(after int64, limit int) ([]OutboxEntry, error)
+	// TrimOutbox removes the outbox entries with seqs up to and including
+	// upTo.
+	TrimOutbox(upTo int64) error
+
+	Commit() error
+	// Rollback ends the transaction without saving it. Rolling back a
+	// transaction which has been committed does nothing, so it's safe to
+	// defer.
+	Rollback() error
+}
+
+// OutboxEntry is an event held in a store's outbox until it has been
+// delivered. Each is saved in the transaction which made the change it's
+// about, so the outbox has an entry for every change which was saved and
+// none for those which weren't.
+type OutboxEntry struct {
+	Seq  int64
+	Data []byte
+}
+
+// StoreOpener opens a store given the part of its configuration after the
+// backend name.
+type StoreOpener func(addr string) (Store, error)
+
+var (
+	openersMu sync.Mutex
+	openers   = map[string]StoreOpener{
+		"memory": func(string) (Store, error) { return NewMemoryStore(), nil },
+	}
+)
+
+// RegisterStore makes a backend available to OpenStore. Backends register
+// themselves when their package is imported.
+func RegisterStore(name string, open StoreOpener) {
+	openersMu.Lock()
+	defer openersMu.Unlock()
+
+	if _, ok := openers[name]; ok {
+		panic("tasks: store " + name + " registered twice")
+	}
+	openers[name] = open
+}
+
+// OpenStore opens the store described by config, which is a backend name
+// optionally followed by a colon and the backend's own settings, such as
+// "memory" or "file:/var/lib/tasks".
+func OpenStore(config string) (Store, error) {
+	name, addr := config, ""
+	if i := strings.IndexByte(config, ':'); i >= 0 {
+		name, addr = config[:i], config[i+1:]
+	}
+
+	openersMu.Lock()
+	open, ok := openers[name]
+	var names []string
+	for n := range openers {
+		names = append(names, n)
+	}
+	openersMu.Unlock()
+
+	if !ok {
+		sort.Strings(names)
+		return nil, fmt.Errorf("unknown store %q, expected one of %s", name, strings.Join(names, ", "))
+	}
+	return open(addr)
+}
+
+// ReadTx runs fn in a read-only transaction.
+func ReadTx(s Store, fn func(tx Tx) error) error {
+	tx, err := s.Begin(false)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	return fn(tx)
+}
+
+// WriteTx runs fn in a writable transaction, committing it if fn succeeds.
+func WriteTx(s Store, fn func(tx Tx) error) error {
+	tx, err := s.Begin(true)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
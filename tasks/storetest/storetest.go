@@ -0,0 +1,652 @@
+// Package storetest checks that a tasks.Store behaves the way the
+// TaskService relies on. Backends run it from their own tests:
+//
+//	func TestStore(t *testing.T) {
+//		storetest.Run(t, storetest.Backend{
+//			Open: func(t *testing.T) tasks.Store { ... },
+//		})
+//	}
+package storetest
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/andyantrim/grpc-example/tasks"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Backend describes the store under test.
+type Backend struct {
+	// Open returns a new, empty store. The suite closes it.
+	Open func(t *testing.T) tasks.Store
+	// Reopen closes s and opens the same store again. Leave it nil for
+	// stores which don't persist.
+	Reopen func(t *testing.T, s tasks.Store) tasks.Store
+}
+
+// Run runs every check against b, each on a fresh store.
+func Run(t *testing.T, b Backend) {
+	checks := []struct {
+		name string
+		fn   func(t *testing.T, s tasks.Store)
+	}{
+		{"GetMissing", testGetMissing},
+		{"PutGet", testPutGet},
+		{"Copies", testCopies},
+		{"Overwrite", testOverwrite},
+		{"Delete", testDelete},
+		{"List", testList},
+		{"ListOwnWrites", testListOwnWrites},
+		{"Rollback", testRollback},
+		{"RollbackAfterCommit", testRollbackAfterCommit},
+		{"ReadOnly", testReadOnly},
+		{"Blobs", testBlobs},
+		{"Meta", testMeta},
+		{"ListMeta", testListMeta},
+		{"Outbox", testOutbox},
+		{"SerialWriters", testSerialWriters},
+	}
+	for _, c := range checks {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			s := b.Open(t)
+			defer s.Close()
+			c.fn(t, s)
+		})
+	}
+	if b.Reopen != nil {
+		t.Run("Reopen", func(t *testing.T) {
+			testReopen(t, b)
+		})
+	}
+}
+
+// sampleTask returns a task using most kinds of field, so encodings which
+// lose data are caught.
+func sampleTask(id int64) *tasks.Task {
+	at := timestamppb.New(time.Date(2024, 3, 1, 12, 30, 0, 500, time.UTC))
+	return &tasks.Task{
+		Id:          id,
+		Title:       "Task " + strconv.FormatInt(id, 10),
+		Description: "Line one\nline two, with ünïcode",
+		ParentId:    id / 2,
+		Labels:      []string{"bug", "ui"},
+		Project:     "web",
+		Logged:      durationpb.New(90 * time.Minute),
+		Status:      tasks.Status_IN_PROGRESS,
+		Rank:        "0000000001i",
+		Priority:    tasks.Priority_HIGH,
+		Assignee:    "alice",
+		CreatedAt:   at,
+		UpdatedAt:   at,
+		CustomFields: map[string]*tasks.CustomValue{
+			"points": {Value: &tasks.CustomValue_NumberValue{NumberValue: 3}},
+		},
+		Comments:  []*tasks.Comment{{Id: 1, Author: "bob", Body: "@alice look", CreatedAt: at}},
+		Watchers:  []string{"alice", "bob"},
+		Checklist: []*tasks.ChecklistItem{{Id: 1, Text: "step", Done: true}},
+		Attachments: []*tasks.Attachment{
+			{Id: 1, Name: "log.txt", ContentType: "text/plain", Size: 3, Blob: "abc", CreatedAt: at},
+		},
+		DependsOn: []int64{id + 1},
+		DueAt:     at,
+	}
+}
+
+func put(t *testing.T, s tasks.Store, ts ...*tasks.Task) {
+	t.Helper()
+	err := tasks.WriteTx(s, func(tx tasks.Tx) error {
+		for _, task := range ts {
+			if err := tx.Put(task); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("putting tasks: %v", err)
+	}
+}
+
+func get(t *testing.T, s tasks.Store, id int64) (*tasks.Task, error) {
+	t.Helper()
+	var task *tasks.Task
+	err := tasks.ReadTx(s, func(tx tasks.Tx) error {
+		var err error
+		task, err = tx.Get(id)
+		return err
+	})
+	return task, err
+}
+
+func list(t *testing.T, s tasks.Store, after int64, limit int) []int64 {
+	t.Helper()
+	var ids []int64
+	err := tasks.ReadTx(s, func(tx tasks.Tx) error {
+		list, err := tx.List(after, limit)
+		for _, task := range list {
+			ids = append(ids, task.Id)
+		}
+		return err
+	})
+	if err != nil {
+		t.Fatalf("listing tasks: %v", err)
+	}
+	return ids
+}
+
+func sameIDs(a, b []int64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func testGetMissing(t *testing.T, s tasks.Store) {
+	if _, err := get(t, s, 1); !errors.Is(err, tasks.ErrNotFound) {
+		t.Fatalf("getting a missing task: got %v, want ErrNotFound", err)
+	}
+}
+
+func testPutGet(t *testing.T, s tasks.Store) {
+	want := sampleTask(7)
+	put(t, s, want)
+	got, err := get(t, s, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !proto.Equal(got, want) {
+		t.Fatalf("stored task changed:\ngot  %v\nwant %v", got, want)
+	}
+}
+
+func testCopies(t *testing.T, s tasks.Store) {
+	task := sampleTask(1)
+	put(t, s, task)
+	task.Title = "changed after Put"
+
+	got, err := get(t, s, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Title != "Task 1" {
+		t.Fatalf("store kept the caller's task, title is %q", got.Title)
+	}
+	got.Title = "changed after Get"
+	if again, _ := get(t, s, 1); again.Title != "Task 1" {
+		t.Fatalf("store handed out its own task, title is %q", again.Title)
+	}
+}
+
+func testOverwrite(t *testing.T, s tasks.Store) {
+	put(t, s, sampleTask(1))
+	next := sampleTask(1)
+	next.Title = "Renamed"
+	next.Labels = nil
+	next.Comments = nil
+	put(t, s, next)
+
+	got, err := get(t, s, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !proto.Equal(got, next) {
+		t.Fatalf("overwritten task:\ngot  %v\nwant %v", got, next)
+	}
+}
+
+func testDelete(t *testing.T, s tasks.Store) {
+	put(t, s, sampleTask(1), sampleTask(2))
+	err := tasks.WriteTx(s, func(tx tasks.Tx) error {
+		if err := tx.Delete(1); err != nil {
+			return err
+		}
+		return tx.Delete(99)
+	})
+	if err != nil {
+		t.Fatalf("deleting: %v", err)
+	}
+	if _, err := get(t, s, 1); !errors.Is(err, tasks.ErrNotFound) {
+		t.Fatalf("getting a deleted task: got %v, want ErrNotFound", err)
+	}
+	if _, err := get(t, s, 2); err != nil {
+		t.Fatalf("deleting task 1 affected task 2: %v", err)
+	}
+}
+
+func testList(t *testing.T, s tasks.Store) {
+	for _, id := range []int64{5, 3, 9, 1, 12, 7, 2} {
+		put(t, s, sampleTask(id))
+	}
+	cases := []struct {
+		after int64
+		limit int
+		want  []int64
+	}{
+		{0, 0, []int64{1, 2, 3, 5, 7, 9, 12}},
+		{0, 3, []int64{1, 2, 3}},
+		{3, 2, []int64{5, 7}},
+		{4, -1, []int64{5, 7, 9, 12}},
+		{12, 0, nil},
+	}
+	for _, c := range cases {
+		if got := list(t, s, c.after, c.limit); !sameIDs(got, c.want) {
+			t.Errorf("List(%d, %d) = %v, want %v", c.after, c.limit, got, c.want)
+		}
+	}
+}
+
+func testListOwnWrites(t *testing.T, s tasks.Store) {
+	put(t, s, sampleTask(1), sampleTask(2), sampleTask(3))
+	err := tasks.WriteTx(s, func(tx tasks.Tx) error {
+		if err := tx.Delete(2); err != nil {
+			return err
+		}
+		if err := tx.Put(sampleTask(4)); err != nil {
+			return err
+		}
+		list, err := tx.List(0, 0)
+		if err != nil {
+			return err
+		}
+		var ids []int64
+		for _, task := range list {
+			ids = append(ids, task.Id)
+		}
+		if want := []int64{1, 3, 4}; !sameIDs(ids, want) {
+			return fmt.Errorf("List in the transaction = %v, want %v", ids, want)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func testRollback(t *testing.T, s tasks.Store) {
+	put(t, s, sampleTask(1))
+	tx, err := s.Begin(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	renamed := sampleTask(1)
+	renamed.Title = "Renamed"
+	for _, err := range []error{
+		tx.Put(renamed),
+		tx.Put(sampleTask(2)),
+		tx.PutBlob("b", []byte("data")),
+		tx.PutMeta("m", []byte("value")),
+		tx.PutOutbox(1, []byte("event")),
+	} {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tx.Rollback(); err != nil {
+		t.Fatalf("rolling back: %v", err)
+	}
+
+	if got, _ := get(t, s, 1); got.GetTitle() != "Task 1" {
+		t.Fatalf("rolled back change was kept, title is %q", got.GetTitle())
+	}
+	if _, err := get(t, s, 2); !errors.Is(err, tasks.ErrNotFound) {
+		t.Fatalf("rolled back task was kept: %v", err)
+	}
+	err = tasks.ReadTx(s, func(tx tasks.Tx) error {
+		if _, err := tx.GetBlob("b"); !errors.Is(err, tasks.ErrNotFound) {
+			return fmt.Errorf("rolled back blob: got %v, want ErrNotFound", err)
+		}
+		if _, err := tx.GetMeta("m"); !errors.Is(err, tasks.ErrNotFound) {
+			return fmt.Errorf("rolled back meta: got %v, want ErrNotFound", err)
+		}
+		if list, err := tx.ListOutbox(0, 0); err != nil || len(list) != 0 {
+			return fmt.Errorf("rolled back outbox: got %d entries, %v", len(list), err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func testRollbackAfterCommit(t *testing.T, s tasks.Store) {
+	tx, err := s.Begin(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Put(sampleTask(1)); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	tx.Rollback()
+	if err := tx.Put(sampleTask(2)); err == nil {
+		t.Error("Put after Commit succeeded")
+	}
+	if _, err := get(t, s, 1); err != nil {
+		t.Fatalf("Rollback after Commit undid it: %v", err)
+	}
+}
+
+func testReadOnly(t *testing.T, s tasks.Store) {
+	err := tasks.ReadTx(s, func(tx tasks.Tx) error {
+		if err := tx.Put(sampleTask(1)); !errors.Is(err, tasks.ErrReadOnly) {
+			return fmt.Errorf("Put: got %v, want ErrReadOnly", err)
+		}
+		if err := tx.Delete(1); !errors.Is(err, tasks.ErrReadOnly) {
+			return fmt.Errorf("Delete: got %v, want ErrReadOnly", err)
+		}
+		if err := tx.PutBlob("b", nil); !errors.Is(err, tasks.ErrReadOnly) {
+			return fmt.Errorf("PutBlob: got %v, want ErrReadOnly", err)
+		}
+		if err := tx.PutMeta("m", nil); !errors.Is(err, tasks.ErrReadOnly) {
+			return fmt.Errorf("PutMeta: got %v, want ErrReadOnly", err)
+		}
+		if err := tx.PutOutbox(1, nil); !errors.Is(err, tasks.ErrReadOnly) {
+			return fmt.Errorf("PutOutbox: got %v, want ErrReadOnly", err)
+		}
+		if err := tx.TrimOutbox(1); !errors.Is(err, tasks.ErrReadOnly) {
+			return fmt.Errorf("TrimOutbox: got %v, want ErrReadOnly", err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// testBytes checks one of the byte stores, reached through get and put.
+func testBytes(t *testing.T, s tasks.Store, get func(tasks.Tx, string) ([]byte, error), put func(tasks.Tx, string, []byte) error) {
+	data := []byte("some \x00 bytes")
+	err := tasks.WriteTx(s, func(tx tasks.Tx) error {
+		if err := put(tx, "key", data); err != nil {
+			return err
+		}
+		return put(tx, "empty", nil)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data[0] = 'X'
+
+	err = tasks.ReadTx(s, func(tx tasks.Tx) error {
+		got, err := get(tx, "key")
+		if err != nil {
+			return err
+		}
+		if string(got) != "some \x00 bytes" {
+			return fmt.Errorf("got %q back", got)
+		}
+		got[0] = 'Y'
+		if again, _ := get(tx, "key"); again[0] != 's' {
+			return errors.New("store handed out its own bytes")
+		}
+		if got, err := get(tx, "empty"); err != nil || len(got) != 0 {
+			return fmt.Errorf("empty value: got %q, %v", got, err)
+		}
+		if _, err := get(tx, "missing"); !errors.Is(err, tasks.ErrNotFound) {
+			return fmt.Errorf("missing key: got %v, want ErrNotFound", err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func testBlobs(t *testing.T, s tasks.Store) {
+	testBytes(t, s, tasks.Tx.GetBlob, tasks.Tx.PutBlob)
+}
+
+func testMeta(t *testing.T, s tasks.Store) {
+	testBytes(t, s, tasks.Tx.GetMeta, tasks.Tx.PutMeta)
+}
+
+func testListMeta(t *testing.T, s tasks.Store) {
+	err := tasks.WriteTx(s, func(tx tasks.Tx) error {
+		for _, key := range []string{"b", "a/2", "a/1"} {
+			if err := tx.PutMeta(key, []byte(key)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Keys written in a transaction are listed in it, and gone once it's
+	// rolled back.
+	tx, err := s.Begin(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.PutMeta("c", nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.PutMeta("a/1", []byte("again")); err != nil {
+		t.Fatal(err)
+	}
+	keys, err := tx.ListMeta()
+	if err != nil || !sameStrings(keys, []string{"a/1", "a/2", "b", "c"}) {
+		t.Errorf("ListMeta in the transaction = %v, %v, want [a/1 a/2 b c]", keys, err)
+	}
+	if err := tx.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+
+	err = tasks.ReadTx(s, func(tx tasks.Tx) error {
+		keys, err := tx.ListMeta()
+		if err != nil || !sameStrings(keys, []string{"a/1", "a/2", "b"}) {
+			return fmt.Errorf("ListMeta = %v, %v, want [a/1 a/2 b]", keys, err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func sameStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// seqs returns the seqs of the outbox entries after the given one.
+func seqs(tx tasks.Tx, after int64, limit int) ([]int64, error) {
+	list, err := tx.ListOutbox(after, limit)
+	var seqs []int64
+	for _, e := range list {
+		seqs = append(seqs, e.Seq)
+	}
+	return seqs, err
+}
+
+func testOutbox(t *testing.T, s tasks.Store) {
+	data := []byte("event \x00 1")
+	err := tasks.WriteTx(s, func(tx tasks.Tx) error {
+		for seq := int64(1); seq <= 5; seq++ {
+			if err := tx.PutOutbox(seq, data); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data[0] = 'X'
+
+	err = tasks.ReadTx(s, func(tx tasks.Tx) error {
+		list, err := tx.ListOutbox(0, 1)
+		if err != nil {
+			return err
+		}
+		if len(list) != 1 || list[0].Seq != 1 || string(list[0].Data) != "event \x00 1" {
+			return fmt.Errorf("ListOutbox(0, 1) = %v", list)
+		}
+		if got, _ := seqs(tx, 2, 2); !sameIDs(got, []int64{3, 4}) {
+			return fmt.Errorf("ListOutbox(2, 2) = %v, want [3 4]", got)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = tasks.WriteTx(s, func(tx tasks.Tx) error {
+		if err := tx.TrimOutbox(3); err != nil {
+			return err
+		}
+		if err := tx.PutOutbox(6, nil); err != nil {
+			return err
+		}
+		if got, err := seqs(tx, 0, 0); err != nil || !sameIDs(got, []int64{4, 5, 6}) {
+			return fmt.Errorf("outbox in the transaction = %v, %v, want [4 5 6]", got, err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tasks.ReadTx(s, func(tx tasks.Tx) error {
+		if got, err := seqs(tx, 0, 0); err != nil || !sameIDs(got, []int64{4, 5, 6}) {
+			return fmt.Errorf("outbox after trimming = %v, %v, want [4 5 6]", got, err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// testSerialWriters increments a counter from many goroutines at once. If
+// writable transactions overlap, increments are lost.
+func testSerialWriters(t *testing.T, s tasks.Store) {
+	const writers = 20
+	var wg sync.WaitGroup
+	errs := make(chan error, writers)
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- tasks.WriteTx(s, func(tx tasks.Tx) error {
+				n := 0
+				b, err := tx.GetMeta("counter")
+				if err == nil {
+					n, err = strconv.Atoi(string(b))
+				} else if errors.Is(err, tasks.ErrNotFound) {
+					err = nil
+				}
+				if err != nil {
+					return err
+				}
+				return tx.PutMeta("counter", []byte(strconv.Itoa(n+1)))
+			})
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var got []byte
+	err := tasks.ReadTx(s, func(tx tasks.Tx) error {
+		var err error
+		got, err = tx.GetMeta("counter")
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != strconv.Itoa(writers) {
+		t.Fatalf("counter is %s after %d increments", got, writers)
+	}
+}
+
+func testReopen(t *testing.T, b Backend) {
+	s := b.Open(t)
+	want := sampleTask(1)
+	put(t, s, want, sampleTask(2))
+	err := tasks.WriteTx(s, func(tx tasks.Tx) error {
+		if err := tx.Delete(2); err != nil {
+			return err
+		}
+		if err := tx.PutBlob("b", []byte("blob")); err != nil {
+			return err
+		}
+		if err := tx.PutOutbox(1, []byte("first")); err != nil {
+			return err
+		}
+		if err := tx.PutOutbox(2, []byte("second")); err != nil {
+			return err
+		}
+		if err := tx.TrimOutbox(1); err != nil {
+			return err
+		}
+		return tx.PutMeta("m", []byte("meta"))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx, err := s.Begin(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Put(sampleTask(3)); err != nil {
+		t.Fatal(err)
+	}
+	tx.Rollback()
+
+	s = b.Reopen(t, s)
+	defer s.Close()
+
+	got, err := get(t, s, 1)
+	if err != nil {
+		t.Fatalf("task lost on reopen: %v", err)
+	}
+	if !proto.Equal(got, want) {
+		t.Fatalf("task changed on reopen:\ngot  %v\nwant %v", got, want)
+	}
+	if ids := list(t, s, 0, 0); !sameIDs(ids, []int64{1}) {
+		t.Fatalf("tasks after reopen = %v, want [1]", ids)
+	}
+	err = tasks.ReadTx(s, func(tx tasks.Tx) error {
+		if b, err := tx.GetBlob("b"); err != nil || string(b) != "blob" {
+			return fmt.Errorf("blob after reopen: %q, %v", b, err)
+		}
+		if b, err := tx.GetMeta("m"); err != nil || string(b) != "meta" {
+			return fmt.Errorf("meta after reopen: %q, %v", b, err)
+		}
+		if list, err := tx.ListOutbox(0, 0); err != nil || len(list) != 1 || list[0].Seq != 2 || string(list[0].Data) != "second" {
+			return fmt.Errorf("outbox after reopen: %v, %v", list, err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
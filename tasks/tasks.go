@@ -2,23 +2,524 @@ package tasks
 
 import (
 	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/teamwork/log"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
+// readOnlyFields are managed by the server and can't be set with Update.
+var readOnlyFields = map[string]bool{
+	"id":     true,
+	"logged": true,
+	"rank":   true,
+
+	"created_at":   true,
+	"updated_at":   true,
+	"completed_at": true,
+
+	// Use Snooze and Unsnooze so the task is woken up on time.
+	"snoozed_until": true,
+
+	// Use AddComment, WatchTask and UnwatchTask.
+	"comments": true,
+	"watchers": true,
+
+	// Use the checklist RPCs, which progress follows.
+	"checklist": true,
+	"progress":  true,
+
+	"rendered_html": true,
+	"sla_due_at":    true,
+	"sealed":        true,
+
+	// Use AddAttachment, Link and Unlink.
+	"attachments": true,
+	"depends_on":  true,
+
+	// Use Merge.
+	"duplicate_of": true,
+}
+
+// changeFunc is told about every task that is created (old is nil), changed
+// or deleted (new is nil), once the change has been saved. It's called with
+// the task lock held, so it must not call back into the TaskService.
+type changeFunc func(old, new *Task)
+
+// change is a task before and after a change which hasn't been saved yet.
+type change struct {
+	old, new *Task
+}
+
 type TaskService struct {
 	UnimplementedTasksServer
+
+	mu    sync.RWMutex
+	tasks map[int64]*Task
+	// corrupt holds the tasks whose content couldn't be read from the
+	// store, which are left out of tasks.
+	corrupt         map[int64]*Task
+	nextID          int64
+	nextCommentID   int64
+	nextChecklistID int64
+	nextAttachID    int64
+	nextEventID     int64
+
+	store Store
+	// pending holds the tasks changed since the last commit, with nil for
+	// deleted ones, and pendingBlobs the new attachment content. changes
+	// holds what to tell the watchers once the commit succeeds.
+	pending      map[int64]*Task
+	pendingBlobs map[string][]byte
+	changes      []change
+
+	// states are saved alongside the tasks, and dirty holds those changed
+	// since the last commit. See state.go.
+	states    []state
+	dirty     map[state]bool
+	fields    *fieldState
+	calendars *calendarState
+	boards    *boardState
+	views     *viewState
+	templates *templateState
+	inbox     *inboxState
+	archive   *archiveState
+	time      *timeState
+	stats     *statsState
+
+	// grams indexes the tasks for FindSimilar, and children holds the ids
+	// of each task's direct subtasks. They follow the tasks' changes and
+	// are rebuilt whenever they're loaded.
+	grams    *trigramIndex
+	children map[int64]map[int64]bool
+
+	watchers []changeFunc
+	events   *eventHub
+	// sinks are the names of the Relay's sinks, whose offsets are backed
+	// up with the outbox.
+	sinks []string
+	// outbox is signalled when events are added to the store's outbox,
+	// and snoozed when a snooze may need waking sooner.
+	outbox  chan struct{}
+	snoozed chan struct{}
+	now     func() time.Time
 }
 
-func NewTaskService() *TaskService {
-	return &TaskService{}
+// NewTaskService returns a service keeping its tasks in store, loading any
+// which are already there.
+func NewTaskService(store Store) (*TaskService, error) {
+	s := &TaskService{
+		store:   store,
+		events:  newEventHub(),
+		outbox:  make(chan struct{}, 1),
+		snoozed: make(chan struct{}, 1),
+		now:     time.Now,
+	}
+	s.initStates()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.loadLocked(); err != nil {
+		return nil, fmt.Errorf("loading tasks: %v", err)
+	}
+	log.Infof("Loaded %d tasks", len(s.tasks))
+	return s, nil
 }
 
-func (s *TaskService) Create(c context.Context, t *TaskRequest) (*TaskResponse, error) {
+func (s *TaskService) Create(c context.Context, t *TaskRequest) (_ *TaskResponse, err error) {
 	log.Infof("Recieved new task %s", t.Title)
 
+	s.mu.Lock()
+	defer s.unlock(&err)
+	return s.createLocked(t)
+}
+
+// createLocked adds a task as Create does. s.mu must be held.
+func (s *TaskService) createLocked(t *TaskRequest) (*TaskResponse, error) {
+	if t.ParentId != 0 && s.tasks[t.ParentId] == nil {
+		return nil, status.Errorf(codes.NotFound, "parent task %d not found", t.ParentId)
+	}
+	task := &Task{
+		Title:        t.Title,
+		Description:  t.Description,
+		ParentId:     t.ParentId,
+		Labels:       t.Labels,
+		Project:      t.Project,
+		Priority:     t.Priority,
+		Assignee:     t.Assignee,
+		CustomFields: t.CustomFields,
+		DueAt:        t.DueAt,
+		Sla:          t.Sla,
+
+		RepeatEveryBusinessDays: t.RepeatEveryBusinessDays,
+
+		DescriptionFormat: t.DescriptionFormat,
+	}
+	if err := checkBusinessDays("due_in_business_days", t.DueInBusinessDays); err != nil {
+		return nil, err
+	}
+	if err := checkBusinessTime("sla", t.Sla.AsDuration()); err != nil {
+		return nil, err
+	}
+	if err := checkBusinessDays("repeat_every_business_days", t.RepeatEveryBusinessDays); err != nil {
+		return nil, err
+	}
+	if t.DueInBusinessDays > 0 {
+		if t.DueAt != nil {
+			return nil, status.Error(codes.InvalidArgument, "only one of due_at and due_in_business_days can be set")
+		}
+		wc := compileCalendar(s.calendarLocked(t.Project))
+		task.DueAt = timestamppb.New(wc.addDays(s.now(), int(t.DueInBusinessDays)))
+	}
+	if err := s.validateCustomFieldsLocked(task); err != nil {
+		return nil, err
+	}
+	similar := s.similarLocked(t.Project, t.Title, t.Description, DefaultSimilarity, defaultSimilarLimit)
+	if t.RejectDuplicates && len(similar) > 0 {
+		ids := make([]string, len(similar))
+		for i, st := range similar {
+			ids[i] = strconv.FormatInt(st.Id, 10)
+		}
+		return nil, status.Errorf(codes.AlreadyExists, "task looks like a duplicate of %s", strings.Join(ids, ", "))
+	}
+	id := s.insertLocked(task)
+
 	resp := TaskResponse{
-		Id: 1,
+		Id:      id,
+		Similar: similar,
 	}
 	return &resp, nil
 }
+
+func (s *TaskService) Get(c context.Context, r *GetRequest) (*Task, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if _, ok := s.corrupt[r.Id]; ok {
+		return nil, corruptError(r.Id)
+	}
+	t, ok := s.tasks[r.Id]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "task %d not found", r.Id)
+	}
+	for r.FollowRedirect && t.DuplicateOf != 0 {
+		if t, ok = s.tasks[t.DuplicateOf]; !ok {
+			return nil, status.Errorf(codes.NotFound, "task %d was merged into a task which has been deleted", r.Id)
+		}
+	}
+	return proto.Clone(t).(*Task), nil
+}
+
+func (s *TaskService) List(c context.Context, r *ListRequest) (*ListResponse, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	// Tasks which can't be read are matched on what's left of them.
+	for _, t := range s.corrupt {
+		if r.Filter.matches(t) {
+			return nil, corruptError(t.Id)
+		}
+	}
+	matched := s.matchLocked(r.Filter)
+	if r.OrderBy != "" {
+		less, err := orderBy(r.OrderBy)
+		if err != nil {
+			return nil, err
+		}
+		sort.SliceStable(matched, func(i, j int) bool {
+			return less(matched[i], matched[j])
+		})
+	}
+
+	resp := &ListResponse{}
+	for _, t := range matched {
+		resp.Tasks = append(resp.Tasks, proto.Clone(t).(*Task))
+	}
+	return resp, nil
+}
+
+func (s *TaskService) Update(c context.Context, r *UpdateRequest) (_ *Task, err error) {
+	paths := r.UpdateMask.GetPaths()
+	if err := validateMask(paths); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.unlock(&err)
+
+	t, ok := s.tasks[r.Id]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "task %d not found", r.Id)
+	}
+	if err := s.updateLocked(t, r.Task, paths); err != nil {
+		return nil, err
+	}
+
+	log.Infof("Updated %s on task %d", strings.Join(paths, ", "), t.Id)
+	return proto.Clone(t).(*Task), nil
+}
+
+// Delete removes a task along with all of its subtasks.
+func (s *TaskService) Delete(c context.Context, r *DeleteRequest) (_ *DeleteResponse, err error) {
+	s.mu.Lock()
+	defer s.unlock(&err)
+
+	if _, ok := s.tasks[r.Id]; !ok {
+		return nil, status.Errorf(codes.NotFound, "task %d not found", r.Id)
+	}
+	ids := s.deleteLocked(r.Id)
+
+	log.Infof("Deleted task %d and %d subtasks", r.Id, len(ids)-1)
+	return &DeleteResponse{Ids: ids}, nil
+}
+
+// corruptError is returned when asked for a task whose content couldn't
+// be read from the store.
+func corruptError(id int64) error {
+	return status.Errorf(codes.DataLoss, "task %d can't be read, as its content is corrupt", id)
+}
+
+// insertLocked assigns the next id to t and stores it. s.mu must be held.
+func (s *TaskService) insertLocked(t *Task) int64 {
+	s.nextID++
+	t.Id = s.nextID
+	if t.Rank == "" {
+		t.Rank = initialRank(t.Id)
+	}
+	s.tasks[t.Id] = t
+	s.changedLocked(nil, t)
+	return t.Id
+}
+
+// childrenLocked returns the direct subtasks of id in creation order. s.mu
+// must be held.
+func (s *TaskService) childrenLocked(id int64) []*Task {
+	ids := make([]int64, 0, len(s.children[id]))
+	for child := range s.children[id] {
+		ids = append(ids, child)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	children := make([]*Task, len(ids))
+	for i, child := range ids {
+		children[i] = s.tasks[child]
+	}
+	return children
+}
+
+// addChildLocked and removeChildLocked keep s.children in step with t's
+// parent. s.mu must be held.
+func (s *TaskService) addChildLocked(t *Task) {
+	if t.ParentId == 0 {
+		return
+	}
+	if s.children[t.ParentId] == nil {
+		s.children[t.ParentId] = make(map[int64]bool)
+	}
+	s.children[t.ParentId][t.Id] = true
+}
+
+func (s *TaskService) removeChildLocked(t *Task) {
+	delete(s.children[t.ParentId], t.Id)
+	if len(s.children[t.ParentId]) == 0 {
+		delete(s.children, t.ParentId)
+	}
+}
+
+// matchLocked returns the tasks matching f in id order. s.mu must be held.
+func (s *TaskService) matchLocked(f *TaskFilter) []*Task {
+	var matched []*Task
+	for _, t := range s.tasks {
+		if f.matches(t) {
+			matched = append(matched, t)
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		return matched[i].Id < matched[j].Id
+	})
+	return matched
+}
+
+// updateLocked applies the fields in paths from patch to t. The paths must
+// already have been checked with validateMask. s.mu must be held.
+func (s *TaskService) updateLocked(t, patch *Task, paths []string) error {
+	if patch == nil {
+		patch = &Task{}
+	}
+	for _, p := range paths {
+		if p != "parent_id" || patch.ParentId == 0 {
+			continue
+		}
+		// Walk up from the new parent to make sure t isn't one of its
+		// ancestors.
+		for id := patch.ParentId; id != 0; {
+			parent, ok := s.tasks[id]
+			if !ok {
+				return status.Errorf(codes.NotFound, "parent task %d not found", id)
+			}
+			if parent.Id == t.Id {
+				return status.Errorf(codes.InvalidArgument, "task %d can't be moved under its own subtask", t.Id)
+			}
+			id = parent.ParentId
+		}
+	}
+
+	// Apply the patch to a copy so t is left alone if the result isn't
+	// valid.
+	next := proto.Clone(t).(*Task)
+	dst := next.ProtoReflect()
+	src := proto.Clone(patch).ProtoReflect()
+	fields := dst.Descriptor().Fields()
+	for _, p := range paths {
+		if name := strings.TrimPrefix(p, customFieldPrefix); name != p {
+			if v, ok := patch.CustomFields[name]; ok {
+				if next.CustomFields == nil {
+					next.CustomFields = make(map[string]*CustomValue)
+				}
+				next.CustomFields[name] = proto.Clone(v).(*CustomValue)
+			} else {
+				delete(next.CustomFields, name)
+			}
+			continue
+		}
+		fd := fields.ByName(protoreflect.Name(p))
+		if src.Has(fd) {
+			dst.Set(fd, src.Get(fd))
+		} else {
+			dst.Clear(fd)
+		}
+	}
+	if err := s.validateCustomFieldsLocked(next); err != nil {
+		return err
+	}
+	if err := checkBusinessTime("sla", next.Sla.AsDuration()); err != nil {
+		return err
+	}
+	if err := checkBusinessDays("repeat_every_business_days", next.RepeatEveryBusinessDays); err != nil {
+		return err
+	}
+
+	old := proto.Clone(t).(*Task)
+	proto.Reset(t)
+	proto.Merge(t, next)
+	s.changedLocked(old, t)
+	return nil
+}
+
+// deleteLocked removes id and its subtasks, returning the removed ids.
+// Dependencies on the removed tasks and timers running on them are dropped.
+// s.mu must be held.
+func (s *TaskService) deleteLocked(id int64) []int64 {
+	ids := s.deleteTreeLocked(id)
+	s.unlinkAllLocked(ids)
+	s.dropTimersLocked(ids)
+	return ids
+}
+
+func (s *TaskService) deleteTreeLocked(id int64) []int64 {
+	ids := []int64{id}
+	for _, child := range s.childrenLocked(id) {
+		ids = append(ids, s.deleteTreeLocked(child.Id)...)
+	}
+	if t, ok := s.tasks[id]; ok {
+		delete(s.tasks, id)
+		s.changedLocked(t, nil)
+	}
+	return ids
+}
+
+// onChange registers fn to be told about every change to a task.
+func (s *TaskService) onChange(fn changeFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.watchers = append(s.watchers, fn)
+}
+
+// changedLocked stamps the change times, progress, rendered description, SLA
+// deadline and automatic watchers on new, and queues it to be saved and
+// then passed on to everything registered with onChange. A repeating task
+// which has just been done gets its next occurrence. s.mu must be held.
+func (s *TaskService) changedLocked(old, new *Task) {
+	if old == nil || new == nil || old.ParentId != new.ParentId {
+		if old != nil {
+			s.removeChildLocked(old)
+		}
+		if new != nil {
+			s.addChildLocked(new)
+		}
+	}
+	if new != nil {
+		autoWatch(old, new)
+		new.Progress = checklistProgress(new)
+		if old == nil || old.Description != new.Description || old.DescriptionFormat != new.DescriptionFormat {
+			new.RenderedHtml = renderDescription(new)
+		}
+		if old == nil || old.Title != new.Title || old.Description != new.Description {
+			s.grams.add(new)
+		}
+
+		now := timestamppb.New(s.now())
+		if old == nil {
+			new.CreatedAt = now
+		}
+		new.UpdatedAt = now
+		if old == nil || old.Project != new.Project || !proto.Equal(old.Sla, new.Sla) {
+			new.SlaDueAt = nil
+			if new.Sla != nil {
+				wc := compileCalendar(s.calendarLocked(new.Project))
+				new.SlaDueAt = timestamppb.New(wc.addTime(new.CreatedAt.AsTime(), new.Sla.AsDuration()))
+			}
+		}
+		if old == nil || old.Status != new.Status {
+			new.CompletedAt = nil
+			if !isOpen(new.Status) {
+				new.CompletedAt = now
+			} else {
+				// Reopening a task brings it back out of the archive.
+				new.ArchivedAt = nil
+			}
+		}
+	}
+	if new != nil {
+		s.pending[new.Id] = new
+		// Later changes in the same commit mustn't show through.
+		s.changes = append(s.changes, change{old, proto.Clone(new).(*Task)})
+		if old != nil && isOpen(old.Status) && new.Status == Status_DONE && new.RepeatEveryBusinessDays > 0 {
+			s.repeatLocked(new)
+		}
+	} else {
+		s.grams.remove(old.Id)
+		s.pending[old.Id] = nil
+		s.changes = append(s.changes, change{old, nil})
+	}
+}
+
+// validateMask checks that paths only name fields that can be updated.
+func validateMask(paths []string) error {
+	if len(paths) == 0 {
+		return status.Error(codes.InvalidArgument, "update_mask must name at least one field")
+	}
+	fields := (&Task{}).ProtoReflect().Descriptor().Fields()
+	for _, p := range paths {
+		if strings.HasPrefix(p, customFieldPrefix) && len(p) > len(customFieldPrefix) {
+			continue
+		}
+		if fields.ByName(protoreflect.Name(p)) == nil || readOnlyFields[p] {
+			return status.Errorf(codes.InvalidArgument, "field %q can't be updated", p)
+		}
+	}
+	return nil
+}
+
+// isOpen reports whether a task with status st still needs doing.
+func isOpen(st Status) bool {
+	return st != Status_DONE && st != Status_CANCELLED
+}
@@ -0,0 +1,74 @@
+package tasks
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// The children index follows tasks being created, moved, deleted and
+// reloaded.
+func TestChildren(t *testing.T) {
+	ctx := context.Background()
+	ts, err := NewTaskService(NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range []*TaskRequest{
+		{Title: "root"},
+		{Title: "a", ParentId: 1},
+		{Title: "b", ParentId: 1},
+		{Title: "b1", ParentId: 3},
+		{Title: "other"},
+	} {
+		if _, err := ts.Create(ctx, r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	check := func(when string, want map[int64][]int64) {
+		t.Helper()
+		ts.mu.RLock()
+		defer ts.mu.RUnlock()
+		for id := int64(1); id <= 5; id++ {
+			var got []int64
+			for _, child := range ts.childrenLocked(id) {
+				got = append(got, child.Id)
+			}
+			if !reflect.DeepEqual(got, want[id]) {
+				t.Errorf("%s, task %d has children %v, want %v", when, id, got, want[id])
+			}
+		}
+	}
+	check("after creating", map[int64][]int64{1: {2, 3}, 3: {4}})
+
+	_, err = ts.Update(ctx, &UpdateRequest{
+		Id:         2,
+		Task:       &Task{ParentId: 5},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"parent_id"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	check("after moving", map[int64][]int64{1: {3}, 3: {4}, 5: {2}})
+
+	// A failed Execute is rolled back by reloading.
+	_, err = ts.Execute(ctx, &ExecuteRequest{Ops: []*ExecuteOp{
+		{Op: &ExecuteOp_Create{Create: &TaskRequest{Title: "c", ParentId: 1}}},
+		{Op: &ExecuteOp_Delete{Delete: &DeleteRequest{Id: 10}}},
+	}})
+	if err == nil {
+		t.Fatal("deleting a missing task succeeded")
+	}
+	check("after rolling back", map[int64][]int64{1: {3}, 3: {4}, 5: {2}})
+
+	resp, err := ts.Delete(ctx, &DeleteRequest{Id: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int64{1, 3, 4}; !reflect.DeepEqual(resp.Ids, want) {
+		t.Errorf("deleted %v, want %v", resp.Ids, want)
+	}
+	check("after deleting", map[int64][]int64{5: {2}})
+}
@@ -0,0 +1,141 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             (unknown)
+// source: tasks/template.proto
+
+package tasks
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// TemplatesClient is the client API for Templates service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type TemplatesClient interface {
+	SaveTemplate(ctx context.Context, in *SaveTemplateRequest, opts ...grpc.CallOption) (*Template, error)
+	CreateFromTemplate(ctx context.Context, in *CreateFromTemplateRequest, opts ...grpc.CallOption) (*CreateFromTemplateResponse, error)
+}
+
+type templatesClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewTemplatesClient(cc grpc.ClientConnInterface) TemplatesClient {
+	return &templatesClient{cc}
+}
+
+func (c *templatesClient) SaveTemplate(ctx context.Context, in *SaveTemplateRequest, opts ...grpc.CallOption) (*Template, error) {
+	out := new(Template)
+	err := c.cc.Invoke(ctx, "/task.Templates/SaveTemplate", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *templatesClient) CreateFromTemplate(ctx context.Context, in *CreateFromTemplateRequest, opts ...grpc.CallOption) (*CreateFromTemplateResponse, error) {
+	out := new(CreateFromTemplateResponse)
+	err := c.cc.Invoke(ctx, "/task.Templates/CreateFromTemplate", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// TemplatesServer is the server API for Templates service.
+// All implementations must embed UnimplementedTemplatesServer
+// for forward compatibility
+type TemplatesServer interface {
+	SaveTemplate(context.Context, *SaveTemplateRequest) (*Template, error)
+	CreateFromTemplate(context.Context, *CreateFromTemplateRequest) (*CreateFromTemplateResponse, error)
+	mustEmbedUnimplementedTemplatesServer()
+}
+
+// UnimplementedTemplatesServer must be embedded to have forward compatible implementations.
+type UnimplementedTemplatesServer struct {
+}
+
+func (UnimplementedTemplatesServer) SaveTemplate(context.Context, *SaveTemplateRequest) (*Template, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SaveTemplate not implemented")
+}
+func (UnimplementedTemplatesServer) CreateFromTemplate(context.Context, *CreateFromTemplateRequest) (*CreateFromTemplateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateFromTemplate not implemented")
+}
+func (UnimplementedTemplatesServer) mustEmbedUnimplementedTemplatesServer() {}
+
+// UnsafeTemplatesServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to TemplatesServer will
+// result in compilation errors.
+type UnsafeTemplatesServer interface {
+	mustEmbedUnimplementedTemplatesServer()
+}
+
+func RegisterTemplatesServer(s grpc.ServiceRegistrar, srv TemplatesServer) {
+	s.RegisterService(&Templates_ServiceDesc, srv)
+}
+
+func _Templates_SaveTemplate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SaveTemplateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TemplatesServer).SaveTemplate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/task.Templates/SaveTemplate",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TemplatesServer).SaveTemplate(ctx, req.(*SaveTemplateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Templates_CreateFromTemplate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateFromTemplateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TemplatesServer).CreateFromTemplate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/task.Templates/CreateFromTemplate",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TemplatesServer).CreateFromTemplate(ctx, req.(*CreateFromTemplateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Templates_ServiceDesc is the grpc.ServiceDesc for Templates service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Templates_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "task.Templates",
+	HandlerType: (*TemplatesServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "SaveTemplate",
+			Handler:    _Templates_SaveTemplate_Handler,
+		},
+		{
+			MethodName: "CreateFromTemplate",
+			Handler:    _Templates_CreateFromTemplate_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "tasks/template.proto",
+}
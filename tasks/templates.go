@@ -0,0 +1,214 @@
+package tasks
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/teamwork/log"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+var placeholderRe = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_.-]+)\s*\}\}`)
+
+// templatesKey is the meta key the templates are saved under.
+const templatesKey = "templates"
+
+// templateState holds the templates by name.
+type templateState struct {
+	byName map[string]*Template
+}
+
+func (st *templateState) load(tx Tx) error {
+	stored := &StoredTemplates{}
+	if err := getState(tx, templatesKey, stored); err != nil {
+		return err
+	}
+	st.byName = make(map[string]*Template)
+	for _, tmpl := range stored.Templates {
+		st.byName[tmpl.Name] = tmpl
+	}
+	return nil
+}
+
+func (st *templateState) save(tx Tx) error {
+	stored := &StoredTemplates{}
+	for _, tmpl := range st.byName {
+		stored.Templates = append(stored.Templates, tmpl)
+	}
+	sort.Slice(stored.Templates, func(i, j int) bool {
+		return stored.Templates[i].Name < stored.Templates[j].Name
+	})
+	return putState(tx, templatesKey, stored)
+}
+
+type TemplateService struct {
+	UnimplementedTemplatesServer
+
+	tasks *TaskService
+}
+
+func NewTemplateService(tasks *TaskService) *TemplateService {
+	return &TemplateService{tasks: tasks}
+}
+
+func (s *TemplateService) SaveTemplate(c context.Context, r *SaveTemplateRequest) (_ *Template, err error) {
+	if r.Name == "" {
+		return nil, status.Error(codes.InvalidArgument, "template name is required")
+	}
+
+	ts := s.tasks
+	ts.mu.Lock()
+	defer ts.unlock(&err)
+
+	root := r.Root
+	if r.TaskId != 0 {
+		if root, err = ts.snapshotLocked(r.TaskId); err != nil {
+			return nil, err
+		}
+	}
+	if root == nil || root.Title == "" {
+		return nil, status.Error(codes.InvalidArgument, "template needs a root task with a title")
+	}
+
+	tmpl := &Template{
+		Name:         r.Name,
+		Root:         proto.Clone(root).(*TemplateTask),
+		Placeholders: placeholders(root),
+	}
+
+	ts.templates.byName[r.Name] = tmpl
+	ts.stateChangedLocked(ts.templates)
+
+	log.Infof("Saved template %s with placeholders %v", r.Name, tmpl.Placeholders)
+	return proto.Clone(tmpl).(*Template), nil
+}
+
+// CreateFromTemplate creates every task in the template in one step. Either
+// the whole tree is created or, if a variable is missing, nothing is.
+func (s *TemplateService) CreateFromTemplate(c context.Context, r *CreateFromTemplateRequest) (_ *CreateFromTemplateResponse, err error) {
+	ts := s.tasks
+	ts.mu.Lock()
+	defer ts.unlock(&err)
+
+	tmpl, ok := ts.templates.byName[r.Name]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "template %s not found", r.Name)
+	}
+
+	var missing []string
+	for _, p := range tmpl.Placeholders {
+		if _, ok := r.Variables[p]; !ok {
+			missing = append(missing, p)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "missing template variables: %s", strings.Join(missing, ", "))
+	}
+
+	var project string
+	if r.ParentId != 0 {
+		parent, ok := ts.tasks[r.ParentId]
+		if !ok {
+			return nil, status.Errorf(codes.NotFound, "parent task %d not found", r.ParentId)
+		}
+		project = parent.Project
+	}
+
+	// Build and check every task before storing any of them. parents holds
+	// the index of each task's parent, or -1 for the root.
+	var build []*Task
+	var parents []int
+	var walk func(tt *TemplateTask, parent int)
+	walk = func(tt *TemplateTask, parent int) {
+		labels := make([]string, len(tt.Labels))
+		for i, l := range tt.Labels {
+			labels[i] = expand(l, r.Variables)
+		}
+		build = append(build, &Task{
+			Title:       expand(tt.Title, r.Variables),
+			Description: expand(tt.Description, r.Variables),
+			Labels:      labels,
+			Project:     project,
+		})
+		parents = append(parents, parent)
+		idx := len(build) - 1
+		for _, sub := range tt.Subtasks {
+			walk(sub, idx)
+		}
+	}
+	walk(tmpl.Root, -1)
+	for _, t := range build {
+		if err := ts.validateCustomFieldsLocked(t); err != nil {
+			return nil, err
+		}
+	}
+
+	resp := &CreateFromTemplateResponse{}
+	for i, t := range build {
+		t.ParentId = r.ParentId
+		if parents[i] >= 0 {
+			t.ParentId = resp.Ids[parents[i]]
+		}
+		resp.Ids = append(resp.Ids, ts.insertLocked(t))
+	}
+
+	log.Infof("Created %d tasks from template %s", len(resp.Ids), r.Name)
+	return resp, nil
+}
+
+// snapshotLocked copies the task id and its subtasks into a template tree.
+// s.mu must be held.
+func (s *TaskService) snapshotLocked(id int64) (*TemplateTask, error) {
+	t, ok := s.tasks[id]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "task %d not found", id)
+	}
+	var copyTree func(t *Task) *TemplateTask
+	copyTree = func(t *Task) *TemplateTask {
+		tt := &TemplateTask{
+			Title:       t.Title,
+			Description: t.Description,
+			Labels:      append([]string(nil), t.Labels...),
+		}
+		for _, child := range s.childrenLocked(t.Id) {
+			tt.Subtasks = append(tt.Subtasks, copyTree(child))
+		}
+		return tt
+	}
+	return copyTree(t), nil
+}
+
+// placeholders returns the sorted, unique placeholder names used in tt.
+func placeholders(tt *TemplateTask) []string {
+	seen := make(map[string]bool)
+	var walk func(tt *TemplateTask)
+	walk = func(tt *TemplateTask) {
+		fields := append([]string{tt.Title, tt.Description}, tt.Labels...)
+		for _, f := range fields {
+			for _, m := range placeholderRe.FindAllStringSubmatch(f, -1) {
+				seen[m[1]] = true
+			}
+		}
+		for _, sub := range tt.Subtasks {
+			walk(sub)
+		}
+	}
+	walk(tt)
+
+	names := make([]string, 0, len(seen))
+	for n := range seen {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func expand(s string, vars map[string]string) string {
+	return placeholderRe.ReplaceAllStringFunc(s, func(m string) string {
+		return vars[placeholderRe.FindStringSubmatch(m)[1]]
+	})
+}
@@ -0,0 +1,83 @@
+package tasks
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Placeholders are filled in across the whole tree, and a missing variable
+// creates nothing.
+func TestTemplatePlaceholders(t *testing.T) {
+	ctx := context.Background()
+	ts, err := NewTaskService(NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	templates := NewTemplateService(ts)
+	tmpl, err := templates.SaveTemplate(ctx, &SaveTemplateRequest{
+		Name: "release",
+		Root: &TemplateTask{
+			Title:  "Release {{ version }}",
+			Labels: []string{"{{team}}"},
+			Subtasks: []*TemplateTask{
+				{Title: "Tag {{version}}", Description: "Ask {{ owner }} for {{missing-brace}"},
+				{Title: "Announce"},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"owner", "team", "version"}; !reflect.DeepEqual(tmpl.Placeholders, want) {
+		t.Errorf("placeholders are %v, want %v", tmpl.Placeholders, want)
+	}
+
+	_, err = templates.CreateFromTemplate(ctx, &CreateFromTemplateRequest{
+		Name:      "release",
+		Variables: map[string]string{"version": "1.2"},
+	})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("creating without every variable returned %v", err)
+	}
+	if list, _ := ts.List(ctx, &ListRequest{}); len(list.Tasks) != 0 {
+		t.Fatalf("a failed create left %d tasks", len(list.Tasks))
+	}
+
+	resp, err := templates.CreateFromTemplate(ctx, &CreateFromTemplateRequest{
+		Name:      "release",
+		Variables: map[string]string{"version": "1.2", "owner": "ann", "team": "web"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int64{1, 2, 3}; !reflect.DeepEqual(resp.Ids, want) {
+		t.Fatalf("created %v, want %v", resp.Ids, want)
+	}
+	for _, want := range []*Task{
+		{Id: 1, Title: "Release 1.2", Labels: []string{"web"}},
+		{Id: 2, Title: "Tag 1.2", Description: "Ask ann for {{missing-brace}", ParentId: 1},
+		{Id: 3, Title: "Announce", ParentId: 1},
+	} {
+		got, err := ts.Get(ctx, &GetRequest{Id: want.Id})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Title != want.Title || got.Description != want.Description || got.ParentId != want.ParentId || len(got.Labels) != len(want.Labels) {
+			t.Errorf("task %d is %q %q under %d with labels %v, want %q %q under %d with labels %v", want.Id,
+				got.Title, got.Description, got.ParentId, got.Labels, want.Title, want.Description, want.ParentId, want.Labels)
+		}
+	}
+
+	// A template saved from tasks keeps their text, placeholders included.
+	saved, err := templates.SaveTemplate(ctx, &SaveTemplateRequest{Name: "copy", TaskId: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(saved.Root.Subtasks) != 2 || saved.Root.Subtasks[1].Title != "Announce" || len(saved.Placeholders) != 0 {
+		t.Errorf("template saved from task 1 is %v", saved)
+	}
+}
@@ -0,0 +1,213 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             (unknown)
+// source: tasks/timer.proto
+
+package tasks
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// TimeTrackingClient is the client API for TimeTracking service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type TimeTrackingClient interface {
+	StartTimer(ctx context.Context, in *StartTimerRequest, opts ...grpc.CallOption) (*Timer, error)
+	StopTimer(ctx context.Context, in *StopTimerRequest, opts ...grpc.CallOption) (*WorkLog, error)
+	LogWork(ctx context.Context, in *LogWorkRequest, opts ...grpc.CallOption) (*WorkLog, error)
+	TimeReport(ctx context.Context, in *TimeReportRequest, opts ...grpc.CallOption) (*TimeReportResponse, error)
+}
+
+type timeTrackingClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewTimeTrackingClient(cc grpc.ClientConnInterface) TimeTrackingClient {
+	return &timeTrackingClient{cc}
+}
+
+func (c *timeTrackingClient) StartTimer(ctx context.Context, in *StartTimerRequest, opts ...grpc.CallOption) (*Timer, error) {
+	out := new(Timer)
+	err := c.cc.Invoke(ctx, "/task.TimeTracking/StartTimer", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *timeTrackingClient) StopTimer(ctx context.Context, in *StopTimerRequest, opts ...grpc.CallOption) (*WorkLog, error) {
+	out := new(WorkLog)
+	err := c.cc.Invoke(ctx, "/task.TimeTracking/StopTimer", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *timeTrackingClient) LogWork(ctx context.Context, in *LogWorkRequest, opts ...grpc.CallOption) (*WorkLog, error) {
+	out := new(WorkLog)
+	err := c.cc.Invoke(ctx, "/task.TimeTracking/LogWork", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *timeTrackingClient) TimeReport(ctx context.Context, in *TimeReportRequest, opts ...grpc.CallOption) (*TimeReportResponse, error) {
+	out := new(TimeReportResponse)
+	err := c.cc.Invoke(ctx, "/task.TimeTracking/TimeReport", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// TimeTrackingServer is the server API for TimeTracking service.
+// All implementations must embed UnimplementedTimeTrackingServer
+// for forward compatibility
+type TimeTrackingServer interface {
+	StartTimer(context.Context, *StartTimerRequest) (*Timer, error)
+	StopTimer(context.Context, *StopTimerRequest) (*WorkLog, error)
+	LogWork(context.Context, *LogWorkRequest) (*WorkLog, error)
+	TimeReport(context.Context, *TimeReportRequest) (*TimeReportResponse, error)
+	mustEmbedUnimplementedTimeTrackingServer()
+}
+
+// UnimplementedTimeTrackingServer must be embedded to have forward compatible implementations.
+type UnimplementedTimeTrackingServer struct {
+}
+
+func (UnimplementedTimeTrackingServer) StartTimer(context.Context, *StartTimerRequest) (*Timer, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StartTimer not implemented")
+}
+func (UnimplementedTimeTrackingServer) StopTimer(context.Context, *StopTimerRequest) (*WorkLog, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StopTimer not implemented")
+}
+func (UnimplementedTimeTrackingServer) LogWork(context.Context, *LogWorkRequest) (*WorkLog, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method LogWork not implemented")
+}
+func (UnimplementedTimeTrackingServer) TimeReport(context.Context, *TimeReportRequest) (*TimeReportResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TimeReport not implemented")
+}
+func (UnimplementedTimeTrackingServer) mustEmbedUnimplementedTimeTrackingServer() {}
+
+// UnsafeTimeTrackingServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to TimeTrackingServer will
+// result in compilation errors.
+type UnsafeTimeTrackingServer interface {
+	mustEmbedUnimplementedTimeTrackingServer()
+}
+
+func RegisterTimeTrackingServer(s grpc.ServiceRegistrar, srv TimeTrackingServer) {
+	s.RegisterService(&TimeTracking_ServiceDesc, srv)
+}
+
+func _TimeTracking_StartTimer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StartTimerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TimeTrackingServer).StartTimer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/task.TimeTracking/StartTimer",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TimeTrackingServer).StartTimer(ctx, req.(*StartTimerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TimeTracking_StopTimer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StopTimerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TimeTrackingServer).StopTimer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/task.TimeTracking/StopTimer",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TimeTrackingServer).StopTimer(ctx, req.(*StopTimerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TimeTracking_LogWork_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LogWorkRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TimeTrackingServer).LogWork(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/task.TimeTracking/LogWork",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TimeTrackingServer).LogWork(ctx, req.(*LogWorkRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TimeTracking_TimeReport_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TimeReportRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TimeTrackingServer).TimeReport(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/task.TimeTracking/TimeReport",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TimeTrackingServer).TimeReport(ctx, req.(*TimeReportRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// TimeTracking_ServiceDesc is the grpc.ServiceDesc for TimeTracking service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var TimeTracking_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "task.TimeTracking",
+	HandlerType: (*TimeTrackingServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "StartTimer",
+			Handler:    _TimeTracking_StartTimer_Handler,
+		},
+		{
+			MethodName: "StopTimer",
+			Handler:    _TimeTracking_StopTimer_Handler,
+		},
+		{
+			MethodName: "LogWork",
+			Handler:    _TimeTracking_LogWork_Handler,
+		},
+		{
+			MethodName: "TimeReport",
+			Handler:    _TimeTracking_TimeReport_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "tasks/timer.proto",
+}
@@ -0,0 +1,308 @@
+package tasks
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/teamwork/log"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// DefaultMaxTimer is the longest a single timer may run. Anything longer was
+// almost certainly forgotten, for example left running over a restart, and
+// is capped when stopped.
+const DefaultMaxTimer = 12 * time.Hour
+
+// timeKey is the meta key the running timers are saved under, and
+// workLogsKey, a slash and a day the work logs started that day.
+const (
+	timeKey     = "time"
+	workLogsKey = "worklogs"
+)
+
+// timeState holds the running timers by user and the work logs by the UTC
+// day they started on. Only the days in changed are saved.
+type timeState struct {
+	timers  map[string]*Timer
+	logs    map[string][]*WorkLog
+	nextID  int64
+	changed map[string]bool
+}
+
+func (tm *timeState) load(tx Tx) error {
+	stored := &StoredTime{}
+	if err := getState(tx, timeKey, stored); err != nil {
+		return err
+	}
+	tm.timers = make(map[string]*Timer)
+	for _, t := range stored.Timers {
+		tm.timers[t.User] = t
+	}
+	tm.logs = make(map[string][]*WorkLog)
+	tm.nextID = stored.LastLogId
+	for _, day := range stored.LogDays {
+		logs := &StoredWorkLogs{}
+		if err := getState(tx, workLogsKey+"/"+day, logs); err != nil {
+			return err
+		}
+		tm.logs[day] = logs.Logs
+	}
+	tm.changed = make(map[string]bool)
+	return nil
+}
+
+func (tm *timeState) save(tx Tx) error {
+	for day := range tm.changed {
+		if err := putState(tx, workLogsKey+"/"+day, &StoredWorkLogs{Logs: tm.logs[day]}); err != nil {
+			return err
+		}
+	}
+	tm.changed = make(map[string]bool)
+
+	stored := &StoredTime{LastLogId: tm.nextID}
+	for _, t := range tm.timers {
+		stored.Timers = append(stored.Timers, t)
+	}
+	sort.Slice(stored.Timers, func(i, j int) bool {
+		return stored.Timers[i].User < stored.Timers[j].User
+	})
+	for day := range tm.logs {
+		stored.LogDays = append(stored.LogDays, day)
+	}
+	sort.Strings(stored.LogDays)
+	return putState(tx, timeKey, stored)
+}
+
+type TimeService struct {
+	UnimplementedTimeTrackingServer
+
+	tasks *TaskService
+
+	// MaxTimer caps how much time a single timer can record.
+	MaxTimer time.Duration
+}
+
+func NewTimeService(tasks *TaskService) *TimeService {
+	return &TimeService{
+		tasks:    tasks,
+		MaxTimer: DefaultMaxTimer,
+	}
+}
+
+func (s *TimeService) StartTimer(c context.Context, r *StartTimerRequest) (_ *Timer, err error) {
+	if r.User == "" {
+		return nil, status.Error(codes.InvalidArgument, "user is required")
+	}
+
+	ts := s.tasks
+	ts.mu.Lock()
+	defer ts.unlock(&err)
+
+	if _, ok := ts.tasks[r.TaskId]; !ok {
+		return nil, status.Errorf(codes.NotFound, "task %d not found", r.TaskId)
+	}
+	if running, ok := ts.time.timers[r.User]; ok {
+		return nil, status.Errorf(codes.FailedPrecondition, "%s already has a timer running on task %d", r.User, running.TaskId)
+	}
+	t := &Timer{
+		User:      r.User,
+		TaskId:    r.TaskId,
+		StartedAt: timestamppb.New(ts.now()),
+	}
+	ts.time.timers[r.User] = t
+	ts.stateChangedLocked(ts.time)
+
+	log.Infof("Started timer for %s on task %d", r.User, r.TaskId)
+	return proto.Clone(t).(*Timer), nil
+}
+
+func (s *TimeService) StopTimer(c context.Context, r *StopTimerRequest) (_ *WorkLog, err error) {
+	ts := s.tasks
+	ts.mu.Lock()
+	defer ts.unlock(&err)
+
+	t, ok := ts.time.timers[r.User]
+	if !ok {
+		return nil, status.Errorf(codes.FailedPrecondition, "%s has no running timer", r.User)
+	}
+	start := t.StartedAt.AsTime()
+	d := ts.now().Sub(start)
+	capped := false
+	if s.MaxTimer > 0 && d > s.MaxTimer {
+		log.Infof("Timer for %s on task %d ran for %s, capping to %s", r.User, t.TaskId, d, s.MaxTimer)
+		d, capped = s.MaxTimer, true
+	}
+
+	delete(ts.time.timers, r.User)
+	wl := ts.logWorkLocked(t.User, t.TaskId, start, d, r.Note)
+	wl.Capped = capped
+	return proto.Clone(wl).(*WorkLog), nil
+}
+
+func (s *TimeService) LogWork(c context.Context, r *LogWorkRequest) (_ *WorkLog, err error) {
+	if r.User == "" {
+		return nil, status.Error(codes.InvalidArgument, "user is required")
+	}
+	if r.Duration == nil || r.Duration.AsDuration() <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "duration must be positive")
+	}
+
+	ts := s.tasks
+	ts.mu.Lock()
+	defer ts.unlock(&err)
+
+	if _, ok := ts.tasks[r.TaskId]; !ok {
+		return nil, status.Errorf(codes.NotFound, "task %d not found", r.TaskId)
+	}
+	start := ts.now().Add(-r.Duration.AsDuration())
+	if r.StartedAt != nil {
+		start = r.StartedAt.AsTime()
+	}
+	wl := ts.logWorkLocked(r.User, r.TaskId, start, r.Duration.AsDuration(), r.Note)
+	return proto.Clone(wl).(*WorkLog), nil
+}
+
+// dropTimersLocked stops the timers running on the given tasks, which are
+// being deleted, without logging their time. s.mu must be held.
+func (s *TaskService) dropTimersLocked(ids []int64) {
+	deleted := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		deleted[id] = true
+	}
+	for user, t := range s.time.timers {
+		if deleted[t.TaskId] {
+			delete(s.time.timers, user)
+			s.stateChangedLocked(s.time)
+			log.Infof("Dropped %s's timer on deleted task %d", user, t.TaskId)
+		}
+	}
+}
+
+// logWorkLocked records a work log and adds it to the task's total. s.mu
+// must be held.
+func (s *TaskService) logWorkLocked(user string, taskID int64, start time.Time, d time.Duration, note string) *WorkLog {
+	if t, ok := s.tasks[taskID]; ok {
+		old := proto.Clone(t).(*Task)
+		t.Logged = durationpb.New(t.Logged.AsDuration() + d)
+		s.changedLocked(old, t)
+	}
+
+	s.time.nextID++
+	wl := &WorkLog{
+		Id:        s.time.nextID,
+		TaskId:    taskID,
+		User:      user,
+		StartedAt: timestamppb.New(start),
+		Duration:  durationpb.New(d),
+		Note:      note,
+	}
+	day := start.UTC().Format(dateLayout)
+	s.time.logs[day] = append(s.time.logs[day], wl)
+	s.time.changed[day] = true
+	s.stateChangedLocked(s.time)
+
+	log.Infof("Logged %s on task %d for %s", d, taskID, user)
+	return wl
+}
+
+func (s *TimeService) TimeReport(c context.Context, r *TimeReportRequest) (*TimeReportResponse, error) {
+	if r.From == nil || r.To == nil {
+		return nil, status.Error(codes.InvalidArgument, "from and to are required")
+	}
+	from, to := r.From.AsTime(), r.To.AsTime()
+	if !to.After(from) {
+		return nil, status.Error(codes.InvalidArgument, "to must be after from")
+	}
+
+	type span struct {
+		user   string
+		taskID int64
+		start  time.Time
+		d      time.Duration
+	}
+
+	ts := s.tasks
+	ts.mu.RLock()
+	defer ts.mu.RUnlock()
+
+	now := ts.now()
+	var spans []span
+	for _, logs := range ts.time.logs {
+		for _, wl := range logs {
+			spans = append(spans, span{wl.User, wl.TaskId, wl.StartedAt.AsTime(), wl.Duration.AsDuration()})
+		}
+	}
+	for _, t := range ts.time.timers {
+		d := now.Sub(t.StartedAt.AsTime())
+		if s.MaxTimer > 0 && d > s.MaxTimer {
+			d = s.MaxTimer
+		}
+		spans = append(spans, span{t.User, t.TaskId, t.StartedAt.AsTime(), d})
+	}
+
+	type key struct {
+		taskID int64
+		user   string
+		day    string
+	}
+	totals := make(map[key]time.Duration)
+	var total time.Duration
+	for _, sp := range spans {
+		// Clip to the range and split at UTC midnight so time is booked
+		// against the day it was spent.
+		start, end := sp.start.UTC(), sp.start.Add(sp.d).UTC()
+		if start.Before(from) {
+			start = from.UTC()
+		}
+		if end.After(to) {
+			end = to.UTC()
+		}
+		for start.Before(end) {
+			midnight := time.Date(start.Year(), start.Month(), start.Day()+1, 0, 0, 0, 0, time.UTC)
+			stop := end
+			if midnight.Before(stop) {
+				stop = midnight
+			}
+			totals[key{sp.taskID, sp.user, start.Format("2006-01-02")}] += stop.Sub(start)
+			total += stop.Sub(start)
+			start = stop
+		}
+	}
+
+	projects := make(map[int64]string)
+	for k := range totals {
+		if t, ok := ts.tasks[k.taskID]; ok {
+			projects[k.taskID] = t.Project
+		}
+	}
+
+	resp := &TimeReportResponse{Total: durationpb.New(total)}
+	for k, d := range totals {
+		resp.Rows = append(resp.Rows, &TimeReportRow{
+			TaskId:   k.taskID,
+			Project:  projects[k.taskID],
+			User:     k.user,
+			Day:      k.day,
+			Duration: durationpb.New(d),
+		})
+	}
+	sort.Slice(resp.Rows, func(i, j int) bool {
+		a, b := resp.Rows[i], resp.Rows[j]
+		if a.Day != b.Day {
+			return a.Day < b.Day
+		}
+		if a.Project != b.Project {
+			return a.Project < b.Project
+		}
+		if a.TaskId != b.TaskId {
+			return a.TaskId < b.TaskId
+		}
+		return a.User < b.User
+	})
+	return resp, nil
+}
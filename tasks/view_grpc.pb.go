@@ -0,0 +1,213 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             (unknown)
+// source: tasks/view.proto
+
+package tasks
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// ViewsClient is the client API for Views service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type ViewsClient interface {
+	SaveView(ctx context.Context, in *View, opts ...grpc.CallOption) (*View, error)
+	ListViews(ctx context.Context, in *ListViewsRequest, opts ...grpc.CallOption) (*ListViewsResponse, error)
+	DeleteView(ctx context.Context, in *DeleteViewRequest, opts ...grpc.CallOption) (*DeleteViewResponse, error)
+	ListByView(ctx context.Context, in *ListByViewRequest, opts ...grpc.CallOption) (*ListResponse, error)
+}
+
+type viewsClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewViewsClient(cc grpc.ClientConnInterface) ViewsClient {
+	return &viewsClient{cc}
+}
+
+func (c *viewsClient) SaveView(ctx context.Context, in *View, opts ...grpc.CallOption) (*View, error) {
+	out := new(View)
+	err := c.cc.Invoke(ctx, "/task.Views/SaveView", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *viewsClient) ListViews(ctx context.Context, in *ListViewsRequest, opts ...grpc.CallOption) (*ListViewsResponse, error) {
+	out := new(ListViewsResponse)
+	err := c.cc.Invoke(ctx, "/task.Views/ListViews", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *viewsClient) DeleteView(ctx context.Context, in *DeleteViewRequest, opts ...grpc.CallOption) (*DeleteViewResponse, error) {
+	out := new(DeleteViewResponse)
+	err := c.cc.Invoke(ctx, "/task.Views/DeleteView", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *viewsClient) ListByView(ctx context.Context, in *ListByViewRequest, opts ...grpc.CallOption) (*ListResponse, error) {
+	out := new(ListResponse)
+	err := c.cc.Invoke(ctx, "/task.Views/ListByView", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ViewsServer is the server API for Views service.
+// All implementations must embed UnimplementedViewsServer
+// for forward compatibility
+type ViewsServer interface {
+	SaveView(context.Context, *View) (*View, error)
+	ListViews(context.Context, *ListViewsRequest) (*ListViewsResponse, error)
+	DeleteView(context.Context, *DeleteViewRequest) (*DeleteViewResponse, error)
+	ListByView(context.Context, *ListByViewRequest) (*ListResponse, error)
+	mustEmbedUnimplementedViewsServer()
+}
+
+// UnimplementedViewsServer must be embedded to have forward compatible implementations.
+type UnimplementedViewsServer struct {
+}
+
+func (UnimplementedViewsServer) SaveView(context.Context, *View) (*View, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SaveView not implemented")
+}
+func (UnimplementedViewsServer) ListViews(context.Context, *ListViewsRequest) (*ListViewsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListViews not implemented")
+}
+func (UnimplementedViewsServer) DeleteView(context.Context, *DeleteViewRequest) (*DeleteViewResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteView not implemented")
+}
+func (UnimplementedViewsServer) ListByView(context.Context, *ListByViewRequest) (*ListResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListByView not implemented")
+}
+func (UnimplementedViewsServer) mustEmbedUnimplementedViewsServer() {}
+
+// UnsafeViewsServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ViewsServer will
+// result in compilation errors.
+type UnsafeViewsServer interface {
+	mustEmbedUnimplementedViewsServer()
+}
+
+func RegisterViewsServer(s grpc.ServiceRegistrar, srv ViewsServer) {
+	s.RegisterService(&Views_ServiceDesc, srv)
+}
+
+func _Views_SaveView_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(View)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ViewsServer).SaveView(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/task.Views/SaveView",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ViewsServer).SaveView(ctx, req.(*View))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Views_ListViews_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListViewsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ViewsServer).ListViews(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/task.Views/ListViews",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ViewsServer).ListViews(ctx, req.(*ListViewsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Views_DeleteView_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteViewRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ViewsServer).DeleteView(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/task.Views/DeleteView",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ViewsServer).DeleteView(ctx, req.(*DeleteViewRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Views_ListByView_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListByViewRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ViewsServer).ListByView(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/task.Views/ListByView",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ViewsServer).ListByView(ctx, req.(*ListByViewRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Views_ServiceDesc is the grpc.ServiceDesc for Views service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Views_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "task.Views",
+	HandlerType: (*ViewsServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "SaveView",
+			Handler:    _Views_SaveView_Handler,
+		},
+		{
+			MethodName: "ListViews",
+			Handler:    _Views_ListViews_Handler,
+		},
+		{
+			MethodName: "DeleteView",
+			Handler:    _Views_DeleteView_Handler,
+		},
+		{
+			MethodName: "ListByView",
+			Handler:    _Views_ListByView_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "tasks/view.proto",
+}
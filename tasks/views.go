@@ -0,0 +1,265 @@
+package tasks
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/teamwork/log"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// viewsKey is the meta key the views are saved under.
+const viewsKey = "views"
+
+type viewState struct {
+	views  map[int64]*View
+	nextID int64
+}
+
+func (vs *viewState) load(tx Tx) error {
+	stored := &StoredViews{}
+	if err := getState(tx, viewsKey, stored); err != nil {
+		return err
+	}
+	vs.views = make(map[int64]*View)
+	vs.nextID = stored.LastId
+	for _, v := range stored.Views {
+		vs.views[v.Id] = v
+		vs.nextID = maxInt64(vs.nextID, v.Id)
+	}
+	return nil
+}
+
+func (vs *viewState) save(tx Tx) error {
+	stored := &StoredViews{LastId: vs.nextID}
+	for _, v := range vs.views {
+		stored.Views = append(stored.Views, v)
+	}
+	sort.Slice(stored.Views, func(i, j int) bool {
+		return stored.Views[i].Id < stored.Views[j].Id
+	})
+	return putState(tx, viewsKey, stored)
+}
+
+type ViewService struct {
+	UnimplementedViewsServer
+
+	tasks *TaskService
+}
+
+func NewViewService(tasks *TaskService) *ViewService {
+	return &ViewService{tasks: tasks}
+}
+
+// SaveView creates a view, or replaces the view with the same id if one is
+// given.
+func (s *ViewService) SaveView(c context.Context, v *View) (_ *View, err error) {
+	if v.Name == "" || v.Owner == "" {
+		return nil, status.Error(codes.InvalidArgument, "views need a name and an owner")
+	}
+	view := proto.Clone(v).(*View)
+	if view.Filter == nil {
+		view.Filter = &TaskFilter{}
+	}
+	if view.Filter.Project == "" {
+		view.Filter.Project = view.Project
+	}
+
+	ts := s.tasks
+	ts.mu.Lock()
+	defer ts.unlock(&err)
+
+	if err := ts.validateViewLocked(view); err != nil {
+		return nil, err
+	}
+	if view.Id != 0 {
+		prev, ok := ts.views.views[view.Id]
+		if !ok {
+			return nil, status.Errorf(codes.NotFound, "view %d not found", view.Id)
+		}
+		if prev.Owner != view.Owner {
+			return nil, status.Errorf(codes.PermissionDenied, "view %d belongs to %s", view.Id, prev.Owner)
+		}
+	} else {
+		ts.views.nextID++
+		view.Id = ts.views.nextID
+	}
+	ts.views.views[view.Id] = view
+	ts.stateChangedLocked(ts.views)
+
+	log.Infof("Saved view %d (%s) for %s", view.Id, view.Name, view.Owner)
+	return proto.Clone(view).(*View), nil
+}
+
+// ListViews returns the user's own views along with the views shared on the
+// project.
+func (s *ViewService) ListViews(c context.Context, r *ListViewsRequest) (*ListViewsResponse, error) {
+	s.tasks.mu.RLock()
+	defer s.tasks.mu.RUnlock()
+
+	resp := &ListViewsResponse{}
+	for _, v := range s.tasks.views.views {
+		if r.Project != "" && v.Project != r.Project {
+			continue
+		}
+		if v.Owner == r.User || v.Shared {
+			resp.Views = append(resp.Views, proto.Clone(v).(*View))
+		}
+	}
+	sort.Slice(resp.Views, func(i, j int) bool {
+		return resp.Views[i].Id < resp.Views[j].Id
+	})
+	return resp, nil
+}
+
+func (s *ViewService) DeleteView(c context.Context, r *DeleteViewRequest) (_ *DeleteViewResponse, err error) {
+	ts := s.tasks
+	ts.mu.Lock()
+	defer ts.unlock(&err)
+
+	v, ok := ts.views.views[r.Id]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "view %d not found", r.Id)
+	}
+	if v.Owner != r.User {
+		return nil, status.Errorf(codes.PermissionDenied, "view %d belongs to %s", r.Id, v.Owner)
+	}
+	delete(ts.views.views, r.Id)
+	ts.stateChangedLocked(ts.views)
+
+	log.Infof("Deleted view %d", r.Id)
+	return &DeleteViewResponse{}, nil
+}
+
+// ListByView runs a saved view, returning only the view's columns.
+func (s *ViewService) ListByView(c context.Context, r *ListByViewRequest) (*ListResponse, error) {
+	s.tasks.mu.RLock()
+	v, ok := s.tasks.views.views[r.Id]
+	if ok && !v.Shared && v.Owner != r.User {
+		ok = false
+	}
+	if ok {
+		v = proto.Clone(v).(*View)
+	}
+	s.tasks.mu.RUnlock()
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "view %d not found", r.Id)
+	}
+
+	resp, err := s.tasks.List(c, &ListRequest{Filter: v.Filter, OrderBy: v.OrderBy})
+	if err != nil {
+		return nil, err
+	}
+	if len(v.Columns) > 0 {
+		for _, t := range resp.Tasks {
+			keepColumns(t, v.Columns)
+		}
+	}
+	return resp, nil
+}
+
+// validateViewLocked checks that everything the view refers to exists.
+// s.mu must be held.
+func (s *TaskService) validateViewLocked(v *View) error {
+	if v.OrderBy != "" {
+		if _, err := orderBy(v.OrderBy); err != nil {
+			return err
+		}
+	}
+
+	fields := (&Task{}).ProtoReflect().Descriptor().Fields()
+	for _, col := range v.Columns {
+		if strings.HasPrefix(col, customFieldPrefix) {
+			continue
+		}
+		if fields.ByName(protoreflect.Name(col)) == nil {
+			return status.Errorf(codes.InvalidArgument, "unknown column %q", col)
+		}
+	}
+
+	f := v.Filter
+	for _, st := range f.Statuses {
+		if _, ok := Status_name[int32(st)]; !ok {
+			return status.Errorf(codes.InvalidArgument, "unknown status %d", st)
+		}
+	}
+
+	// Custom fields must be defined on the view's project, or on some
+	// project if the view isn't limited to one.
+	defined := func(name string) []*CustomFieldDefinition {
+		var defs []*CustomFieldDefinition
+		for project, pd := range s.fields.defs {
+			if d, ok := pd[name]; ok && (f.Project == "" || project == f.Project) {
+				defs = append(defs, d)
+			}
+		}
+		return defs
+	}
+	var names []string
+	for _, col := range append([]string{v.OrderBy}, v.Columns...) {
+		col = strings.TrimPrefix(col, "-")
+		if name := strings.TrimPrefix(col, customFieldPrefix); name != col {
+			names = append(names, name)
+		}
+	}
+	for _, name := range names {
+		if len(defined(name)) == 0 {
+			return status.Errorf(codes.InvalidArgument, "custom field %s is not defined", name)
+		}
+	}
+	for _, cf := range f.CustomFields {
+		defs := defined(cf.Name)
+		if len(defs) == 0 {
+			return status.Errorf(codes.InvalidArgument, "custom field %s is not defined", cf.Name)
+		}
+		if _, ok := CustomFieldFilter_Op_name[int32(cf.Op)]; !ok {
+			return status.Errorf(codes.InvalidArgument, "unknown operator %d for custom field %s", cf.Op, cf.Name)
+		}
+		var err error
+		for _, d := range defs {
+			if err = checkValue(d, cf.Value); err == nil {
+				break
+			}
+		}
+		if err != nil {
+			return status.Errorf(codes.InvalidArgument, "custom field %s: %s", cf.Name, err)
+		}
+	}
+	return nil
+}
+
+// keepColumns clears every field of t except its id and the given columns.
+func keepColumns(t *Task, columns []string) {
+	keep := map[string]bool{"id": true}
+	custom := make(map[string]bool)
+	for _, col := range columns {
+		if name := strings.TrimPrefix(col, customFieldPrefix); name != col {
+			keep["custom_fields"] = true
+			custom[name] = true
+		} else {
+			keep[col] = true
+			if col == "custom_fields" {
+				custom = nil
+			}
+		}
+	}
+
+	m := t.ProtoReflect()
+	m.Range(func(fd protoreflect.FieldDescriptor, _ protoreflect.Value) bool {
+		if !keep[string(fd.Name())] {
+			m.Clear(fd)
+		}
+		return true
+	})
+	if custom != nil {
+		for name := range t.CustomFields {
+			if !custom[name] {
+				delete(t.CustomFields, name)
+			}
+		}
+	}
+}
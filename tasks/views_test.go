@@ -0,0 +1,101 @@
+package tasks
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// A view runs its filter and order on the server and returns only its
+// columns, and a view that can't run is refused when it's saved.
+func TestViewFilters(t *testing.T) {
+	ctx := context.Background()
+	ts, err := NewTaskService(NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields := NewCustomFieldService(ts)
+	views := NewViewService(ts)
+	if _, err := fields.DefineField(ctx, &CustomFieldDefinition{Project: "p", Name: "points", Type: CustomFieldDefinition_NUMBER}); err != nil {
+		t.Fatal(err)
+	}
+	number := func(n float64) *CustomValue { return &CustomValue{Value: &CustomValue_NumberValue{NumberValue: n}} }
+	for _, r := range []*TaskRequest{
+		{Title: "small", Project: "p", Description: "x", CustomFields: map[string]*CustomValue{"points": number(1)}},
+		{Title: "big", Project: "p", Description: "x", CustomFields: map[string]*CustomValue{"points": number(8)}},
+		{Title: "medium", Project: "p", Description: "x", CustomFields: map[string]*CustomValue{"points": number(3)}},
+		{Title: "elsewhere", Project: "q", Description: "x"},
+	} {
+		if _, err := ts.Create(ctx, r); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	points := func(op CustomFieldFilter_Op, n float64) *TaskFilter {
+		return &TaskFilter{CustomFields: []*CustomFieldFilter{{Name: "points", Op: op, Value: number(n)}}}
+	}
+	for name, bad := range map[string]*View{
+		"no owner":        {Name: "v", Project: "p"},
+		"unknown column":  {Name: "v", Owner: "ann", Project: "p", Columns: []string{"colour"}},
+		"unknown field":   {Name: "v", Owner: "ann", Project: "p", Columns: []string{"custom_fields.colour"}},
+		"unknown order":   {Name: "v", Owner: "ann", Project: "p", OrderBy: "colour"},
+		"field elsewhere": {Name: "v", Owner: "ann", Project: "q", Filter: points(CustomFieldFilter_GREATER, 1)},
+		"wrong type":      {Name: "v", Owner: "ann", Project: "p", Filter: &TaskFilter{CustomFields: []*CustomFieldFilter{{Name: "points", Value: &CustomValue{Value: &CustomValue_EnumValue{EnumValue: "S"}}}}}},
+		"unknown status":  {Name: "v", Owner: "ann", Project: "p", Filter: &TaskFilter{Statuses: []Status{99}}},
+	} {
+		if _, err := views.SaveView(ctx, bad); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("saving a view with %s returned %v", name, err)
+		}
+	}
+
+	v, err := views.SaveView(ctx, &View{
+		Name:    "big first",
+		Owner:   "ann",
+		Project: "p",
+		Filter:  points(CustomFieldFilter_GREATER, 1),
+		OrderBy: "-custom_fields.points",
+		Columns: []string{"title"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := views.ListByView(ctx, &ListByViewRequest{Id: v.Id, User: "ann"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, task := range resp.Tasks {
+		got = append(got, task.Title)
+		if task.Id == 0 || task.Project != "" || task.Description != "" || task.CustomFields != nil {
+			t.Errorf("task %d kept fields outside the view's columns: %v", task.Id, task)
+		}
+	}
+	if want := []string{"big", "medium"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("view listed %v, want %v", got, want)
+	}
+
+	// Private views are only seen by their owner until they're shared.
+	if _, err := views.ListByView(ctx, &ListByViewRequest{Id: v.Id, User: "bob"}); status.Code(err) != codes.NotFound {
+		t.Errorf("running ann's private view as bob returned %v", err)
+	}
+	if _, err := views.SaveView(ctx, &View{Id: v.Id, Name: "mine now", Owner: "bob", Project: "p"}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("replacing ann's view as bob returned %v", err)
+	}
+	v.Shared = true
+	if _, err := views.SaveView(ctx, v); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := views.ListByView(ctx, &ListByViewRequest{Id: v.Id, User: "bob"}); err != nil {
+		t.Errorf("running ann's shared view as bob returned %v", err)
+	}
+	list, err := views.ListViews(ctx, &ListViewsRequest{User: "bob", Project: "p"})
+	if err != nil || len(list.Views) != 1 {
+		t.Errorf("bob's views are %v, %v", list, err)
+	}
+	if _, err := views.DeleteView(ctx, &DeleteViewRequest{Id: v.Id, User: "bob"}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("deleting ann's view as bob returned %v", err)
+	}
+}